@@ -2,25 +2,122 @@
 package cyclecmd
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
 	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
 
+	"github.com/RaphSku/cyclecmd/style"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"golang.org/x/term"
 )
 
+// maxKeySize is the maximum number of bytes of a key the event loop reads at once, it fits the escape sequences of
+// common keys, like Page Up (ESC [ 5 ~) or Ctrl+Right (ESC [ 1 ; 5 C). The input is split into keys, see
+// NewKeyReader.
+const maxKeySize = 16
+
+// signalGracePeriod is the time the event loop has to pick up a terminating signal, once it passed the event loop
+// is considered busy and the signal terminates the process.
+const signalGracePeriod = time.Second
+
+// defaultTerminalWidth is the width in columns that is assumed when the size of the terminal is unknown.
+const defaultTerminalWidth = 80
+
 // ConsoleApp handles all the events in an event loop and serves as an entry point for your console.
 type ConsoleApp struct {
 	logger *zap.Logger
 	// The Event Registry is the source of truth for all custom events that
 	// were registered.
 	eventRegistry *EventRegistry
+	// modalEventRegistries is a stack of event registries that temporarily take over the event dispatch,
+	// the most recently pushed event registry handles all tokens until it is popped again.
+	modalEventRegistries []*EventRegistry
+	// modeEventRegistries handle the tokens while the console application is in their mode, see SetModeEventRegistry
+	modeEventRegistries map[string]*EventRegistry
 	// The Event History is decoupled from the Event Registry and records
 	// all events that were processed.
 	eventHistory *EventHistory
 
+	// input is the source of the token stream, Stdin is used when no input is set
+	input io.Reader
+	// output receives everything the console application prints, Stdout is used when no output is set
+	output io.Writer
+	// errorOutput receives the errors of the batch mode, Stderr is used when no error output is set
+	errorOutput io.Writer
+	// batch configures the batch mode
+	batch batchSettings
+	// exitStatus is the exit status of the last run of Start
+	exitStatus int
+	// sessionRecorder records the input and output of a session, might be nil
+	sessionRecorder *SessionRecorder
+	// hooks are called at specific points of the lifecycle of the console application
+	hooks hooks
+
+	// bannerTemplate replaces the default welcome banner, might be nil
+	bannerTemplate *template.Template
+	// bannerDisabled turns off the welcome banner
+	bannerDisabled bool
+	// promptFunc returns the prompt that is printed after the delimiter, might be nil
+	promptFunc func() string
+	// templateFields contains custom fields for the banner and prompt templates
+	templateFields map[string]any
+	// mode is the current mode of the console application
+	mode string
+	// lastExitStatus is the exit status of the last executed command
+	lastExitStatus int
+	// theme contains the styles the console application uses for its own output
+	theme style.Theme
+	// colorProfile overrides the detected colour profile of the output, might be nil
+	colorProfile *style.ColorProfile
+	// width and height cache the size of the terminal, both are 0 as long as the size was not determined
+	width  int
+	height int
+	// sizeMutex guards width and height, since Resize can be called from another goroutine
+	sizeMutex sync.Mutex
+	// resizes notifies the event loop that the size was changed via Resize
+	resizes chan struct{}
+	// screen manages the fixed regions of the terminal, like the status line
+	screen *Screen
+	// progress manages the lines of spinners and progress bars above the prompt
+	progress *progressArea
+	// view is rendered on the alternate screen buffer while the full-screen mode is active, might be nil
+	view View
+	// previousFrame is the frame that was written to the terminal last, might be nil
+	previousFrame *Frame
+	// asyncEvents are queued by Post and PostToken, asyncMutex guards them since they are posted from other goroutines
+	asyncEvents []asyncEvent
+	asyncMutex  sync.Mutex
+	// asyncNotifications notifies the event loop that async events were queued
+	asyncNotifications chan struct{}
+	// tickInterval is the interval between two ticks, the event loop does not tick if it is not positive
+	tickInterval time.Duration
+	// ticker is running while the event loop is running and a tick interval is set
+	ticker *time.Ticker
+	// watchedFiles are the configuration files that are reloaded whenever they change, see WatchKeyBindings
+	watchedFiles []*watchedFile
+	// configPollInterval is the interval in which the watched files are checked for changes
+	configPollInterval time.Duration
+	// configWatcher polls the watched files while the event loop is running, might be nil
+	configWatcher *configWatcher
+	// signalsDisabled stops the event loop from relaying signals, see DisableSignalHandling
+	signalsDisabled bool
+	// keyInput splits the input into keys, it is kept until the input changes, see keyReader
+	keyInput io.Reader
+	// running is true while the event loop is running
+	running bool
+	// loop contains the channels of the running event loop, might be nil
+	loop *loopChannels
+	// conclusion is set once the event loop concluded, might be nil
+	conclusion *loopConclusion
+
 	// Name of the console application
 	Name string
 	// Version of the console application
@@ -90,7 +187,14 @@ func NewConsoleApp(
 		Name:          name,
 		Version:       version,
 		Description:   description,
+		theme:         style.DefaultTheme(),
+		resizes:       make(chan struct{}, 1),
+
+		asyncNotifications: make(chan struct{}, 1),
+		configPollInterval: defaultConfigPollInterval,
 	}
+	consoleApp.screen = newScreen(consoleApp)
+	consoleApp.progress = newProgressArea(consoleApp)
 
 	return consoleApp
 }
@@ -100,12 +204,227 @@ func NewConsoleApp(
 // to production since all logs will be otherwise shown.
 func (ca *ConsoleApp) ChangeToDebugMode() {
 	ca.logger = initLogger(true)
-	fmt.Printf("Attention! You have enabled debug mode (Level: %v)! Turn off if running in production!\r\n", ca.logger.Level())
+	fmt.Fprintf(ca.Output(), "Attention! You have enabled debug mode (Level: %v)! Turn off if running in production!\r\n", ca.logger.Level())
 	ca.logger.Debug("Logger is now set to debug level", zap.String("func", "ChangeToDebugMode"))
 }
 
+// SetInput sets the reader the event loop reads its tokens from. By default, tokens are read from Stdin.
+// The terminal is only switched to raw mode when the input is a terminal.
+//
+// Parameters:
+//   - `input` : Reader that provides the token stream
+func (ca *ConsoleApp) SetInput(input io.Reader) {
+	ca.input = input
+	ca.keyInput = nil
+}
+
+// SetOutput sets the writer the console application prints to. By default, Stdout is used.
+//
+// Parameters:
+//   - `output` : Writer that receives the output of the console application
+func (ca *ConsoleApp) SetOutput(output io.Writer) {
+	ca.output = output
+}
+
+// Output returns the writer the console application prints to. Events should write their output to this
+// writer instead of Stdout, so that their output can be recorded or redirected.
+//
+// Returns:
+//   - `io.Writer` : Writer that receives the output of the console application
+func (ca *ConsoleApp) Output() io.Writer {
+	if ca.output != nil {
+		return ca.output
+	}
+	return os.Stdout
+}
+
+// SetErrorOutput sets the writer the errors of the batch mode are printed to. By default, Stderr is used.
+//
+// Parameters:
+//   - `errorOutput` : Writer that receives the errors
+func (ca *ConsoleApp) SetErrorOutput(errorOutput io.Writer) {
+	ca.errorOutput = errorOutput
+}
+
+// ErrorOutput returns the writer the errors of the batch mode are printed to.
+//
+// Returns:
+//   - `io.Writer` : Writer that receives the errors
+func (ca *ConsoleApp) ErrorOutput() io.Writer {
+	if ca.errorOutput != nil {
+		return ca.errorOutput
+	}
+	return os.Stderr
+}
+
+// EventHistory returns the event history that records all events the console application processed.
+//
+// Returns:
+//   - `*EventHistory` : Event history of the console application
+func (ca *ConsoleApp) EventHistory() *EventHistory {
+	return ca.eventHistory
+}
+
+// inputReader returns the reader the event loop reads its tokens from.
+//
+// Returns:
+//   - `io.Reader` : Reader that provides the token stream
+func (ca *ConsoleApp) inputReader() io.Reader {
+	if ca.input != nil {
+		return ca.input
+	}
+	return os.Stdin
+}
+
+// inputFd returns the file descriptor of the input if the input is a file.
+//
+// Returns:
+//   - `int` : File descriptor of the input
+//   - `bool` : False if the input is not a file
+func (ca *ConsoleApp) inputFd() (int, bool) {
+	file, ok := ca.inputReader().(*os.File)
+	if !ok {
+		return 0, false
+	}
+	return int(file.Fd()), true
+}
+
+// PushEventRegistry pushes an event registry that takes over the event dispatch, the previous event
+// registry is used again once the pushed event registry is popped. This allows modal event handling,
+// e.g. a search mode that interprets the same tokens differently than the regular mode.
+//
+// Parameters:
+//   - `eventRegistry` : Event registry that handles all tokens until it is popped
+func (ca *ConsoleApp) PushEventRegistry(eventRegistry *EventRegistry) {
+	ca.modalEventRegistries = append(ca.modalEventRegistries, eventRegistry)
+	ca.logger.Debug("Event registry pushed", zap.Int("depth", len(ca.modalEventRegistries)), zap.String("func", "PushEventRegistry"))
+}
+
+// PopEventRegistry pops the most recently pushed event registry, the event registry that was active before
+// takes over the event dispatch again.
+//
+// Returns:
+//   - `error` : Returns an error when no event registry was pushed
+func (ca *ConsoleApp) PopEventRegistry() error {
+	depth := len(ca.modalEventRegistries)
+	if depth == 0 {
+		return fmt.Errorf("no event registry was pushed, the event registry of the console app cannot be popped")
+	}
+	ca.modalEventRegistries = ca.modalEventRegistries[:depth-1]
+	ca.logger.Debug("Event registry popped", zap.Int("depth", depth-1), zap.String("func", "PopEventRegistry"))
+	return nil
+}
+
+// SetModeEventRegistry sets the event registry that handles the tokens while the console application is in
+// the mode, see SetMode. In all modes without an event registry, the event registry of the console app handles
+// the tokens. Pushed event registries take precedence over the event registry of the mode.
+//
+// Parameters:
+//   - `mode` : Name of the mode
+//   - `eventRegistry` : Event registry of the mode, nil removes the event registry of the mode
+func (ca *ConsoleApp) SetModeEventRegistry(mode string, eventRegistry *EventRegistry) {
+	if eventRegistry == nil {
+		delete(ca.modeEventRegistries, mode)
+		return
+	}
+	if ca.modeEventRegistries == nil {
+		ca.modeEventRegistries = make(map[string]*EventRegistry)
+	}
+	ca.modeEventRegistries[mode] = eventRegistry
+}
+
+// ModeEventRegistry returns the event registry of the mode.
+//
+// Parameters:
+//   - `mode` : Name of the mode
+//
+// Returns:
+//   - `*EventRegistry` : The event registry of the mode, nil if the mode has no event registry
+func (ca *ConsoleApp) ModeEventRegistry(mode string) *EventRegistry {
+	return ca.modeEventRegistries[mode]
+}
+
+// activeEventRegistry returns the event registry that currently handles the event dispatch.
+//
+// Returns:
+//   - `*EventRegistry` : The most recently pushed event registry, the event registry of the current mode or the
+//     event registry of the console app
+func (ca *ConsoleApp) activeEventRegistry() *EventRegistry {
+	depth := len(ca.modalEventRegistries)
+	if depth > 0 {
+		return ca.modalEventRegistries[depth-1]
+	}
+	if eventRegistry, ok := ca.modeEventRegistries[ca.mode]; ok {
+		return eventRegistry
+	}
+	return ca.eventRegistry
+}
+
+// SetTheme sets the theme the console application uses for its own output, like the prompt, error messages
+// and help output. While the event loop runs, it must be set from the event loop, see Screen.SetStatus.
+//
+// Parameters:
+//   - `theme` : Theme of the console application
+func (ca *ConsoleApp) SetTheme(theme style.Theme) {
+	ca.theme = theme
+}
+
+// Theme returns the theme the console application uses for its own output.
+//
+// Returns:
+//   - `style.Theme` : Theme of the console application
+func (ca *ConsoleApp) Theme() style.Theme {
+	return ca.theme
+}
+
+// SetColorProfile overrides the colour profile that is detected for the output.
+//
+// Parameters:
+//   - `colorProfile` : Colour profile that is used to render styles
+func (ca *ConsoleApp) SetColorProfile(colorProfile style.ColorProfile) {
+	ca.colorProfile = &colorProfile
+}
+
+// ColorProfile returns the colour profile of the output. Unless it is overridden, it is detected from TERM,
+// COLORTERM, NO_COLOR and FORCE_COLOR and styles are stripped when the output is not a terminal.
+//
+// Returns:
+//   - `style.ColorProfile` : Colour profile that is used to render styles
+func (ca *ConsoleApp) ColorProfile() style.ColorProfile {
+	if ca.colorProfile != nil {
+		return *ca.colorProfile
+	}
+	return style.DetectColorProfile(ca.Output())
+}
+
+// render renders the text with the style and the colour profile of the output.
+//
+// Parameters:
+//   - `textStyle` : Style of the text
+//   - `text` : Text that is styled
+//
+// Returns:
+//   - `string` : Styled text
+func (ca *ConsoleApp) render(textStyle style.Style, text string) string {
+	return textStyle.Render(ca.ColorProfile(), text)
+}
+
+// prompt returns the delimiter without leading line breaks followed by the prompt, it is used to redraw
+// the prompt on a new line. The prompt is styled with the prompt style of the theme.
+//
+// Returns:
+//   - `string` : The prompt
+func (ca *ConsoleApp) prompt() string {
+	prompt := strings.TrimLeft(ca.Delimiter, "\r\n")
+	if ca.promptFunc != nil {
+		prompt += ca.promptFunc()
+	}
+	return ca.render(ca.theme.Prompt, prompt)
+}
+
 // SetLineDelimiter allows the User to define a custom delimiter that will be printed
-// after each event that is defined by eventTrigger.
+// after each event that is defined by eventTrigger. A dynamic prompt can be printed after the
+// delimiter, see SetPromptFunc and SetPromptTemplate.
 //
 // Parameters:
 //   - `delimiter` : Delimiter should be fairly short
@@ -122,31 +441,143 @@ func (ca *ConsoleApp) SetLineDelimiter(delimiter string, eventTrigger string) {
 
 // Start will save the terminal state, handle terminating signals and kick off the event loop. Note, events are recorded
 // in the event history before the event handling happens. They are recorded as they occur.
+// The registered lifecycle hooks are called on start, around every event, on errors, on resize and on exit.
+// The terminal state is restored when the event loop concludes, when an event panics and when a terminating signal
+// like SIGINT, SIGTERM or SIGHUP is received. A signal concludes the event loop with EXIT_SIGNAL and Start returns
+// instead of the process being killed, so that the console application decides how to exit. If the event loop is
+// busy, e.g. an event blocks, and does not pick up the signal within a second, the terminal state is restored and
+// the signal terminates the process as usual.
 func (ca *ConsoleApp) Start() {
 	defer ca.logger.Sync()
 
-	ca.logger.Debug("Saving current terminal (if Stdin is a terminal) state before entering the event loop", zap.String("func", "Start"))
-	prevState := ca.saveTerminalState()
-	ca.logger.Debug("Current terminal state has been saved successfully", zap.String("func", "Start"))
+	// Signals are captured before the terminal enters raw mode, so that it is restored whenever a signal arrives
+	signals := make(chan os.Signal, 1)
+	terminationSignals := make(chan os.Signal)
+	if !ca.signalsDisabled {
+		notifyTermination(signals)
+		defer signal.Stop(signals)
+	}
+
+	batch := ca.batchEnabled()
+	var prevState *term.State
+	if !batch {
+		ca.logger.Debug("Saving current terminal (if Stdin is a terminal) state before entering the event loop", zap.String("func", "Start"))
+		prevState = ca.saveTerminalState()
+		ca.logger.Debug("Current terminal state has been saved successfully", zap.String("func", "Start"))
+	}
 
-	ca.logger.Debug("Will enter event loop now", zap.String("func", "Start"))
 	if prevState != nil {
-		defer term.Restore(int(os.Stdin.Fd()), prevState)
+		fd, _ := ca.inputFd()
+		defer term.Restore(fd, prevState)
+	}
+	relayDone := make(chan struct{})
+	defer close(relayDone)
+	go ca.relayTermination(signals, terminationSignals, prevState, relayDone)
+
+	if ca.sessionRecorder != nil {
+		ca.logger.Debug("Session recording is enabled", zap.String("func", "Start"))
+		input, output := ca.input, ca.output
+		defer func() { ca.input, ca.output = input, output }()
+		ca.sessionRecorder.start()
+		// The event loop records the keys itself, so that the keys of masked events are not recorded
+		if batch {
+			ca.input = ca.sessionRecorder.recordInput(ca.inputReader())
+		}
+		ca.output = ca.sessionRecorder.recordOutput(ca.Output())
 	}
-	ca.eventLoop(prevState)
+
+	ca.exitStatus = 1
+	if err := ca.runStartHooks(); err != nil {
+		ca.logger.Debug("Start hook failed", zap.Error(err), zap.String("func", "Start"))
+		ca.runErrorHooks(err)
+		ca.runExitHooks(EXIT_ERROR, err)
+		return
+	}
+
+	var reason ExitReason
+	var err error
+	if batch {
+		ca.logger.Debug("Will execute the input in batch mode now", zap.String("func", "Start"))
+		reason, err = ca.batchLoop(terminationSignals)
+	} else {
+		ca.logger.Debug("Will enter event loop now", zap.String("func", "Start"))
+		reason, err = ca.eventLoop(prevState, terminationSignals)
+	}
+	if err == nil && reason != EXIT_SIGNAL {
+		ca.exitStatus = 0
+	}
+	ca.runExitHooks(reason, err)
+}
+
+// relayTermination relays the terminating signals to the event loop. A signal the event loop does not pick up within
+// the grace period restores the terminal state and terminates the process, so that a busy event loop cannot swallow
+// it.
+//
+// Parameters:
+//   - `signals` : Channel that receives the terminating signals of the process
+//   - `terminationSignals` : Channel the event loop receives the signals from
+//   - `prevState` : The terminal state that is restored before the process terminates, might be nil
+//   - `done` : Closed once the event loop concluded
+func (ca *ConsoleApp) relayTermination(signals <-chan os.Signal, terminationSignals chan<- os.Signal, prevState *term.State, done <-chan struct{}) {
+	for {
+		var terminationSignal os.Signal
+		select {
+		case terminationSignal = <-signals:
+		case <-done:
+			return
+		}
+		select {
+		case terminationSignals <- terminationSignal:
+		case <-done:
+			return
+		case <-time.After(signalGracePeriod):
+			ca.logger.Debug("Event loop is busy, the signal terminates the process", zap.String("Signal", terminationSignal.String()), zap.String("func", "relayTermination"))
+			if prevState != nil {
+				fd, _ := ca.inputFd()
+				term.Restore(fd, prevState)
+			}
+			raiseTermination(terminationSignal)
+			return
+		}
+	}
+}
+
+// DisableSignalHandling stops the console application from relaying the signals of the process, i.e. terminating
+// signals no longer conclude the event loop and SIGWINCH is ignored. It is meant for console applications that
+// serve a remote terminal, e.g. a session of a server, which report their size via Resize and must not be
+// concluded by the signals that are sent to the server.
+func (ca *ConsoleApp) DisableSignalHandling() {
+	ca.signalsDisabled = true
 }
 
-// saveTerminalState will save the state of the terminal, if there is no terminal available, no state will be saved.
+// AttachRemoteTerminal attaches the console application to a remote terminal, e.g. a session of a server. The
+// input of the terminal is split into keys, see NewKeyReader, and the signals of the process are not handled, see
+// DisableSignalHandling, so that the signals of the server do not conclude the session. Every remote terminal needs
+// its own console application with its own EventRegistry and EventHistory, so console applications must never be
+// shared between the sessions of a server.
+//
+// Parameters:
+//   - `terminal` : Reads the input of the remote terminal and writes the output to it
+//   - `errorOutput` : Writer that receives the errors, e.g. of the rc file, instead of Stderr of the server
+func (ca *ConsoleApp) AttachRemoteTerminal(terminal io.ReadWriter, errorOutput io.Writer) {
+	ca.SetInput(NewKeyReader(terminal))
+	ca.SetOutput(terminal)
+	ca.SetErrorOutput(errorOutput)
+	ca.DisableSignalHandling()
+}
+
+// saveTerminalState will save the state of the terminal, if the input is no terminal, no state will be saved.
 //
 // Returns:
 //   - `*term.State` : Returns the reference of the current terminal state
 func (ca *ConsoleApp) saveTerminalState() *term.State {
-	if !term.IsTerminal(int(os.Stdin.Fd())) {
-		ca.logger.Debug("Detected that standard input is not a terminal", zap.String("func", "saveTerminalState"))
+	fd, ok := ca.inputFd()
+	if !ok || !term.IsTerminal(fd) {
+		ca.logger.Debug("Detected that the input is not a terminal", zap.String("func", "saveTerminalState"))
 		return nil
 	}
 
-	terminalState, err := term.MakeRaw(int(os.Stdin.Fd()))
+	terminalState, err := term.MakeRaw(fd)
 	if err != nil {
 		fmt.Print("terminal state could not be saved! error:", err)
 		os.Exit(1)
@@ -178,53 +609,295 @@ func (ca *ConsoleApp) convertByteTokenToStringToken(byteToken []byte) string {
 	return string(filteredByteArray)
 }
 
-// eventLoop is a long running process that will capture Stdin and handle incoming events,
-// as well as record the event history.
+// readResult is the result of reading a single chunk from the input.
+type readResult struct {
+	chunk []byte
+	n     int
+	err   error
+}
+
+// readInput reads a single key of up to maxKeySize bytes from the input for every request, reading on demand
+// ensures that no input is consumed once the event loop concluded. Only a read of the key reader that outlasted
+// the escape timeout stays in progress, its bytes are returned to the next event loop, see keyReader.
+//
+// Parameters:
+//   - `input` : Reader that provides the token stream
+//   - `requests` : Every request triggers a single read, reading stops once the channel is closed
+//   - `results` : Receives the result of every read
+func (ca *ConsoleApp) readInput(input io.Reader, requests <-chan struct{}, results chan<- readResult) {
+	for range requests {
+		chunk := make([]byte, maxKeySize)
+		n, err := input.Read(chunk)
+		results <- readResult{chunk: chunk, n: n, err: err}
+	}
+}
+
+// keyReader returns the input split into keys. The key reader is kept for the next event loop as long as the
+// input does not change, so that the bytes of a read that is still in progress once the event loop concluded are
+// not lost.
+//
+// Returns:
+//   - `io.Reader` : Reader that returns one key per read
+func (ca *ConsoleApp) keyReader() io.Reader {
+	if ca.keyInput == nil {
+		ca.keyInput = NewKeyReader(ca.inputReader())
+	}
+	return ca.keyInput
+}
+
+// eventLoop is a long running process that will capture the input and handle incoming events,
+// as well as record the event history. Besides the input, it handles resizes, async events and ticks.
+// The full-screen view is rendered after each of them.
 //
 // Parameters:
 //   - `prevState`: The previous terminal state that will be restored after the event loop concludes
-func (ca *ConsoleApp) eventLoop(prevState *term.State) {
-	fmt.Printf("Welcome to %s! Version: %s\r\n%s\r", ca.Name, ca.Version, ca.Description)
-	fmt.Printf("%s", ca.Delimiter)
-	for {
-		// TODO: Need to separate reading from parsing in the future to make this event loop more robust
-		b := make([]byte, 3)
-		n, err := os.Stdin.Read(b)
-		if n == 0 && prevState == nil {
-			ca.logger.Debug("EOF found", zap.String("func", "eventLoop"))
-			return
+//   - `terminationSignals` : Channel that receives the signals that conclude the event loop
+//
+// Returns:
+//   - `ExitReason` : Reason why the event loop concluded
+//   - `error` : The error that stopped the event loop, if any
+func (ca *ConsoleApp) eventLoop(prevState *term.State, terminationSignals chan os.Signal) (ExitReason, error) {
+	// A resize before the event loop started is already reflected by the size the screen is drawn with
+	select {
+	case <-ca.resizes:
+	default:
+	}
+	ca.running = true
+	ca.conclusion = nil
+	defer func() { ca.running = false }()
+	ca.screen.activate()
+	defer ca.screen.deactivate()
+	defer func() {
+		if ca.IsFullScreen() {
+			ca.ExitFullScreen()
 		}
-		if err != nil {
-			ca.logger.Debug("Could not read from Stdin", zap.Error(err), zap.String("func", "eventLoop"))
-			return
+	}()
+	ca.startTicker()
+	defer ca.stopTicker()
+	ca.startConfigWatcher()
+	defer ca.stopConfigWatcher()
+	defer ca.progress.hide()
+	defer ca.progress.cancelAll()
+	ca.printBanner()
+	ca.printDelimiter()
+
+	ca.loop = &loopChannels{
+		requests:           make(chan struct{}, 1),
+		results:            make(chan readResult, 1),
+		resizeSignals:      make(chan os.Signal, 1),
+		terminationSignals: terminationSignals,
+		isTerminal:         prevState != nil,
+	}
+	defer close(ca.loop.requests)
+	go ca.readInput(ca.keyReader(), ca.loop.requests, ca.loop.results)
+
+	if !ca.signalsDisabled {
+		notifyResize(ca.loop.resizeSignals)
+		defer signal.Stop(ca.loop.resizeSignals)
+	}
+
+	for {
+		if reason, err, concluded := ca.processNext(); concluded {
+			return reason, err
 		}
-		token := ca.convertByteTokenToStringToken(b)
-		ca.logger.Debug("Token captured", zap.String("Token", token), zap.String("func", "eventLoop"))
-		eventInformation, err := ca.eventRegistry.GetMatchingEventInformation(token)
-		if err != nil {
-			ca.logger.Debug("Did not find a matching event", zap.Error(err), zap.String("func", "eventLoop"))
-			return
+	}
+}
+
+// loopChannels are the channels the event loop waits on.
+type loopChannels struct {
+	// requests triggers a single read of the input
+	requests chan struct{}
+	// results receives the result of every read
+	results chan readResult
+	// readPending is true while a read was requested whose result was not received yet
+	readPending bool
+	// resizeSignals receives SIGWINCH
+	resizeSignals chan os.Signal
+	// terminationSignals receives the signals that conclude the event loop, e.g. SIGTERM
+	terminationSignals chan os.Signal
+	// isTerminal is true if the input is a terminal in raw mode
+	isTerminal bool
+}
+
+// loopConclusion records why the event loop concluded.
+type loopConclusion struct {
+	reason ExitReason
+	err    error
+}
+
+// processNext waits for the next token, resize, async event or tick and handles it. The full-screen view is
+// rendered afterwards. Once the event loop concluded, processNext keeps returning the same conclusion.
+//
+// Returns:
+//   - `ExitReason` : Reason why the event loop concluded
+//   - `error` : The error that stopped the event loop, if any
+//   - `bool` : True if the event loop concluded
+func (ca *ConsoleApp) processNext() (ExitReason, error, bool) {
+	if ca.conclusion != nil {
+		return ca.conclusion.reason, ca.conclusion.err, true
+	}
+	if !ca.loop.readPending {
+		ca.loop.requests <- struct{}{}
+		ca.loop.readPending = true
+	}
+
+	var controlEvent *ControlEvent
+	var err error
+	select {
+	case terminationSignal := <-ca.loop.terminationSignals:
+		ca.logger.Debug("Terminating signal received", zap.String("Signal", terminationSignal.String()), zap.String("func", "processNext"))
+		return ca.conclude(EXIT_SIGNAL, nil)
+	case <-ca.loop.resizeSignals:
+		ca.refreshSize()
+		controlEvent, err = ca.handleResize()
+	case <-ca.resizes:
+		controlEvent, err = ca.handleResize()
+	case <-ca.asyncNotifications:
+		controlEvent, err = ca.handleAsyncEvents()
+	case now := <-ca.ticks():
+		ca.progress.hide()
+		controlEvent = ca.runTickHooks(now)
+	case result := <-ca.loop.results:
+		ca.loop.readPending = false
+		if result.err != nil && !errors.Is(result.err, io.EOF) {
+			ca.logger.Debug("Could not read from input", zap.Error(result.err), zap.String("func", "processNext"))
+			ca.runErrorHooks(result.err)
+			return ca.conclude(EXIT_ERROR, result.err)
 		}
-		eventHistoryEntry := EventHistoryEntry{
-			Token:     token,
-			EventName: eventInformation.EventName,
-			Event:     eventInformation.Event,
+		if result.err != nil || result.n == 0 && !ca.loop.isTerminal {
+			ca.logger.Debug("EOF found", zap.String("func", "processNext"))
+			return ca.conclude(EXIT_END_OF_INPUT, nil)
 		}
-		ca.eventHistory.AddEvent(eventHistoryEntry)
-		lengthOfHistoryString := strconv.Itoa(ca.eventHistory.Len())
-		ca.logger.Debug("Event History Length", zap.String("Length", lengthOfHistoryString), zap.String("func", "eventLoop"))
-		err, controlEvent := eventInformation.Event.Handle(token)
-		if err != nil {
-			ca.logger.Debug("Event handling failed", zap.Error(err), zap.String("func", "eventLoop"))
-			return
+		// TODO: Need to separate reading from parsing in the future to make this event loop more robust
+		token := ca.convertByteTokenToStringToken(result.chunk)
+		masked := ca.activeEventRegistry().maskTokens
+		if !masked {
+			ca.logger.Debug("Token captured", zap.String("Token", token), zap.String("func", "processNext"))
 		}
-		if controlEvent != nil {
-			if controlEvent.Terminate {
-				return
-			}
+		if ca.sessionRecorder != nil {
+			// Keys like Enter are recorded while the tokens are masked, so that the recording can be replayed
+			_, printable := printableCharacter(token)
+			ca.sessionRecorder.recordKey(result.chunk[:result.n], masked && printable)
 		}
-		if token == ca.DelimiterEventTrigger {
-			fmt.Print(ca.Delimiter)
+		if ca.progress.cancelOperation(token) {
+			break
 		}
+		controlEvent, err = ca.dispatchToken(token)
+	}
+
+	// The event loop might have concluded while an event handled further events, see RunModal
+	if ca.conclusion != nil {
+		return ca.conclusion.reason, ca.conclusion.err, true
+	}
+
+	if err != nil {
+		ca.logger.Debug("Event handling failed", zap.Error(err), zap.String("func", "processNext"))
+		ca.runErrorHooks(err)
+		return ca.conclude(EXIT_ERROR, err)
+	}
+	if controlEvent != nil && controlEvent.Terminate {
+		return ca.conclude(EXIT_TERMINATED, nil)
+	}
+	ca.renderView()
+	ca.progress.refresh()
+	return 0, nil, false
+}
+
+// conclude records the conclusion of the event loop.
+//
+// Parameters:
+//   - `reason` : Reason why the event loop concluded
+//   - `err` : The error that stopped the event loop, if any
+//
+// Returns:
+//   - `ExitReason` : The reason
+//   - `error` : The error
+//   - `bool` : Always true
+func (ca *ConsoleApp) conclude(reason ExitReason, err error) (ExitReason, error, bool) {
+	ca.conclusion = &loopConclusion{reason: reason, err: err}
+	return reason, err, true
+}
+
+// dispatchToken looks up the event that matches the token, records it in the event history and
+// handles it. The delimiter is printed afterwards if the token is the delimiter event trigger and no event
+// registry was pushed.
+// BeforeEvent hooks can veto the event, in that case it is neither recorded nor handled.
+//
+// Parameters:
+//   - `token` : Token that should be dispatched
+//
+// Returns:
+//   - `*ControlEvent` : The control event returned by the event handler, might be nil
+//   - `error` : Returns an error when no matching event was found or the event handling failed
+func (ca *ConsoleApp) dispatchToken(token string) (*ControlEvent, error) {
+	// Events might print, so the progress lines are drawn above the prompt again once the event was handled
+	ca.progress.hide()
+	eventRegistry := ca.activeEventRegistry()
+	eventTrigger, complete := eventRegistry.advanceSequence(token)
+	if !complete {
+		ca.logger.Debug("Waiting for the next token of a key sequence", zap.String("func", "dispatchToken"))
+		return nil, nil
+	}
+	eventInformation, err := eventRegistry.GetMatchingEventInformation(eventTrigger)
+	if err != nil {
+		ca.logger.Debug("Did not find a matching event", zap.Error(err), zap.String("func", "dispatchToken"))
+		return nil, err
+	}
+	// Pushed event registries, e.g. of widgets, handle the delimiter event trigger themselves
+	modal := len(ca.modalEventRegistries) > 0
+	eventHistoryEntry := EventHistoryEntry{
+		Token:     token,
+		EventName: eventInformation.EventName,
+		Event:     eventInformation.Event,
+	}
+	// A completed key sequence is recorded as a whole, so that it can be replayed, see MacroRegistry
+	if tokens := strings.Split(eventTrigger, sequenceSeparator); len(tokens) > 1 {
+		eventHistoryEntry.Token = strings.Join(tokens, " ")
+		eventHistoryEntry.Tokens = tokens
+	}
+	if eventRegistry.maskTokens {
+		eventHistoryEntry.Token = MASKED_TOKEN
+		eventHistoryEntry.Tokens = nil
+	}
+	controlEvent, err := ca.handleEvent(token, eventHistoryEntry)
+	if err != nil || controlEvent != nil && controlEvent.Terminate {
+		return controlEvent, err
+	}
+	if token == ca.DelimiterEventTrigger && !modal {
+		ca.printDelimiter()
+	}
+	return controlEvent, nil
+}
+
+// handleEvent runs the BeforeEvent hooks, records the event in the event history, handles it and runs the
+// AfterEvent hooks. A vetoed event is neither recorded nor handled.
+//
+// Parameters:
+//   - `token` : Token that is passed to the event
+//   - `eventHistoryEntry` : Entry that is recorded, its event handles the token
+//
+// Returns:
+//   - `*ControlEvent` : The control event returned by the event handler or a hook, might be nil
+//   - `error` : Returns an error when the event handling failed
+func (ca *ConsoleApp) handleEvent(token string, eventHistoryEntry EventHistoryEntry) (*ControlEvent, error) {
+	controlEvent := ca.runEventHooks(ca.hooks.beforeEvent, eventHistoryEntry)
+	if controlEvent != nil && controlEvent.Terminate {
+		return controlEvent, nil
+	}
+	if controlEvent != nil && controlEvent.Veto {
+		ca.logger.Debug("Event was vetoed", zap.String("Token", eventHistoryEntry.Token), zap.String("func", "handleEvent"))
+		return nil, nil
+	}
+	ca.eventHistory.AddEvent(eventHistoryEntry)
+	lengthOfHistoryString := strconv.Itoa(ca.eventHistory.Len())
+	ca.logger.Debug("Event History Length", zap.String("Length", lengthOfHistoryString), zap.String("func", "handleEvent"))
+	err, controlEvent := eventHistoryEntry.Event.Handle(token)
+	if err != nil {
+		ca.logger.Debug("Event handling failed", zap.Error(err), zap.String("func", "handleEvent"))
+		return nil, err
+	}
+	if hookControlEvent := ca.runEventHooks(ca.hooks.afterEvent, eventHistoryEntry); hookControlEvent != nil && hookControlEvent.Terminate {
+		return hookControlEvent, nil
 	}
+	return controlEvent, nil
 }
@@ -3,6 +3,7 @@
 package cyclecmd_test
 
 import (
+	"fmt"
 	"os"
 	"os/exec"
 	"testing"
@@ -114,3 +115,25 @@ func TestSetLineDelimiterNotInRegistry(t *testing.T) {
 	}
 	t.Fatalf("process returned err: %v, want exit code 1", err)
 }
+
+func TestPushAndPopEventRegistry(t *testing.T) {
+	t.Parallel()
+
+	eventRegistry := cyclecmd.NewEventRegistry(setupDefaultEventInformation())
+	consoleApp := cyclecmd.NewConsoleApp(
+		"test",
+		"0.1.0",
+		"This is a test console application",
+		eventRegistry,
+		cyclecmd.NewEventHistory(),
+	)
+
+	err := consoleApp.PopEventRegistry()
+	if assert.Error(t, err) {
+		assert.Equal(t, fmt.Errorf("no event registry was pushed, the event registry of the console app cannot be popped"), err)
+	}
+
+	consoleApp.PushEventRegistry(cyclecmd.NewEventRegistry(setupDefaultEventInformation()))
+	err = consoleApp.PopEventRegistry()
+	assert.NoError(t, err)
+}
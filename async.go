@@ -0,0 +1,129 @@
+package cyclecmd
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// asyncEvent is an event that was posted from another goroutine, either a token that is dispatched, a
+// function that is called by the event loop or an update of the progress components.
+type asyncEvent struct {
+	token string
+	run   func() (error, *ControlEvent)
+	// refreshProgress is true if the event only lets the event loop draw the progress lines again
+	refreshProgress bool
+}
+
+// PostToken queues a token that is dispatched by the event loop as if it was read from the input, i.e. it is
+// recorded in the event history and handled by the active event registry. It is safe to call PostToken from
+// another goroutine, it never blocks.
+//
+// Parameters:
+//   - `token` : Token that should be dispatched
+func (ca *ConsoleApp) PostToken(token string) {
+	ca.post(asyncEvent{token: token})
+}
+
+// Post queues a function that is called by the event loop, so that it can safely access the console
+// application, e.g. to update the screen with the result of a background task. The function is not recorded in
+// the event history. It is safe to call Post from another goroutine, it never blocks.
+//
+// Parameters:
+//   - `run` : Function that is called by the event loop, it can return a control event like an event
+func (ca *ConsoleApp) Post(run func() (error, *ControlEvent)) {
+	ca.post(asyncEvent{run: run})
+}
+
+// post appends the async event to the queue and notifies the event loop.
+//
+// Parameters:
+//   - `event` : Async event that is queued
+func (ca *ConsoleApp) post(event asyncEvent) {
+	ca.asyncMutex.Lock()
+	ca.asyncEvents = append(ca.asyncEvents, event)
+	ca.asyncMutex.Unlock()
+	// A pending notification is sufficient, since the event loop handles all queued events at once
+	select {
+	case ca.asyncNotifications <- struct{}{}:
+	default:
+	}
+}
+
+// handleAsyncEvents handles all queued async events in the order they were posted.
+//
+// Returns:
+//   - `*ControlEvent` : The first control event with the termination flag, might be nil
+//   - `error` : Returns an error when an async event failed, the remaining events stay queued
+func (ca *ConsoleApp) handleAsyncEvents() (*ControlEvent, error) {
+	for {
+		ca.asyncMutex.Lock()
+		if len(ca.asyncEvents) == 0 {
+			ca.asyncMutex.Unlock()
+			return nil, nil
+		}
+		event := ca.asyncEvents[0]
+		ca.asyncEvents = ca.asyncEvents[1:]
+		ca.asyncMutex.Unlock()
+
+		var controlEvent *ControlEvent
+		var err error
+		if event.refreshProgress {
+			// The progress lines are drawn once the event loop handled all async events
+			continue
+		} else if event.run != nil {
+			ca.logger.Debug("Running posted function", zap.String("func", "handleAsyncEvents"))
+			ca.progress.hide()
+			err, controlEvent = event.run()
+		} else {
+			ca.logger.Debug("Dispatching posted token", zap.String("Token", event.token), zap.String("func", "handleAsyncEvents"))
+			controlEvent, err = ca.dispatchToken(event.token)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if controlEvent != nil && controlEvent.Terminate {
+			return controlEvent, nil
+		}
+	}
+}
+
+// SetTickInterval makes the event loop tick in the interval, every tick calls the tick hooks and renders the
+// full-screen view, e.g. to animate a spinner or update a clock. A non-positive interval turns ticking off.
+// It can be called before the event loop starts or from within events.
+//
+// Parameters:
+//   - `interval` : Interval between two ticks
+func (ca *ConsoleApp) SetTickInterval(interval time.Duration) {
+	ca.tickInterval = interval
+	if ca.running {
+		ca.stopTicker()
+		ca.startTicker()
+	}
+}
+
+// startTicker starts ticking if a tick interval is set, it is called once the event loop starts.
+func (ca *ConsoleApp) startTicker() {
+	if ca.tickInterval > 0 {
+		ca.ticker = time.NewTicker(ca.tickInterval)
+	}
+}
+
+// stopTicker stops ticking, it is called once the event loop concluded.
+func (ca *ConsoleApp) stopTicker() {
+	if ca.ticker != nil {
+		ca.ticker.Stop()
+		ca.ticker = nil
+	}
+}
+
+// ticks returns the channel of the ticker.
+//
+// Returns:
+//   - `<-chan time.Time` : Channel that receives the ticks, nil if ticking is turned off
+func (ca *ConsoleApp) ticks() <-chan time.Time {
+	if ca.ticker == nil {
+		return nil
+	}
+	return ca.ticker.C
+}
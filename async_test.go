@@ -0,0 +1,78 @@
+//go:build unit_test
+
+package cyclecmd_test
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/RaphSku/cyclecmd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPostedEvents(t *testing.T) {
+	t.Parallel()
+
+	// The pipe never provides a token, so that the event loop only handles the posted events
+	input, inputWriter := io.Pipe()
+	defer inputWriter.Close()
+	consoleApp, eventRegistry, eventHistory, _ := setupHooksConsoleApp()
+	consoleApp.SetInput(input)
+	err := eventRegistry.RegisterEvent("a", cyclecmd.EventInformation{EventName: "Test", Event: &TestEvent{}})
+	assert.NoError(t, err)
+	var actReason cyclecmd.ExitReason
+	consoleApp.OnExit(func(reason cyclecmd.ExitReason, err error) {
+		actReason = reason
+	})
+
+	calls := []string{}
+	consoleApp.OnStart(func() error {
+		go func() {
+			consoleApp.PostToken("a")
+			consoleApp.Post(func() (error, *cyclecmd.ControlEvent) {
+				calls = append(calls, "posted")
+				return nil, cyclecmd.NewControlEvent(cyclecmd.CYCLE_TERMINATE)
+			})
+		}()
+		return nil
+	})
+
+	_, err = captureStdOutput(func() {
+		consoleApp.Start()
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, cyclecmd.EXIT_TERMINATED, actReason)
+	assert.Equal(t, []string{"posted"}, calls)
+	assert.Equal(t, 1, eventHistory.Len())
+	entry, err := eventHistory.RetrieveEventEntryByIndex(0)
+	assert.NoError(t, err)
+	assert.Equal(t, "a", entry.Token)
+}
+
+func TestTicks(t *testing.T) {
+	t.Parallel()
+
+	input, inputWriter := io.Pipe()
+	defer inputWriter.Close()
+	consoleApp, _, eventHistory, _ := setupHooksConsoleApp()
+	consoleApp.SetInput(input)
+	consoleApp.SetTickInterval(time.Millisecond)
+	ticks := 0
+	consoleApp.OnTick(func(now time.Time) *cyclecmd.ControlEvent {
+		ticks += 1
+		if ticks == 3 {
+			return cyclecmd.NewControlEvent(cyclecmd.CYCLE_TERMINATE)
+		}
+		return nil
+	})
+	var actReason cyclecmd.ExitReason
+	consoleApp.OnExit(func(reason cyclecmd.ExitReason, err error) {
+		actReason = reason
+	})
+
+	consoleApp.Start()
+	assert.Equal(t, cyclecmd.EXIT_TERMINATED, actReason)
+	assert.Equal(t, 3, ticks)
+	assert.Equal(t, 0, eventHistory.Len())
+}
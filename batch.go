@@ -0,0 +1,305 @@
+package cyclecmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"go.uber.org/zap"
+	"golang.org/x/term"
+)
+
+// BatchMode selects whether the console application reads its input as lines of a script instead of key presses.
+type BatchMode int
+
+const (
+	// BATCH_OFF processes the input as key presses, this is the default.
+	BATCH_OFF BatchMode = iota
+	// BATCH_AUTO enables the batch mode if the input is not a terminal, e.g. when the input is piped.
+	BATCH_AUTO
+	// BATCH_ON always enables the batch mode.
+	BATCH_ON
+)
+
+// batchInputName is the name of the input in the error messages of the batch mode.
+const batchInputName = "input"
+
+// batchCommentPrefix starts lines that are skipped in batch mode.
+const batchCommentPrefix = "#"
+
+// batchSettings configure the batch mode of a console application.
+type batchSettings struct {
+	mode BatchMode
+	// handler executes every line, lines are dispatched as key presses if it is nil
+	handler func(line string) (error, *ControlEvent)
+	// continueOnError executes the remaining lines after a line failed
+	continueOnError bool
+	// active is true while lines are executed in batch mode
+	active bool
+}
+
+// batchLine is a line that was read from the input in batch mode.
+type batchLine struct {
+	text string
+	err  error
+}
+
+// SetBatchMode sets whether Start processes the input in batch mode. In batch mode, the input is read line by
+// line, the banner, the delimiter, the prompt and progress lines are not printed and the terminal is not put into
+// raw mode. Empty lines and lines starting with # are skipped. Every line is executed by the batch handler, see
+// SetBatchHandler, the event loop concludes with EXIT_ERROR on the first line that fails, see SetContinueOnError.
+// Errors are printed to the error output together with the line number. Ticks and async events are not
+// processed in batch mode.
+//
+// Parameters:
+//   - `mode` : BATCH_OFF by default, BATCH_AUTO enables the batch mode if the input is not a terminal
+func (ca *ConsoleApp) SetBatchMode(mode BatchMode) {
+	ca.batch.mode = mode
+}
+
+// SetBatchHandler sets the function that executes the lines in batch mode, e.g. CommandRegistry.Execute. Lines
+// are recorded in the event history as submitted lines. Without a handler, every character of a line is
+// dispatched as key press followed by KEY_ENTER, just as if the line was typed.
+//
+// Parameters:
+//   - `handler` : Executes a line, an error marks the line as failed
+func (ca *ConsoleApp) SetBatchHandler(handler func(line string) (error, *ControlEvent)) {
+	ca.batch.handler = handler
+}
+
+// SetContinueOnError sets whether the remaining lines are executed after a line failed in batch mode. The event
+// loop still concludes with EXIT_ERROR once the input is exhausted.
+//
+// Parameters:
+//   - `continueOnError` : True to execute the remaining lines after a line failed
+func (ca *ConsoleApp) SetContinueOnError(continueOnError bool) {
+	ca.batch.continueOnError = continueOnError
+}
+
+// IsBatch returns whether lines are currently executed in batch mode, events can use it to skip interactive
+// output.
+//
+// Returns:
+//   - `bool` : True while lines are executed in batch mode
+func (ca *ConsoleApp) IsBatch() bool {
+	return ca.batch.active
+}
+
+// batchEnabled returns whether Start processes the input in batch mode.
+//
+// Returns:
+//   - `bool` : True if the batch mode is on or is detected
+func (ca *ConsoleApp) batchEnabled() bool {
+	switch ca.batch.mode {
+	case BATCH_ON:
+		return true
+	case BATCH_AUTO:
+		fd, ok := ca.inputFd()
+		return !ok || !term.IsTerminal(fd)
+	}
+	return false
+}
+
+// batchLoop executes the input line by line until it is exhausted, a line fails or a terminating signal is received.
+//
+// Parameters:
+//   - `terminationSignals` : Channel that receives the signals that conclude the batch mode
+//
+// Returns:
+//   - `ExitReason` : Reason why the batch mode concluded
+//   - `error` : The errors of the failed lines, if any
+func (ca *ConsoleApp) batchLoop(terminationSignals chan os.Signal) (ExitReason, error) {
+	ca.batch.active = true
+	defer func() { ca.batch.active = false }()
+	ca.conclusion = nil
+
+	lines := make(chan batchLine)
+	done := make(chan struct{})
+	defer close(done)
+	go readLines(ca.inputReader(), lines, done)
+
+	var errs []error
+	for lineNumber := 1; ; lineNumber++ {
+		var line batchLine
+		var ok bool
+		select {
+		case terminationSignal := <-terminationSignals:
+			ca.logger.Debug("Terminating signal received", zap.String("Signal", terminationSignal.String()), zap.String("func", "batchLoop"))
+			return EXIT_SIGNAL, errors.Join(errs...)
+		case line, ok = <-lines:
+		}
+		if !ok {
+			if len(errs) > 0 {
+				return EXIT_ERROR, errors.Join(errs...)
+			}
+			return EXIT_END_OF_INPUT, nil
+		}
+		if line.err != nil {
+			ca.logger.Debug("Could not read from input", zap.Error(line.err), zap.String("func", "batchLoop"))
+			ca.runErrorHooks(line.err)
+			return EXIT_ERROR, errors.Join(append(errs, line.err)...)
+		}
+
+		controlEvent, err := ca.executeBatchLine(line.text, ca.batch.handler)
+		if err != nil {
+			err = fmt.Errorf("%v:%d: %w", batchInputName, lineNumber, err)
+			ca.logger.Debug("Line failed", zap.Error(err), zap.String("func", "batchLoop"))
+			ca.runErrorHooks(err)
+			fmt.Fprintf(ca.ErrorOutput(), "error: %v\n", err)
+			errs = append(errs, err)
+			if !ca.batch.continueOnError {
+				return EXIT_ERROR, err
+			}
+		}
+		if controlEvent != nil && controlEvent.Terminate {
+			return EXIT_TERMINATED, errors.Join(errs...)
+		}
+	}
+}
+
+// readLines sends every line of the input to the channel and closes the channel once the input is exhausted
+// or could not be read.
+//
+// Parameters:
+//   - `input` : Input that is read
+//   - `lines` : Channel that receives the lines
+//   - `done` : Closed once no further lines are received
+func readLines(input io.Reader, lines chan<- batchLine, done <-chan struct{}) {
+	defer close(lines)
+	reader := bufio.NewReader(input)
+	for {
+		text, err := reader.ReadString('\n')
+		if errors.Is(err, io.EOF) && text == "" {
+			return
+		}
+		line := batchLine{text: strings.TrimRight(text, "\r\n")}
+		if err != nil && !errors.Is(err, io.EOF) {
+			line = batchLine{err: err}
+		}
+		select {
+		case lines <- line:
+		case <-done:
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// executeBatchLine executes a line with the handler or dispatches it as key presses, empty lines and
+// comments are skipped.
+//
+// Parameters:
+//   - `line` : Line that should be executed
+//   - `handler` : Executes the line, the line is dispatched as key presses if it is nil
+//
+// Returns:
+//   - `*ControlEvent` : The control event returned for the line, might be nil
+//   - `error` : Returns an error when the line failed
+func (ca *ConsoleApp) executeBatchLine(line string, handler func(line string) (error, *ControlEvent)) (*ControlEvent, error) {
+	trimmedLine := strings.TrimSpace(line)
+	if trimmedLine == "" || strings.HasPrefix(trimmedLine, batchCommentPrefix) {
+		return nil, nil
+	}
+	if handler != nil {
+		eventHistoryEntry := EventHistoryEntry{
+			Token:     line,
+			EventName: SUBMITTED_LINE_EVENT_NAME,
+			Event:     &funcEvent{handle: handler},
+		}
+		return ca.handleEvent(line, eventHistoryEntry)
+	}
+	for _, character := range line + KEY_ENTER {
+		controlEvent, err := ca.dispatchToken(ca.convertByteTokenToStringToken([]byte(string(character))))
+		if err != nil || controlEvent != nil && controlEvent.Terminate {
+			return controlEvent, err
+		}
+	}
+	return nil, nil
+}
+
+// ExitStatus returns the exit status of the last run of Start, e.g. to pass it to os.Exit. It is 1 if the event
+// loop concluded due to an error, a failed line in batch mode or a signal and 0 otherwise.
+//
+// Returns:
+//   - `int` : Exit status of the console application
+func (ca *ConsoleApp) ExitStatus() int {
+	return ca.exitStatus
+}
+
+// RunScript executes the lines of a script file like the batch mode executes the input, it can be called before
+// the event loop starts or from within events. The banner is not printed, while the script runs the delimiter,
+// the prompt and progress lines are not printed either. If a line terminates, the remaining lines are skipped
+// and a running event loop concludes with EXIT_TERMINATED.
+//
+// Parameters:
+//   - `path` : Path of the script file
+//
+// Returns:
+//   - `error` : Returns an error when the script could not be read or a line failed, the error names the file
+//     and the line, see SetContinueOnError
+func (ca *ConsoleApp) RunScript(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("script could not be opened! error: %w", err)
+	}
+	defer file.Close()
+
+	controlEvent, err := ca.runScript(file, path, ca.batch.handler, ca.batch.continueOnError)
+	if controlEvent != nil && controlEvent.Terminate && ca.running {
+		ca.conclude(EXIT_TERMINATED, nil)
+	}
+	return err
+}
+
+// runScript executes the lines of the script one after another.
+//
+// Parameters:
+//   - `script` : Script that is read
+//   - `name` : Name of the script in error messages
+//   - `handler` : Executes the lines, they are dispatched as key presses if it is nil
+//   - `continueOnError` : True to execute the remaining lines after a line failed
+//
+// Returns:
+//   - `*ControlEvent` : The control event of the line that terminated, might be nil
+//   - `error` : Returns an error when the script could not be read or a line failed
+func (ca *ConsoleApp) runScript(
+	script io.Reader,
+	name string,
+	handler func(line string) (error, *ControlEvent),
+	continueOnError bool,
+) (*ControlEvent, error) {
+	active := ca.batch.active
+	ca.batch.active = true
+	defer func() { ca.batch.active = active }()
+	ca.progress.hide()
+
+	reader := bufio.NewReader(script)
+	var errs []error
+	for lineNumber := 1; ; lineNumber++ {
+		text, readErr := reader.ReadString('\n')
+		if readErr != nil && !errors.Is(readErr, io.EOF) {
+			return nil, errors.Join(append(errs, fmt.Errorf("script %v could not be read! error: %w", name, readErr))...)
+		}
+		if text == "" && readErr != nil {
+			return nil, errors.Join(errs...)
+		}
+
+		controlEvent, err := ca.executeBatchLine(strings.TrimRight(text, "\r\n"), handler)
+		if err != nil {
+			err = fmt.Errorf("%v:%d: %w", name, lineNumber, err)
+			ca.logger.Debug("Line failed", zap.Error(err), zap.String("func", "runScript"))
+			errs = append(errs, err)
+			if !continueOnError {
+				return nil, err
+			}
+		}
+		if controlEvent != nil && controlEvent.Terminate {
+			return controlEvent, errors.Join(errs...)
+		}
+	}
+}
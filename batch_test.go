@@ -0,0 +1,197 @@
+//go:build unit_test
+
+package cyclecmd_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/RaphSku/cyclecmd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupBatchConsoleApp(t *testing.T, input string) (*cyclecmd.ConsoleApp, *bytes.Buffer, *bytes.Buffer) {
+	output := &bytes.Buffer{}
+	errorOutput := &bytes.Buffer{}
+	consoleApp := cyclecmd.NewConsoleApp(
+		"test",
+		"0.1.0",
+		"This is a test console application",
+		cyclecmd.NewEventRegistry(setupDefaultEventInformation()),
+		cyclecmd.NewEventHistory(),
+	)
+	consoleApp.SetInput(strings.NewReader(input))
+	consoleApp.SetOutput(output)
+	consoleApp.SetErrorOutput(errorOutput)
+
+	commandRegistry := cyclecmd.NewCommandRegistry(consoleApp)
+	commands := []cyclecmd.Command{
+		{
+			Name: "greet",
+			Run: func(args []string) (error, *cyclecmd.ControlEvent) {
+				fmt.Fprintf(consoleApp.Output(), "Hello %v\n", strings.Join(args, " "))
+				return nil, nil
+			},
+		},
+		{
+			Name: "fail",
+			Run: func(args []string) (error, *cyclecmd.ControlEvent) {
+				return fmt.Errorf("command failed"), nil
+			},
+		},
+		{
+			Name: "quit",
+			Run: func(args []string) (error, *cyclecmd.ControlEvent) {
+				return nil, cyclecmd.NewControlEvent(cyclecmd.CYCLE_TERMINATE)
+			},
+		},
+	}
+	for _, command := range commands {
+		require.NoError(t, commandRegistry.RegisterCommand(command))
+	}
+	consoleApp.SetBatchHandler(commandRegistry.Execute)
+	return consoleApp, output, errorOutput
+}
+
+func TestBatchMode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		input           string
+		continueOnError bool
+		expReason       cyclecmd.ExitReason
+		expExitStatus   int
+		expOutput       string
+		expErrorOutput  string
+		expLines        []string
+	}{
+		{
+			name:          "success",
+			input:         "greet Jane\n# comment\n\n  \ngreet John",
+			expReason:     cyclecmd.EXIT_END_OF_INPUT,
+			expExitStatus: 0,
+			expOutput:     "Hello Jane\nHello John\n",
+			expLines:      []string{"greet Jane", "greet John"},
+		},
+		{
+			name:           "stop on error",
+			input:          "greet Jane\r\nunknown\r\nfail\r\ngreet John\r\n",
+			expReason:      cyclecmd.EXIT_ERROR,
+			expExitStatus:  1,
+			expOutput:      "Hello Jane\n",
+			expErrorOutput: "error: input:2: command unknown is not registered\n",
+			expLines:       []string{"greet Jane", "unknown"},
+		},
+		{
+			name:            "continue on error",
+			input:           "greet Jane\nunknown\nfail\ngreet John\n",
+			continueOnError: true,
+			expReason:       cyclecmd.EXIT_ERROR,
+			expExitStatus:   1,
+			expOutput:       "Hello Jane\nHello John\n",
+			expErrorOutput:  "error: input:2: command unknown is not registered\nerror: input:3: command failed\n",
+			expLines:        []string{"greet Jane", "unknown", "fail", "greet John"},
+		},
+		{
+			name:          "terminate",
+			input:         "greet Jane\nquit\ngreet John\n",
+			expReason:     cyclecmd.EXIT_TERMINATED,
+			expExitStatus: 0,
+			expOutput:     "Hello Jane\n",
+			expLines:      []string{"greet Jane", "quit"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			consoleApp, output, errorOutput := setupBatchConsoleApp(t, test.input)
+			consoleApp.SetBatchMode(cyclecmd.BATCH_ON)
+			consoleApp.SetContinueOnError(test.continueOnError)
+			var actReason cyclecmd.ExitReason
+			consoleApp.OnExit(func(reason cyclecmd.ExitReason, err error) {
+				actReason = reason
+			})
+
+			consoleApp.Start()
+
+			assert.Equal(t, test.expReason, actReason)
+			assert.Equal(t, test.expExitStatus, consoleApp.ExitStatus())
+			assert.Equal(t, test.expOutput, output.String())
+			assert.Equal(t, test.expErrorOutput, errorOutput.String())
+			assert.Equal(t, test.expLines, consoleApp.EventHistory().SubmittedLines())
+			assert.False(t, consoleApp.IsBatch())
+		})
+	}
+}
+
+func TestBatchModeDetection(t *testing.T) {
+	t.Parallel()
+
+	consoleApp, output, _ := setupBatchConsoleApp(t, "greet Jane\n")
+	consoleApp.SetBatchMode(cyclecmd.BATCH_AUTO)
+	consoleApp.Start()
+	assert.Equal(t, "Hello Jane\n", output.String())
+
+	consoleApp, output, _ = setupBatchConsoleApp(t, "")
+	consoleApp.SetInput(newTokenReader("a", "b"))
+	consoleApp.Start()
+	assert.Contains(t, output.String(), "Welcome")
+	assert.Equal(t, 2, consoleApp.EventHistory().Len())
+}
+
+func TestBatchModeDispatchesKeys(t *testing.T) {
+	t.Parallel()
+
+	eventRegistry := cyclecmd.NewEventRegistry(setupDefaultEventInformation())
+	consoleApp := cyclecmd.NewConsoleApp("test", "0.1.0", "This is a test console application", eventRegistry, cyclecmd.NewEventHistory())
+	output := &bytes.Buffer{}
+	consoleApp.SetInput(strings.NewReader("hi\nyo\n"))
+	consoleApp.SetOutput(output)
+	consoleApp.SetBatchMode(cyclecmd.BATCH_ON)
+	var submittedLines []string
+	lineEditor := cyclecmd.NewLineEditor(consoleApp, func(line string) (error, *cyclecmd.ControlEvent) {
+		submittedLines = append(submittedLines, line)
+		return nil, nil
+	})
+	require.NoError(t, lineEditor.RegisterEvents(eventRegistry))
+	consoleApp.SetLineDelimiter("\r\n> ", cyclecmd.KEY_ENTER)
+
+	consoleApp.Start()
+
+	assert.Equal(t, []string{"hi", "yo"}, submittedLines)
+	assert.Equal(t, 6, consoleApp.EventHistory().Len()-len(submittedLines))
+	assert.NotContains(t, output.String(), "Welcome")
+	assert.NotContains(t, output.String(), ">")
+	assert.Equal(t, 0, consoleApp.ExitStatus())
+}
+
+func TestRunScript(t *testing.T) {
+	t.Parallel()
+
+	consoleApp, output, _ := setupBatchConsoleApp(t, "")
+	directory := t.TempDir()
+	scriptPath := filepath.Join(directory, "script")
+	require.NoError(t, os.WriteFile(scriptPath, []byte("greet Jane\n\nfail\ngreet John\n"), 0o644))
+
+	err := consoleApp.RunScript(scriptPath)
+	if assert.Error(t, err) {
+		assert.Equal(t, scriptPath+":3: command failed", err.Error())
+	}
+	assert.Equal(t, "Hello Jane\n", output.String())
+
+	output.Reset()
+	consoleApp.SetContinueOnError(true)
+	err = consoleApp.RunScript(scriptPath)
+	assert.Error(t, err)
+	assert.Equal(t, "Hello Jane\nHello John\n", output.String())
+
+	err = consoleApp.RunScript(filepath.Join(directory, "missing"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
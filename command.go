@@ -0,0 +1,262 @@
+package cyclecmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// minimumHelpDescriptionWidth is the narrowest width in columns the descriptions of the help output are wrapped to.
+const minimumHelpDescriptionWidth = 20
+
+// Command is a named command that can be executed by submitting a line that starts with its name.
+type Command struct {
+	// Name of the command, it is the first word of the line
+	Name string
+	// Description of the command, should be relatively short
+	Description string
+	// Run executes the command with the remaining words of the line as arguments
+	Run func(args []string) (error, *ControlEvent)
+	// ArgumentCompleters complete the arguments of the command, the nth completer completes the nth argument.
+	// The last completer is used for all further arguments.
+	ArgumentCompleters []Completer
+}
+
+// CommandRegistry contains all commands that are registered with this registry and executes submitted lines.
+// The built-in `help` command is always registered and lists all commands, the built-in commands `source`,
+// `alias` and `bind` are registered by EnableBuiltins.
+type CommandRegistry struct {
+	consoleApp *ConsoleApp
+
+	// commands is a key-value data structure, the key contains the command name and the value contains the command
+	commands map[string]*Command
+	// aliases maps the name of an alias to the command line it stands for
+	aliases map[string]string
+	// sourceDepth is the number of files that are currently sourced
+	sourceDepth int
+}
+
+// NewCommandRegistry initialises the command registry.
+//
+// Parameters:
+//   - `consoleApp` : Console application the commands print to
+//
+// Returns:
+//   - `*CommandRegistry` : Returns an instance of the command registry
+func NewCommandRegistry(consoleApp *ConsoleApp) *CommandRegistry {
+	commandRegistry := &CommandRegistry{
+		consoleApp: consoleApp,
+		commands:   make(map[string]*Command),
+		aliases:    make(map[string]string),
+	}
+	commandRegistry.commands["help"] = &Command{
+		Name:        "help",
+		Description: "Lists all available commands",
+		Run: func(args []string) (error, *ControlEvent) {
+			commandRegistry.PrintHelp()
+			return nil, nil
+		},
+	}
+	return commandRegistry
+}
+
+// RegisterCommand registers a command under its name.
+//
+// Parameters:
+//   - `command` : Command that should be registered
+//
+// Returns:
+//   - `error` : Returns an error when the command has no name, no run function or is already registered
+func (cr *CommandRegistry) RegisterCommand(command Command) error {
+	if command.Name == "" || strings.ContainsAny(command.Name, " \t") {
+		return fmt.Errorf("command name %q is invalid, it must not be empty or contain whitespace", command.Name)
+	}
+	if command.Run == nil {
+		return fmt.Errorf("command %v has no run function", command.Name)
+	}
+	_, ok := cr.commands[command.Name]
+	if ok {
+		return fmt.Errorf("command is already registered under name %v", command.Name)
+	}
+	cr.commands[command.Name] = &command
+	return nil
+}
+
+// SetArgumentCompleter sets the completer for the argument at position of a registered command.
+//
+// Parameters:
+//   - `commandName` : Name of the command
+//   - `position` : Position of the argument, starting at 0
+//   - `completer` : Completer for the argument
+//
+// Returns:
+//   - `error` : Returns an error when the command is not registered or the position is negative
+func (cr *CommandRegistry) SetArgumentCompleter(commandName string, position int, completer Completer) error {
+	command, ok := cr.commands[commandName]
+	if !ok {
+		return fmt.Errorf("command %v is not registered", commandName)
+	}
+	if position < 0 {
+		return fmt.Errorf("argument position %v is invalid, it must not be negative", position)
+	}
+	for len(command.ArgumentCompleters) <= position {
+		command.ArgumentCompleters = append(command.ArgumentCompleters, nil)
+	}
+	command.ArgumentCompleters[position] = completer
+	return nil
+}
+
+// Commands returns all registered commands sorted by their name.
+//
+// Returns:
+//   - `[]Command` : All registered commands
+func (cr *CommandRegistry) Commands() []Command {
+	commands := make([]Command, 0, len(cr.commands))
+	for _, command := range cr.commands {
+		commands = append(commands, *command)
+	}
+	sort.Slice(commands, func(i, j int) bool {
+		return commands[i].Name < commands[j].Name
+	})
+	return commands
+}
+
+// Execute executes the line, the first word is the command name and the remaining words are its arguments.
+// Empty lines are ignored and a first word that is an alias is replaced by its command line, see RegisterAlias.
+// The exit status of the command is available via ConsoleApp.LastExitStatus.
+//
+// Parameters:
+//   - `line` : Line that should be executed
+//
+// Returns:
+//   - `error` : Returns an error when the command is unknown or the command failed
+//   - `*ControlEvent` : Control event returned by the command
+func (cr *CommandRegistry) Execute(line string) (error, *ControlEvent) {
+	return cr.execute(line, false)
+}
+
+// execute executes the line, see Execute.
+//
+// Parameters:
+//   - `line` : Line that should be executed
+//   - `sourced` : True if the line is read from a sourced file, the built-in commands are available and an
+//     unknown command is not named in the error, so that the content of the file is not revealed
+//
+// Returns:
+//   - `error` : Returns an error when the command is unknown or the command failed
+//   - `*ControlEvent` : Control event returned by the command
+func (cr *CommandRegistry) execute(line string, sourced bool) (error, *ControlEvent) {
+	words := strings.Fields(line)
+	if len(words) == 0 {
+		return nil, nil
+	}
+	if commandLine, ok := cr.aliases[words[0]]; ok {
+		words = append(strings.Fields(commandLine), words[1:]...)
+	}
+	command, ok := cr.commands[words[0]]
+	if !ok && sourced {
+		command, ok = cr.builtinCommand(words[0])
+	}
+	if !ok {
+		cr.consoleApp.lastExitStatus = 1
+		if sourced {
+			return fmt.Errorf("line does not start with a registered command"), nil
+		}
+		return fmt.Errorf("command %v is not registered", words[0]), nil
+	}
+	cr.consoleApp.logger.Debug("Executing command", zap.String("command", command.Name), zap.Strings("args", words[1:]), zap.String("func", "Execute"))
+	err, controlEvent := command.Run(words[1:])
+	cr.consoleApp.lastExitStatus = 0
+	if err != nil {
+		cr.consoleApp.lastExitStatus = 1
+	}
+	return err, controlEvent
+}
+
+// Submit executes the line and prints an error instead of returning it, so that a failing command does not
+// stop the event loop. It can be passed as submit callback to NewLineEditor.
+//
+// Parameters:
+//   - `line` : Line that should be executed
+//
+// Returns:
+//   - `error` : Returns no error in this case
+//   - `*ControlEvent` : Control event returned by the command
+func (cr *CommandRegistry) Submit(line string) (error, *ControlEvent) {
+	err, controlEvent := cr.Execute(line)
+	if err != nil {
+		errorMessage := cr.consoleApp.render(cr.consoleApp.theme.Error, fmt.Sprintf("error: %v", err))
+		fmt.Fprintf(cr.consoleApp.Output(), "%s\r\n", errorMessage)
+	}
+	return nil, controlEvent
+}
+
+// PrintHelp prints all registered commands together with their descriptions, styled with the help styles
+// of the theme. Descriptions are wrapped to the width of the terminal.
+func (cr *CommandRegistry) PrintHelp() {
+	commands := cr.Commands()
+	nameWidth := 0
+	for _, command := range commands {
+		nameWidth = max(nameWidth, displayWidth(command.Name))
+	}
+	terminalWidth, _ := cr.consoleApp.Size()
+	// Descriptions are not wrapped into a column that is too narrow to be readable
+	descriptionWidth := max(terminalWidth-nameWidth-2, minimumHelpDescriptionWidth)
+	theme := cr.consoleApp.theme
+	for _, command := range commands {
+		name := cr.consoleApp.render(theme.HelpKey, truncateText(command.Name, terminalWidth))
+		padding := strings.Repeat(" ", max(0, nameWidth-displayWidth(command.Name)))
+		for i, line := range wrapText(command.Description, descriptionWidth) {
+			if i > 0 {
+				name, padding = "", strings.Repeat(" ", nameWidth)
+			}
+			description := cr.consoleApp.render(theme.HelpDescription, line)
+			fmt.Fprintf(cr.consoleApp.Output(), "%s%s  %s\r\n", name, padding, description)
+		}
+	}
+}
+
+// Completer returns a completer that completes command names for the first word of the line and
+// delegates the completion of arguments to the argument completers of the command.
+//
+// Returns:
+//   - `Completer` : Completer for the commands of this registry
+func (cr *CommandRegistry) Completer() Completer {
+	return CompleterFunc(cr.complete)
+}
+
+// complete completes the command name or the argument in front of the cursor.
+//
+// Parameters:
+//   - `line` : The current line
+//   - `cursor` : Position of the cursor within the line
+//
+// Returns:
+//   - `[]Candidate` : Candidates for the word in front of the cursor
+func (cr *CommandRegistry) complete(line string, cursor int) []Candidate {
+	wordStart, word := wordInFrontOfCursor(line, cursor)
+	precedingWords := strings.Fields(string([]rune(line)[:wordStart]))
+
+	if len(precedingWords) == 0 {
+		var candidates []Candidate
+		for _, command := range cr.Commands() {
+			if strings.HasPrefix(command.Name, word) {
+				candidates = append(candidates, Candidate{Value: command.Name, Description: command.Description})
+			}
+		}
+		return candidates
+	}
+
+	command, ok := cr.commands[precedingWords[0]]
+	if !ok || len(command.ArgumentCompleters) == 0 {
+		return nil
+	}
+	position := min(len(precedingWords)-1, len(command.ArgumentCompleters)-1)
+	completer := command.ArgumentCompleters[position]
+	if completer == nil {
+		return nil
+	}
+	return completer.Complete(line, cursor)
+}
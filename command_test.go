@@ -0,0 +1,140 @@
+//go:build unit_test
+
+package cyclecmd_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/RaphSku/cyclecmd"
+	"github.com/RaphSku/cyclecmd/style"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupCommandRegistry() (*cyclecmd.CommandRegistry, *bytes.Buffer) {
+	consoleApp := cyclecmd.NewConsoleApp(
+		"test",
+		"0.1.0",
+		"This is a test console application",
+		cyclecmd.NewEventRegistry(setupDefaultEventInformation()),
+		cyclecmd.NewEventHistory(),
+	)
+	output := &bytes.Buffer{}
+	consoleApp.SetOutput(output)
+	return cyclecmd.NewCommandRegistry(consoleApp), output
+}
+
+func TestCommandRegistration(t *testing.T) {
+	t.Parallel()
+
+	commandRegistry, _ := setupCommandRegistry()
+	command := cyclecmd.Command{
+		Name:        "greet",
+		Description: "Greets the user",
+		Run: func(args []string) (error, *cyclecmd.ControlEvent) {
+			return nil, nil
+		},
+	}
+	err := commandRegistry.RegisterCommand(command)
+	assert.NoError(t, err)
+
+	err = commandRegistry.RegisterCommand(command)
+	if assert.Error(t, err) {
+		assert.Equal(t, fmt.Errorf("command is already registered under name %v", "greet"), err)
+	}
+
+	err = commandRegistry.RegisterCommand(cyclecmd.Command{Name: "no run"})
+	if assert.Error(t, err) {
+		assert.Equal(t, fmt.Errorf("command name %q is invalid, it must not be empty or contain whitespace", "no run"), err)
+	}
+
+	actNames := []string{}
+	for _, command := range commandRegistry.Commands() {
+		actNames = append(actNames, command.Name)
+	}
+	assert.Equal(t, []string{"greet", "help"}, actNames)
+}
+
+func TestCommandExecution(t *testing.T) {
+	t.Parallel()
+
+	commandRegistry, output := setupCommandRegistry()
+	var actArgs []string
+	err := commandRegistry.RegisterCommand(cyclecmd.Command{
+		Name:        "greet",
+		Description: "Greets the user",
+		Run: func(args []string) (error, *cyclecmd.ControlEvent) {
+			actArgs = args
+			return nil, cyclecmd.NewControlEvent(cyclecmd.CYCLE_TERMINATE)
+		},
+	})
+	assert.NoError(t, err)
+
+	err, controlEvent := commandRegistry.Execute("  greet  Jane Doe ")
+	assert.NoError(t, err)
+	assert.True(t, controlEvent.Terminate)
+	assert.Equal(t, []string{"Jane", "Doe"}, actArgs)
+
+	err, _ = commandRegistry.Execute("unknown")
+	if assert.Error(t, err) {
+		assert.Equal(t, fmt.Errorf("command %v is not registered", "unknown"), err)
+	}
+
+	err, _ = commandRegistry.Submit("unknown")
+	assert.NoError(t, err)
+	assert.Equal(t, "error: command unknown is not registered\r\n", output.String())
+
+	output.Reset()
+	err, _ = commandRegistry.Execute("help")
+	assert.NoError(t, err)
+	assert.Equal(t, "greet  Greets the user\r\nhelp   Lists all available commands\r\n", output.String())
+}
+
+func TestCommandCompleter(t *testing.T) {
+	t.Parallel()
+
+	commandRegistry, _ := setupCommandRegistry()
+	err := commandRegistry.RegisterCommand(cyclecmd.Command{
+		Name:        "checkout",
+		Description: "Checks out a branch",
+		Run: func(args []string) (error, *cyclecmd.ControlEvent) {
+			return nil, nil
+		},
+	})
+	assert.NoError(t, err)
+	err = commandRegistry.SetArgumentCompleter("checkout", 0, cyclecmd.NewStaticCompleter("main", "feature"))
+	assert.NoError(t, err)
+	err = commandRegistry.SetArgumentCompleter("unknown", 0, cyclecmd.NewStaticCompleter())
+	assert.Error(t, err)
+
+	completer := commandRegistry.Completer()
+	assert.Equal(t, []cyclecmd.Candidate{{Value: "checkout", Description: "Checks out a branch"}}, completer.Complete("ch", 2))
+	assert.Equal(t, []cyclecmd.Candidate{{Value: "main"}}, completer.Complete("checkout m", 10))
+	assert.Nil(t, completer.Complete("help x", 6))
+}
+
+func TestThemedCommandOutput(t *testing.T) {
+	t.Parallel()
+
+	consoleApp := cyclecmd.NewConsoleApp(
+		"test",
+		"0.1.0",
+		"This is a test console application",
+		cyclecmd.NewEventRegistry(setupDefaultEventInformation()),
+		cyclecmd.NewEventHistory(),
+	)
+	output := &bytes.Buffer{}
+	consoleApp.SetOutput(output)
+	consoleApp.SetColorProfile(style.PROFILE_ANSI16)
+	consoleApp.SetTheme(style.ColorfulTheme())
+	commandRegistry := cyclecmd.NewCommandRegistry(consoleApp)
+
+	err, _ := commandRegistry.Execute("help")
+	assert.NoError(t, err)
+	assert.Equal(t, "\x1b[1;36mhelp\x1b[0m  \x1b[2mLists all available commands\x1b[0m\r\n", output.String())
+
+	output.Reset()
+	_, _ = commandRegistry.Submit("unknown")
+	assert.Equal(t, "\x1b[1;91merror: command unknown is not registered\x1b[0m\r\n", output.String())
+}
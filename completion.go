@@ -0,0 +1,233 @@
+package cyclecmd
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Candidate is a possible completion of the word in front of the cursor.
+type Candidate struct {
+	// Value replaces the word in front of the cursor
+	Value string
+	// Description is shown next to the value when the candidates are listed, might be empty
+	Description string
+}
+
+// Completer is an interface that defines how the line editor completes the word in front of the cursor.
+//
+// Completer expects the following method to be implemented:
+//
+// Behavior:
+//   - `Complete(line string, cursor int) []Candidate` : it expects the current line and the cursor position
+//     counted in runes, the returned candidates replace the word in front of the cursor
+type Completer interface {
+	Complete(line string, cursor int) []Candidate
+}
+
+// CompleterFunc adapts a function to the Completer interface.
+type CompleterFunc func(line string, cursor int) []Candidate
+
+// Complete calls the adapted function.
+//
+// Parameters:
+//   - `line` : The current line
+//   - `cursor` : Position of the cursor within the line
+//
+// Returns:
+//   - `[]Candidate` : Candidates for the word in front of the cursor
+func (cf CompleterFunc) Complete(line string, cursor int) []Candidate {
+	return cf(line, cursor)
+}
+
+// StaticCompleter completes the word in front of the cursor with a fixed set of candidates.
+type StaticCompleter struct {
+	candidates []Candidate
+}
+
+// NewStaticCompleter initialises a completer that completes with the given values.
+//
+// Parameters:
+//   - `values` : Values that are offered as candidates
+//
+// Returns:
+//   - `*StaticCompleter` : Returns an instance of StaticCompleter
+func NewStaticCompleter(values ...string) *StaticCompleter {
+	candidates := make([]Candidate, 0, len(values))
+	for _, value := range values {
+		candidates = append(candidates, Candidate{Value: value})
+	}
+	return &StaticCompleter{candidates: candidates}
+}
+
+// Complete returns all values that start with the word in front of the cursor.
+//
+// Parameters:
+//   - `line` : The current line
+//   - `cursor` : Position of the cursor within the line
+//
+// Returns:
+//   - `[]Candidate` : Candidates for the word in front of the cursor
+func (sc *StaticCompleter) Complete(line string, cursor int) []Candidate {
+	_, word := wordInFrontOfCursor(line, cursor)
+	var candidates []Candidate
+	for _, candidate := range sc.candidates {
+		if strings.HasPrefix(candidate.Value, word) {
+			candidates = append(candidates, candidate)
+		}
+	}
+	return candidates
+}
+
+// FilePathCompleter completes the word in front of the cursor with paths of the file system.
+// Directories are completed with a trailing path separator.
+type FilePathCompleter struct{}
+
+// NewFilePathCompleter initialises a completer for file paths.
+//
+// Returns:
+//   - `*FilePathCompleter` : Returns an instance of FilePathCompleter
+func NewFilePathCompleter() *FilePathCompleter {
+	return &FilePathCompleter{}
+}
+
+// Complete returns all paths that start with the word in front of the cursor.
+//
+// Parameters:
+//   - `line` : The current line
+//   - `cursor` : Position of the cursor within the line
+//
+// Returns:
+//   - `[]Candidate` : Candidates for the word in front of the cursor
+func (fpc *FilePathCompleter) Complete(line string, cursor int) []Candidate {
+	_, word := wordInFrontOfCursor(line, cursor)
+	directory, prefix := filepath.Split(word)
+	readDirectory := directory
+	if readDirectory == "" {
+		readDirectory = "."
+	}
+	entries, err := os.ReadDir(readDirectory)
+	if err != nil {
+		return nil
+	}
+	var candidates []Candidate
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		// Hidden files are only offered when they are asked for explicitly
+		if strings.HasPrefix(name, ".") && !strings.HasPrefix(prefix, ".") {
+			continue
+		}
+		value := directory + name
+		if entry.IsDir() {
+			value += string(filepath.Separator)
+		}
+		candidates = append(candidates, Candidate{Value: value})
+	}
+	return candidates
+}
+
+// wordInFrontOfCursor returns the word that ends at the cursor, words are separated by whitespace.
+//
+// Parameters:
+//   - `line` : The current line
+//   - `cursor` : Position of the cursor within the line
+//
+// Returns:
+//   - `int` : Position where the word starts
+//   - `string` : The word in front of the cursor, empty if the cursor follows whitespace
+func wordInFrontOfCursor(line string, cursor int) (int, string) {
+	runes := []rune(line)
+	if cursor > len(runes) {
+		cursor = len(runes)
+	}
+	start := cursor
+	for start > 0 && !unicode.IsSpace(runes[start-1]) {
+		start -= 1
+	}
+	return start, string(runes[start:cursor])
+}
+
+// commonPrefix returns the longest prefix that all candidate values share.
+//
+// Parameters:
+//   - `candidates` : Candidates whose values are compared
+//
+// Returns:
+//   - `string` : The longest common prefix
+func commonPrefix(candidates []Candidate) string {
+	if len(candidates) == 0 {
+		return ""
+	}
+	prefix := []rune(candidates[0].Value)
+	for _, candidate := range candidates[1:] {
+		value := []rune(candidate.Value)
+		length := 0
+		for length < len(prefix) && length < len(value) && prefix[length] == value[length] {
+			length += 1
+		}
+		prefix = prefix[:length]
+	}
+	return string(prefix)
+}
+
+// sortCandidates sorts the candidates by their value.
+//
+// Parameters:
+//   - `candidates` : Candidates that are sorted in place
+func sortCandidates(candidates []Candidate) {
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Value < candidates[j].Value
+	})
+}
+
+// formatCandidates formats the candidates for listing them under the prompt. Candidates with descriptions are
+// listed one per line, otherwise the candidates are arranged in columns like `ls` does. Lines that are wider
+// than the available width are truncated.
+//
+// Parameters:
+//   - `candidates` : Candidates that are listed
+//   - `width` : Available width in columns
+//
+// Returns:
+//   - `[]string` : Formatted lines
+func formatCandidates(candidates []Candidate, width int) []string {
+	valueWidth := 0
+	hasDescription := false
+	for _, candidate := range candidates {
+		valueWidth = max(valueWidth, displayWidth(candidate.Value))
+		hasDescription = hasDescription || candidate.Description != ""
+	}
+
+	var lines []string
+	if hasDescription {
+		for _, candidate := range candidates {
+			padding := strings.Repeat(" ", valueWidth-displayWidth(candidate.Value))
+			line := candidate.Value + padding + "  " + candidate.Description
+			lines = append(lines, truncateText(strings.TrimRight(line, " "), width))
+		}
+		return lines
+	}
+
+	columnWidth := valueWidth + 2
+	columns := max(1, width/columnWidth)
+	rows := (len(candidates) + columns - 1) / columns
+	for row := 0; row < rows; row++ {
+		var line strings.Builder
+		for column := 0; column < columns; column++ {
+			// Candidates are arranged column by column
+			index := column*rows + row
+			if index >= len(candidates) {
+				break
+			}
+			line.WriteString(candidates[index].Value)
+			line.WriteString(strings.Repeat(" ", columnWidth-displayWidth(candidates[index].Value)))
+		}
+		lines = append(lines, truncateText(strings.TrimRight(line.String(), " "), width))
+	}
+	return lines
+}
@@ -0,0 +1,98 @@
+//go:build unit_test
+
+package cyclecmd_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/RaphSku/cyclecmd"
+	"github.com/stretchr/testify/assert"
+)
+
+func typeTokens(text string) []string {
+	tokens := []string{}
+	for _, character := range text {
+		tokens = append(tokens, string(character))
+	}
+	return tokens
+}
+
+func TestFilePathCompleter(t *testing.T) {
+	t.Parallel()
+
+	directory := t.TempDir()
+	assert.NoError(t, os.Mkdir(filepath.Join(directory, "docs"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(directory, "doc.txt"), []byte{}, 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(directory, ".hidden"), []byte{}, 0o644))
+
+	line := "open " + directory + string(filepath.Separator) + "do"
+	actCandidates := cyclecmd.NewFilePathCompleter().Complete(line, len([]rune(line)))
+	expCandidates := []cyclecmd.Candidate{
+		{Value: filepath.Join(directory, "doc.txt")},
+		{Value: filepath.Join(directory, "docs") + string(filepath.Separator)},
+	}
+	assert.ElementsMatch(t, expCandidates, actCandidates)
+}
+
+func TestTabCompletion(t *testing.T) {
+	t.Parallel()
+
+	completer := cyclecmd.NewStaticCompleter("status", "stash", "show")
+
+	tokens := append(typeTokens("git st"), cyclecmd.KEY_TAB)
+	consoleApp, lineEditor, _, _ := setupLineEditorConsoleApp(tokens...)
+	lineEditor.SetCompleter(completer)
+	consoleApp.Start()
+	assert.Equal(t, "git sta", lineEditor.Line())
+
+	tokens = append(typeTokens("git sh"), cyclecmd.KEY_TAB)
+	consoleApp, lineEditor, _, _ = setupLineEditorConsoleApp(tokens...)
+	lineEditor.SetCompleter(completer)
+	consoleApp.Start()
+	assert.Equal(t, "git show ", lineEditor.Line())
+
+	tokens = append(typeTokens("git sta"), cyclecmd.KEY_TAB, cyclecmd.KEY_TAB, cyclecmd.KEY_TAB)
+	consoleApp, lineEditor, _, _ = setupLineEditorConsoleApp(tokens...)
+	lineEditor.SetCompleter(completer)
+	consoleApp.Start()
+	assert.Equal(t, "git status", lineEditor.Line())
+}
+
+func TestTabCompletionListsWideCandidates(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		candidates []cyclecmd.Candidate
+		expLines   string
+	}{
+		{
+			name:       "columns",
+			candidates: []cyclecmd.Candidate{{Value: "日本語"}, {Value: "ab"}, {Value: "cd"}},
+			expLines:   "\r\nab      cd      日本語\r\n",
+		},
+		{
+			name:       "descriptions",
+			candidates: []cyclecmd.Candidate{{Value: "日本語", Description: "wide"}, {Value: "ab", Description: "narrow"}},
+			expLines:   "\r\nab      narrow\r\n日本語  wide\r\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			consoleApp, lineEditor, _, _ := setupLineEditorConsoleApp(cyclecmd.KEY_TAB)
+			output := &bytes.Buffer{}
+			consoleApp.SetOutput(output)
+			consoleApp.Resize(40, 10)
+			lineEditor.SetCompleter(cyclecmd.CompleterFunc(func(line string, cursor int) []cyclecmd.Candidate {
+				return test.candidates
+			}))
+			consoleApp.Start()
+			assert.Contains(t, output.String(), test.expLines)
+		})
+	}
+}
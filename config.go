@@ -0,0 +1,85 @@
+package cyclecmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFormat is the format of a configuration file, like a keybinding file.
+type ConfigFormat string
+
+const (
+	// CONFIG_FORMAT_YAML is the format of files with the extension .yaml or .yml
+	CONFIG_FORMAT_YAML ConfigFormat = "yaml"
+	// CONFIG_FORMAT_JSON is the format of files with the extension .json
+	CONFIG_FORMAT_JSON ConfigFormat = "json"
+	// CONFIG_FORMAT_TOML is the format of files with the extension .toml
+	CONFIG_FORMAT_TOML ConfigFormat = "toml"
+)
+
+// ConfigFormatOf determines the format of a configuration file from its extension.
+//
+// Parameters:
+//   - `path` : Path of the configuration file
+//
+// Returns:
+//   - `ConfigFormat` : Format of the configuration file
+//   - `error` : Returns an error when the extension is unknown
+func ConfigFormatOf(path string) (ConfigFormat, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return CONFIG_FORMAT_YAML, nil
+	case ".json":
+		return CONFIG_FORMAT_JSON, nil
+	case ".toml":
+		return CONFIG_FORMAT_TOML, nil
+	}
+	return "", fmt.Errorf("format of %v is unknown, the extension has to be .yaml, .yml, .json or .toml", path)
+}
+
+// decodeConfig decodes the content of a configuration file into the value. Fields that are unknown are
+// rejected, so that typos do not go unnoticed.
+//
+// Parameters:
+//   - `data` : Content of the configuration file
+//   - `format` : Format of the content
+//   - `value` : Pointer to the value the content is decoded into
+//
+// Returns:
+//   - `error` : Returns an error when the content is invalid
+func decodeConfig(data []byte, format ConfigFormat, value any) error {
+	switch format {
+	case CONFIG_FORMAT_YAML:
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		// An empty file is no error
+		if err := decoder.Decode(value); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("yaml could not be decoded! error: %w", err)
+		}
+	case CONFIG_FORMAT_JSON:
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(value); err != nil {
+			return fmt.Errorf("json could not be decoded! error: %w", err)
+		}
+	case CONFIG_FORMAT_TOML:
+		metaData, err := toml.Decode(string(data), value)
+		if err != nil {
+			return fmt.Errorf("toml could not be decoded! error: %w", err)
+		}
+		if undecoded := metaData.Undecoded(); len(undecoded) > 0 {
+			return fmt.Errorf("toml could not be decoded, the key %v is unknown", undecoded[0])
+		}
+	default:
+		return fmt.Errorf("format %v is unknown", format)
+	}
+	return nil
+}
@@ -4,20 +4,25 @@ const (
 	// CYCLE_TERMINATE is a flag indicating the termination event.
 	// It has the integer value 1.
 	CYCLE_TERMINATE int = 1
+	// CYCLE_VETO is a flag indicating that an event should not be handled.
+	// It has the integer value 2.
+	CYCLE_VETO int = 2
 )
 
 // ControlEvent represents control signals with boolean flags.
 //
-// Currently, it contains a single flag:
+// Currently, it contains the following flags:
 //   - Terminate: true if the termination flag is set.
+//   - Veto: true if the veto flag is set, only respected when returned by a BeforeEvent hook.
 type ControlEvent struct {
 	Terminate bool
+	Veto      bool
 }
 
 // NewControlEvent creates a new ControlEvent from the given flags integer.
 //
-// It checks if the TERMINATE bit is set in the flags and sets the
-// Terminate field accordingly.
+// It checks if the TERMINATE and VETO bits are set in the flags and sets the
+// Terminate and Veto fields accordingly.
 //
 // Parameters:
 //   - `flags` : A number of control events whose bit should be set
@@ -31,6 +36,10 @@ func NewControlEvent(flags int) *ControlEvent {
 	if flags&CYCLE_TERMINATE != 0 {
 		controlEvent.Terminate = true
 	}
+	controlEvent.Veto = false
+	if flags&CYCLE_VETO != 0 {
+		controlEvent.Veto = true
+	}
 
 	return controlEvent
 }
@@ -0,0 +1,161 @@
+package cyclecmdtest
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// escapeSequence matches control sequences, operating system commands and two-byte escape sequences.
+var escapeSequence = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(\x07|\x1b\\)|\x1b[()][0-9A-Za-z]|\x1b[0-9=>A-Z\\^_a-z]`)
+
+// Expect drives a console application like the expect tool: input is sent and the output and the event history
+// are checked in order. Before every check, Expect waits until the event loop handled all keys that were sent,
+// so that no sleeps are needed. Every successful check consumes the output or the events up to the match, so
+// that the next check only looks at what followed. Failed checks mark the test as failed.
+type Expect struct {
+	tb       testing.TB
+	terminal *Terminal
+	timeout  time.Duration
+	// outputOffset is the position in the output without escape sequences after the last match
+	outputOffset int
+	// historyOffset is the index of the first event history entry after the last matched event
+	historyOffset int
+}
+
+// NewExpect initialises an expect driver for the console application that runs in the terminal, the terminal
+// is not started.
+//
+// Parameters:
+//   - `tb` : Test that is marked as failed when a check fails
+//   - `terminal` : Terminal the console application runs in
+//
+// Returns:
+//   - `*Expect` : Returns an instance of Expect
+func NewExpect(tb testing.TB, terminal *Terminal) *Expect {
+	return &Expect{tb: tb, terminal: terminal, timeout: DEFAULT_TIMEOUT}
+}
+
+// SetTimeout sets how long a check waits for the event loop, DEFAULT_TIMEOUT by default. The timeout only
+// matters if the event loop hangs, successful checks never wait for it.
+//
+// Parameters:
+//   - `timeout` : Maximum time to wait for the event loop
+func (e *Expect) SetTimeout(timeout time.Duration) {
+	e.timeout = timeout
+}
+
+// Send types the input, see Terminal.Send.
+//
+// Parameters:
+//   - `input` : Input that is typed, e.g. "help\r"
+func (e *Expect) Send(input string) {
+	e.terminal.Send(input)
+}
+
+// SendKeys presses the keys, see Terminal.SendKeys. An unknown key marks the test as failed.
+//
+// Parameters:
+//   - `names` : Names of the keys, e.g. "up", "ctrl+c" or "enter"
+func (e *Expect) SendKeys(names ...string) {
+	e.tb.Helper()
+	if err := e.terminal.SendKeys(names...); err != nil {
+		e.tb.Errorf("keys could not be sent! error: %v", err)
+	}
+}
+
+// Expect checks that the output that followed the last match matches the regular expression. Escape sequences
+// are removed from the output before it is matched.
+//
+// Parameters:
+//   - `pattern` : Regular expression
+//
+// Returns:
+//   - `[]string` : The match followed by the submatches, nil if the output does not match
+func (e *Expect) Expect(pattern string) []string {
+	e.tb.Helper()
+	expression, err := regexp.Compile(pattern)
+	if err != nil {
+		e.tb.Errorf("pattern %v is invalid! error: %v", pattern, err)
+		return nil
+	}
+	if !e.waitIdle() {
+		return nil
+	}
+	output := escapeSequence.ReplaceAllString(e.terminal.Output(), "")[e.outputOffset:]
+	location := expression.FindStringSubmatchIndex(output)
+	if location == nil {
+		e.tb.Errorf("output does not match %q, output:\n%q", pattern, output)
+		return nil
+	}
+	submatches := make([]string, 0, len(location)/2)
+	for i := 0; i < len(location); i += 2 {
+		if location[i] < 0 {
+			submatches = append(submatches, "")
+			continue
+		}
+		submatches = append(submatches, output[location[i]:location[i+1]])
+	}
+	e.outputOffset += location[1]
+	return submatches
+}
+
+// ExpectEvent checks that an event with the name was recorded in the event history after the last matched event.
+//
+// Parameters:
+//   - `eventName` : Name of the event
+//
+// Returns:
+//   - `bool` : True if the event was recorded
+func (e *Expect) ExpectEvent(eventName string) bool {
+	e.tb.Helper()
+	if !e.waitIdle() {
+		return false
+	}
+	eventHistory := e.terminal.consoleApp.EventHistory()
+	var eventNames []string
+	for i := e.historyOffset; i < eventHistory.Len(); i++ {
+		entry, err := eventHistory.RetrieveEventEntryByIndex(i)
+		if err != nil {
+			break
+		}
+		if entry.EventName == eventName {
+			e.historyOffset = i + 1
+			return true
+		}
+		eventNames = append(eventNames, entry.EventName)
+	}
+	e.tb.Errorf("event %v was not recorded, recorded events: [%v]", eventName, strings.Join(eventNames, ", "))
+	return false
+}
+
+// ExpectTerminated checks that the event loop concluded. It waits while the event loop handles the keys that
+// were sent, but fails as soon as the event loop waits for further keys.
+//
+// Returns:
+//   - `bool` : True if the event loop concluded
+func (e *Expect) ExpectTerminated() bool {
+	e.tb.Helper()
+	if !e.waitIdle() {
+		return false
+	}
+	if !e.terminal.Concluded() {
+		e.tb.Errorf("event loop did not conclude, it waits for further keys")
+		return false
+	}
+	return true
+}
+
+// waitIdle waits until the event loop handled all keys, a timeout marks the test as failed.
+//
+// Returns:
+//   - `bool` : True if the event loop became idle
+func (e *Expect) waitIdle() bool {
+	e.tb.Helper()
+	if err := e.terminal.WaitIdle(e.timeout); err != nil {
+		e.tb.Errorf("%v", err)
+		return false
+	}
+	return true
+}
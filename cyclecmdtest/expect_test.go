@@ -0,0 +1,66 @@
+//go:build unit_test
+
+package cyclecmdtest_test
+
+import (
+	"testing"
+
+	"github.com/RaphSku/cyclecmd"
+	"github.com/RaphSku/cyclecmd/cyclecmdtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpect(t *testing.T) {
+	t.Parallel()
+
+	terminal, _ := setupTerminal(t, 40, 8)
+	expect := cyclecmdtest.NewExpect(t, terminal)
+	err := terminal.Start()
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"Version: 0.1.0"}, expect.Expect(`Version: \S+`))
+	expect.Send("greet Ada\r")
+	assert.True(t, expect.ExpectEvent("Insert"))
+	assert.True(t, expect.ExpectEvent("Submit"))
+	assert.True(t, expect.ExpectEvent(cyclecmd.SUBMITTED_LINE_EVENT_NAME))
+	assert.Equal(t, []string{"Hello [Ada]!", "Ada"}, expect.Expect(`Hello \[(\w+)\]!`))
+
+	expect.SendKeys("h", "e", "l", "p", "enter")
+	assert.NotNil(t, expect.Expect(`help\s+Lists all available commands`))
+
+	terminal.Close()
+	assert.True(t, expect.ExpectTerminated())
+	reason, err := terminal.ExitReason()
+	assert.NoError(t, err)
+	assert.Equal(t, cyclecmd.EXIT_END_OF_INPUT, reason)
+}
+
+func TestExpectFailures(t *testing.T) {
+	t.Parallel()
+
+	terminal, _ := setupTerminal(t, 40, 8)
+	err := terminal.Start()
+	assert.NoError(t, err)
+	defer terminal.Close()
+
+	// The failures are counted instead of failing this test
+	recorder := &failureRecorder{TB: t}
+	expect := cyclecmdtest.NewExpect(recorder, terminal)
+	expect.Send("greet\r")
+	assert.Nil(t, expect.Expect(`Goodbye`))
+	assert.False(t, expect.ExpectEvent("Backspace"))
+	assert.False(t, expect.ExpectTerminated())
+	assert.Nil(t, expect.Expect(`(`))
+	expect.SendKeys("unknown key")
+	assert.Equal(t, 5, recorder.failures)
+}
+
+// failureRecorder counts failures instead of failing the test.
+type failureRecorder struct {
+	testing.TB
+	failures int
+}
+
+func (fr *failureRecorder) Errorf(format string, args ...any) {
+	fr.failures += 1
+}
@@ -0,0 +1,46 @@
+package cyclecmdtest
+
+import (
+	"strings"
+
+	"github.com/RaphSku/cyclecmd"
+)
+
+// KeyBytes returns the bytes a terminal in raw mode sends for a key, see cyclecmd.KeyBytes.
+//
+// Parameters:
+//   - `name` : Name of the key, e.g. "enter", "up", "pgdown", "ctrl+c" or "alt+b"
+//
+// Returns:
+//   - `string` : Bytes of the key
+//   - `error` : Returns an error when the key is unknown
+func KeyBytes(name string) (string, error) {
+	return cyclecmd.KeyBytes(name)
+}
+
+// splitKeys splits the input into the keys a terminal would send one by one, escape sequences and multi-byte
+// characters are kept together.
+//
+// Parameters:
+//   - `input` : Input that is typed
+//
+// Returns:
+//   - `[]string` : Bytes of every key
+func splitKeys(input string) []string {
+	var splitInput []string
+	for len(input) > 0 {
+		length := len(string([]rune(input)[0]))
+		if strings.HasPrefix(input, "\x1b[") || strings.HasPrefix(input, "\x1bO") {
+			length = len(input)
+			for i := 2; i < len(input); i++ {
+				if input[i] >= 0x40 && input[i] <= 0x7e {
+					length = i + 1
+					break
+				}
+			}
+		}
+		splitInput = append(splitInput, input[:length])
+		input = input[length:]
+	}
+	return splitInput
+}
@@ -0,0 +1,198 @@
+package cyclecmdtest
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// PTY is a real pseudo-terminal pair that is allocated via /dev/ptmx. The console application runs on the
+// slave side like it would in a terminal emulator, while the test drives it from the master side. In contrast
+// to the in-memory Terminal, the kernel interprets the terminal attributes, so that a PTY verifies that raw mode
+// is entered and that the attributes are restored.
+type PTY struct {
+	// Master is the side of the terminal emulator, input is written to it and the output is read from it
+	Master *os.File
+	// Slave is the side of the console application, it should be used as its input and output
+	Slave *os.File
+
+	mutex  sync.Mutex
+	output bytes.Buffer
+	// changed is closed and replaced whenever output was read from the master
+	changed chan struct{}
+	// done is closed once the master can no longer be read
+	done chan struct{}
+}
+
+// OpenPTY allocates a pseudo-terminal pair and starts reading the output from the master side, so that the
+// console application never blocks on a full terminal buffer.
+//
+// Returns:
+//   - `*PTY` : Returns an instance of PTY
+//   - `error` : Returns an error when the pseudo-terminal pair could not be allocated
+func OpenPTY() (*PTY, error) {
+	master, err := os.OpenFile("/dev/ptmx", os.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		return nil, fmt.Errorf("pseudo-terminal master could not be opened! error: %w", err)
+	}
+	if err := unix.IoctlSetPointerInt(int(master.Fd()), unix.TIOCSPTLCK, 0); err != nil {
+		master.Close()
+		return nil, fmt.Errorf("pseudo-terminal slave could not be unlocked! error: %w", err)
+	}
+	number, err := unix.IoctlGetInt(int(master.Fd()), unix.TIOCGPTN)
+	if err != nil {
+		master.Close()
+		return nil, fmt.Errorf("pseudo-terminal slave could not be determined! error: %w", err)
+	}
+	slave, err := os.OpenFile(fmt.Sprintf("/dev/pts/%d", number), os.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		master.Close()
+		return nil, fmt.Errorf("pseudo-terminal slave could not be opened! error: %w", err)
+	}
+
+	pty := &PTY{
+		Master:  master,
+		Slave:   slave,
+		changed: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go pty.readOutput()
+	return pty, nil
+}
+
+// readOutput collects the output of the master side until it can no longer be read.
+func (p *PTY) readOutput() {
+	defer close(p.done)
+	buffer := make([]byte, 4096)
+	for {
+		n, err := p.Master.Read(buffer)
+		if n > 0 {
+			p.mutex.Lock()
+			p.output.Write(buffer[:n])
+			close(p.changed)
+			p.changed = make(chan struct{})
+			p.mutex.Unlock()
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// Send writes the input to the master side, the console application reads it from the slave side.
+//
+// Parameters:
+//   - `input` : Input that is typed, e.g. "help\r"
+//
+// Returns:
+//   - `error` : Returns an error when the input could not be written
+func (p *PTY) Send(input string) error {
+	if _, err := p.Master.WriteString(input); err != nil {
+		return fmt.Errorf("input could not be sent! error: %w", err)
+	}
+	return nil
+}
+
+// Output returns everything the console application wrote so far, including escape sequences.
+//
+// Returns:
+//   - `string` : The output
+func (p *PTY) Output() string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.output.String()
+}
+
+// WaitFor waits until the output contains the text.
+//
+// Parameters:
+//   - `text` : Text that is waited for
+//   - `timeout` : Maximum time to wait
+//
+// Returns:
+//   - `error` : Returns an error when the text did not appear in time
+func (p *PTY) WaitFor(text string, timeout time.Duration) error {
+	deadline := time.After(timeout)
+	for {
+		p.mutex.Lock()
+		found := strings.Contains(p.output.String(), text)
+		changed := p.changed
+		p.mutex.Unlock()
+		if found {
+			return nil
+		}
+		select {
+		case <-changed:
+		case <-p.done:
+			return fmt.Errorf("output does not contain %q, the pseudo-terminal was closed", text)
+		case <-deadline:
+			return fmt.Errorf("output does not contain %q after %v, output:\n%q", text, timeout, p.Output())
+		}
+	}
+}
+
+// SetSize sets the size of the pseudo-terminal, the console application receives SIGWINCH if it is the
+// foreground process of the terminal.
+//
+// Parameters:
+//   - `width` : Number of columns
+//   - `height` : Number of rows
+//
+// Returns:
+//   - `error` : Returns an error when the size could not be set
+func (p *PTY) SetSize(width int, height int) error {
+	windowSize := &unix.Winsize{Col: uint16(width), Row: uint16(height)}
+	if err := unix.IoctlSetWinsize(int(p.Master.Fd()), unix.TIOCSWINSZ, windowSize); err != nil {
+		return fmt.Errorf("size could not be set! error: %w", err)
+	}
+	return nil
+}
+
+// Attributes returns the current terminal attributes of the slave side, attributes can be compared to check
+// that they were restored exactly.
+//
+// Returns:
+//   - `unix.Termios` : The terminal attributes
+//   - `error` : Returns an error when the attributes could not be read
+func (p *PTY) Attributes() (unix.Termios, error) {
+	termios, err := unix.IoctlGetTermios(int(p.Slave.Fd()), unix.TCGETS)
+	if err != nil {
+		return unix.Termios{}, fmt.Errorf("terminal attributes could not be read! error: %w", err)
+	}
+	return *termios, nil
+}
+
+// IsRaw reports whether the slave side is in raw mode, i.e. input is neither echoed nor collected into lines and
+// control characters do not raise signals.
+//
+// Returns:
+//   - `bool` : True if the slave side is in raw mode
+//   - `error` : Returns an error when the attributes could not be read
+func (p *PTY) IsRaw() (bool, error) {
+	termios, err := p.Attributes()
+	if err != nil {
+		return false, err
+	}
+	return termios.Lflag&(unix.ECHO|unix.ICANON|unix.ISIG) == 0, nil
+}
+
+// Close closes both sides of the pseudo-terminal pair.
+//
+// Returns:
+//   - `error` : Returns an error when a side could not be closed
+func (p *PTY) Close() error {
+	slaveErr := p.Slave.Close()
+	masterErr := p.Master.Close()
+	if slaveErr != nil {
+		return fmt.Errorf("pseudo-terminal slave could not be closed! error: %w", slaveErr)
+	}
+	if masterErr != nil {
+		return fmt.Errorf("pseudo-terminal master could not be closed! error: %w", masterErr)
+	}
+	return nil
+}
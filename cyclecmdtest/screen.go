@@ -0,0 +1,485 @@
+package cyclecmdtest
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/RaphSku/cyclecmd/style"
+)
+
+// tabWidth is the distance between two tab stops.
+const tabWidth = 8
+
+// continuation marks the second cell of a wide character.
+const continuation rune = 0
+
+// Screen interprets the output of a console application like a terminal does and keeps the resulting grid of
+// characters. It understands the control characters and escape sequences that cyclecmd writes, i.e. cursor
+// movement, erasing, inserting and deleting lines, scroll regions and the alternate screen buffer. Styles are
+// ignored. Screen is not safe for concurrent use, see Terminal.
+type Screen struct {
+	width  int
+	height int
+	cells  [][]rune
+	// cursorX and cursorY are the position of the cursor, cursorX equals the width while a wrap is pending
+	cursorX int
+	cursorY int
+	// savedX and savedY are the position saved by ESC 7
+	savedX int
+	savedY int
+	// scrollTop and scrollBottom are the first and the last row of the scroll region
+	scrollTop    int
+	scrollBottom int
+	// primaryCells, primaryX and primaryY keep the primary screen while the alternate screen is active
+	primaryCells [][]rune
+	primaryX     int
+	primaryY     int
+	// pending contains an incomplete escape sequence or character of the previous write
+	pending []byte
+}
+
+// NewScreen initialises a blank screen.
+//
+// Parameters:
+//   - `width` : Width in columns
+//   - `height` : Height in rows
+//
+// Returns:
+//   - `*Screen` : Returns an instance of Screen
+func NewScreen(width int, height int) *Screen {
+	s := &Screen{width: max(1, width), height: max(1, height)}
+	s.cells = s.blankCells()
+	s.scrollBottom = s.height - 1
+	return s
+}
+
+// Write interprets the output, escape sequences and characters might be split across writes.
+//
+// Parameters:
+//   - `p` : Output of the console application
+//
+// Returns:
+//   - `int` : Always the length of the output
+//   - `error` : Always nil
+func (s *Screen) Write(p []byte) (int, error) {
+	data := append(s.pending, p...)
+	s.pending = nil
+	for len(data) > 0 {
+		consumed := s.interpret(data)
+		if consumed == 0 {
+			s.pending = append([]byte(nil), data...)
+			break
+		}
+		data = data[consumed:]
+	}
+	return len(p), nil
+}
+
+// Resize changes the size of the screen, the content is kept at the upper left corner and the scroll region is
+// reset.
+//
+// Parameters:
+//   - `width` : Width in columns
+//   - `height` : Height in rows
+func (s *Screen) Resize(width int, height int) {
+	resize := func(cells [][]rune) [][]rune {
+		resized := make([][]rune, max(1, height))
+		for y := range resized {
+			resized[y] = []rune(strings.Repeat(" ", max(1, width)))
+			if y < len(cells) {
+				copy(resized[y], cells[y])
+			}
+		}
+		return resized
+	}
+	s.cells = resize(s.cells)
+	if s.primaryCells != nil {
+		s.primaryCells = resize(s.primaryCells)
+	}
+	s.width, s.height = max(1, width), max(1, height)
+	s.cursorX, s.cursorY = min(s.cursorX, s.width-1), min(s.cursorY, s.height-1)
+	s.scrollTop, s.scrollBottom = 0, s.height-1
+}
+
+// Size returns the size of the screen.
+//
+// Returns:
+//   - `int` : Width in columns
+//   - `int` : Height in rows
+func (s *Screen) Size() (int, int) {
+	return s.width, s.height
+}
+
+// Cursor returns the position of the cursor.
+//
+// Returns:
+//   - `int` : Column of the cursor, starting at 0
+//   - `int` : Row of the cursor, starting at 0
+func (s *Screen) Cursor() (int, int) {
+	return min(s.cursorX, s.width-1), s.cursorY
+}
+
+// Line returns a row of the screen without trailing spaces.
+//
+// Parameters:
+//   - `row` : Row starting at 0
+//
+// Returns:
+//   - `string` : Content of the row, empty if the row is outside of the screen
+func (s *Screen) Line(row int) string {
+	if row < 0 || row >= s.height {
+		return ""
+	}
+	var line strings.Builder
+	for _, cell := range s.cells[row] {
+		if cell != continuation {
+			line.WriteRune(cell)
+		}
+	}
+	return strings.TrimRight(line.String(), " ")
+}
+
+// Lines returns all rows of the screen without trailing spaces.
+//
+// Returns:
+//   - `[]string` : Content of every row
+func (s *Screen) Lines() []string {
+	lines := make([]string, s.height)
+	for row := range lines {
+		lines[row] = s.Line(row)
+	}
+	return lines
+}
+
+// String returns the content of the screen, rows are separated by newlines and trailing empty rows are omitted.
+//
+// Returns:
+//   - `string` : Content of the screen
+func (s *Screen) String() string {
+	lines := s.Lines()
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// blankCells returns a grid of blank cells with the size of the screen.
+//
+// Returns:
+//   - `[][]rune` : Blank cells
+func (s *Screen) blankCells() [][]rune {
+	cells := make([][]rune, s.height)
+	for y := range cells {
+		cells[y] = s.blankRow()
+	}
+	return cells
+}
+
+// blankRow returns a row of blank cells.
+//
+// Returns:
+//   - `[]rune` : Blank cells
+func (s *Screen) blankRow() []rune {
+	return []rune(strings.Repeat(" ", s.width))
+}
+
+// interpret interprets a control character, an escape sequence or a character at the start of the data.
+//
+// Parameters:
+//   - `data` : Output that was not interpreted yet
+//
+// Returns:
+//   - `int` : Number of bytes that were interpreted, 0 if the data ends within an escape sequence or character
+func (s *Screen) interpret(data []byte) int {
+	switch data[0] {
+	case '\x1b':
+		return s.interpretEscape(data)
+	case '\r':
+		s.cursorX = 0
+	case '\n', '\v', '\f':
+		s.lineFeed()
+	case '\b':
+		s.cursorX = max(0, min(s.cursorX, s.width-1)-1)
+	case '\t':
+		s.cursorX = min(s.width-1, (s.cursorX/tabWidth+1)*tabWidth)
+	default:
+		if data[0] < 0x20 || data[0] == 0x7f {
+			return 1
+		}
+		if !utf8.FullRune(data) {
+			return 0
+		}
+		character, size := utf8.DecodeRune(data)
+		s.print(character)
+		return size
+	}
+	return 1
+}
+
+// interpretEscape interprets an escape sequence at the start of the data.
+//
+// Parameters:
+//   - `data` : Output that starts with ESC
+//
+// Returns:
+//   - `int` : Length of the escape sequence, 0 if it is incomplete
+func (s *Screen) interpretEscape(data []byte) int {
+	if len(data) < 2 {
+		return 0
+	}
+	switch data[1] {
+	case '[':
+		for i := 2; i < len(data); i++ {
+			if data[i] >= 0x40 && data[i] <= 0x7e {
+				s.interpretCSI(string(data[2:i]), data[i])
+				return i + 1
+			}
+		}
+		return 0
+	case ']':
+		// Operating system commands end with BEL or ESC \
+		for i := 2; i < len(data); i++ {
+			if data[i] == '\a' {
+				return i + 1
+			}
+			if data[i] == '\x1b' && i+1 < len(data) && data[i+1] == '\\' {
+				return i + 2
+			}
+		}
+		return 0
+	case '7':
+		s.savedX, s.savedY = s.cursorX, s.cursorY
+	case '8':
+		s.cursorX, s.cursorY = s.savedX, s.savedY
+	case 'D':
+		s.lineFeed()
+	case 'E':
+		s.cursorX = 0
+		s.lineFeed()
+	case 'M':
+		if s.cursorY == s.scrollTop {
+			s.scrollDown(s.scrollTop, 1)
+		} else {
+			s.cursorY = max(0, s.cursorY-1)
+		}
+	case 'c':
+		*s = *NewScreen(s.width, s.height)
+	case '(', ')':
+		// Character set designations are ignored
+		if len(data) < 3 {
+			return 0
+		}
+		return 3
+	}
+	return 2
+}
+
+// interpretCSI interprets a control sequence.
+//
+// Parameters:
+//   - `parameters` : Parameters between ESC [ and the final byte
+//   - `final` : Final byte of the control sequence
+func (s *Screen) interpretCSI(parameters string, final byte) {
+	private := strings.HasPrefix(parameters, "?")
+	parameters = strings.TrimPrefix(parameters, "?")
+	var values []int
+	for _, parameter := range strings.Split(parameters, ";") {
+		value, err := strconv.Atoi(parameter)
+		if err != nil {
+			value = 0
+		}
+		values = append(values, value)
+	}
+	// value returns the parameter at the index or the default if it is missing or 0
+	value := func(index int, defaultValue int) int {
+		if index < len(values) && values[index] > 0 {
+			return values[index]
+		}
+		return defaultValue
+	}
+	x := min(s.cursorX, s.width-1)
+
+	switch final {
+	case 'A':
+		s.cursorX, s.cursorY = x, max(0, s.cursorY-value(0, 1))
+	case 'B':
+		s.cursorX, s.cursorY = x, min(s.height-1, s.cursorY+value(0, 1))
+	case 'C':
+		s.cursorX = min(s.width-1, x+value(0, 1))
+	case 'D':
+		s.cursorX = max(0, x-value(0, 1))
+	case 'E':
+		s.cursorX, s.cursorY = 0, min(s.height-1, s.cursorY+value(0, 1))
+	case 'F':
+		s.cursorX, s.cursorY = 0, max(0, s.cursorY-value(0, 1))
+	case 'G':
+		s.cursorX = min(s.width-1, value(0, 1)-1)
+	case 'd':
+		s.cursorY = min(s.height-1, value(0, 1)-1)
+	case 'H', 'f':
+		s.cursorY = min(s.height-1, value(0, 1)-1)
+		s.cursorX = min(s.width-1, value(1, 1)-1)
+	case 'J':
+		s.cursorX = x
+		switch value(0, 0) {
+		case 0:
+			s.eraseLine(s.cursorY, x, s.width)
+			for y := s.cursorY + 1; y < s.height; y++ {
+				s.cells[y] = s.blankRow()
+			}
+		case 1:
+			s.eraseLine(s.cursorY, 0, x+1)
+			for y := 0; y < s.cursorY; y++ {
+				s.cells[y] = s.blankRow()
+			}
+		default:
+			s.cells = s.blankCells()
+		}
+	case 'K':
+		s.cursorX = x
+		switch value(0, 0) {
+		case 0:
+			s.eraseLine(s.cursorY, x, s.width)
+		case 1:
+			s.eraseLine(s.cursorY, 0, x+1)
+		default:
+			s.eraseLine(s.cursorY, 0, s.width)
+		}
+	case 'L':
+		if s.cursorY >= s.scrollTop && s.cursorY <= s.scrollBottom {
+			s.scrollDown(s.cursorY, value(0, 1))
+		}
+	case 'M':
+		if s.cursorY >= s.scrollTop && s.cursorY <= s.scrollBottom {
+			s.scrollUp(s.cursorY, value(0, 1))
+		}
+	case '@':
+		row := s.cells[s.cursorY]
+		count := min(value(0, 1), s.width-x)
+		copy(row[x+count:], row[x:])
+		for i := x; i < x+count; i++ {
+			row[i] = ' '
+		}
+	case 'P':
+		row := s.cells[s.cursorY]
+		count := min(value(0, 1), s.width-x)
+		copy(row[x:], row[x+count:])
+		for i := s.width - count; i < s.width; i++ {
+			row[i] = ' '
+		}
+	case 'X':
+		s.eraseLine(s.cursorY, x, x+value(0, 1))
+	case 'r':
+		top, bottom := value(0, 1)-1, value(1, s.height)-1
+		if top < bottom && bottom < s.height {
+			s.scrollTop, s.scrollBottom = top, bottom
+		}
+		s.cursorX, s.cursorY = 0, 0
+	case 's':
+		s.savedX, s.savedY = s.cursorX, s.cursorY
+	case 'u':
+		s.cursorX, s.cursorY = s.savedX, s.savedY
+	case 'h', 'l':
+		if private {
+			for _, mode := range values {
+				if mode == 1049 || mode == 1047 || mode == 47 {
+					s.switchScreen(final == 'h')
+				}
+			}
+		}
+	}
+}
+
+// print draws the character at the cursor and advances the cursor, wide characters occupy two cells.
+//
+// Parameters:
+//   - `character` : Character that is drawn
+func (s *Screen) print(character rune) {
+	width := style.RuneWidth(character)
+	if width == 0 {
+		return
+	}
+	if s.cursorX+width > s.width {
+		s.cursorX = 0
+		s.lineFeed()
+	}
+	row := s.cells[s.cursorY]
+	// Wide characters that are partly overwritten are erased entirely
+	if row[s.cursorX] == continuation && s.cursorX > 0 {
+		row[s.cursorX-1] = ' '
+	}
+	if end := s.cursorX + width; end < s.width && row[end] == continuation {
+		row[end] = ' '
+	}
+	row[s.cursorX] = character
+	if width == 2 {
+		row[s.cursorX+1] = continuation
+	}
+	s.cursorX += width
+}
+
+// lineFeed moves the cursor down, the scroll region scrolls if the cursor is in its last row.
+func (s *Screen) lineFeed() {
+	s.cursorX = min(s.cursorX, s.width-1)
+	if s.cursorY == s.scrollBottom {
+		s.scrollUp(s.scrollTop, 1)
+		return
+	}
+	s.cursorY = min(s.height-1, s.cursorY+1)
+}
+
+// scrollUp removes rows at the row, the rows below move up and blank rows appear at the bottom of the scroll
+// region.
+//
+// Parameters:
+//   - `row` : First row that is removed
+//   - `count` : Number of rows
+func (s *Screen) scrollUp(row int, count int) {
+	count = min(count, s.scrollBottom-row+1)
+	copy(s.cells[row:s.scrollBottom+1], s.cells[row+count:s.scrollBottom+1])
+	for y := s.scrollBottom - count + 1; y <= s.scrollBottom; y++ {
+		s.cells[y] = s.blankRow()
+	}
+}
+
+// scrollDown inserts blank rows at the row, the rows below move down and rows at the bottom of the scroll
+// region disappear.
+//
+// Parameters:
+//   - `row` : Row where the blank rows are inserted
+//   - `count` : Number of rows
+func (s *Screen) scrollDown(row int, count int) {
+	count = min(count, s.scrollBottom-row+1)
+	copy(s.cells[row+count:s.scrollBottom+1], s.cells[row:s.scrollBottom+1-count])
+	for y := row; y < row+count; y++ {
+		s.cells[y] = s.blankRow()
+	}
+}
+
+// eraseLine makes the cells of the row between the columns blank.
+//
+// Parameters:
+//   - `row` : Row of the cells
+//   - `from` : First column
+//   - `to` : Column after the last column
+func (s *Screen) eraseLine(row int, from int, to int) {
+	for x := max(0, from); x < min(to, s.width); x++ {
+		s.cells[row][x] = ' '
+	}
+}
+
+// switchScreen switches between the primary and the alternate screen buffer, the alternate screen buffer is
+// blank whenever it is entered.
+//
+// Parameters:
+//   - `alternate` : True to switch to the alternate screen buffer
+func (s *Screen) switchScreen(alternate bool) {
+	if alternate && s.primaryCells == nil {
+		s.primaryCells, s.primaryX, s.primaryY = s.cells, s.cursorX, s.cursorY
+		s.cells = s.blankCells()
+	} else if !alternate && s.primaryCells != nil {
+		s.cells, s.cursorX, s.cursorY = s.primaryCells, s.primaryX, s.primaryY
+		s.primaryCells = nil
+	}
+}
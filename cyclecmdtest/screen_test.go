@@ -0,0 +1,73 @@
+//go:build unit_test
+
+package cyclecmdtest_test
+
+import (
+	"testing"
+
+	"github.com/RaphSku/cyclecmd/cyclecmdtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScreenText(t *testing.T) {
+	t.Parallel()
+
+	screen := cyclecmdtest.NewScreen(5, 3)
+	screen.Write([]byte("abcdefg\r\nxy\bz"))
+	assert.Equal(t, "abcde\nfg\nxz", screen.String())
+	x, y := screen.Cursor()
+	assert.Equal(t, 2, x)
+	assert.Equal(t, 2, y)
+
+	// The screen scrolls once the cursor moves below the last row
+	screen.Write([]byte("\r\n1"))
+	assert.Equal(t, []string{"fg", "xz", "1"}, screen.Lines())
+
+	// Wide characters occupy two cells and escape sequences might be split across writes
+	screen = cyclecmdtest.NewScreen(5, 2)
+	screen.Write([]byte("日本\x1b"))
+	screen.Write([]byte("[1;1H\xe2"))
+	screen.Write([]byte("\x80\xa6"))
+	assert.Equal(t, "… 本", screen.Line(0))
+}
+
+func TestScreenEscapeSequences(t *testing.T) {
+	t.Parallel()
+
+	screen := cyclecmdtest.NewScreen(6, 4)
+	screen.Write([]byte("one\r\ntwo\r\nthree\x1b[2;2H\x1b[K"))
+	assert.Equal(t, "one\nt\nthree", screen.String())
+
+	// Inserting and deleting lines moves the rows below
+	screen.Write([]byte("\x1b[1L"))
+	assert.Equal(t, "one\n\nt\nthree", screen.String())
+	screen.Write([]byte("\x1b[1;1H\x1b[2M"))
+	assert.Equal(t, "t\nthree", screen.String())
+
+	// Only the scroll region scrolls, the last row is kept
+	screen.Write([]byte("\x1b[4;1Hstatus\x1b[1;3r\x1b[3;1Ha\r\nb\r\nc"))
+	assert.Equal(t, []string{"a", "b", "c", "status"}, screen.Lines())
+
+	// Saving and restoring the cursor, erasing the screen below the cursor
+	screen.Write([]byte("\x1b[r\x1b7\x1b[2;1H\x1b[J\x1b8X"))
+	assert.Equal(t, "X", screen.String())
+}
+
+func TestScreenAlternateBuffer(t *testing.T) {
+	t.Parallel()
+
+	screen := cyclecmdtest.NewScreen(10, 3)
+	screen.Write([]byte("prompt> "))
+	screen.Write([]byte("\x1b[?1049h\x1b[2J\x1b[2;3Hview"))
+	assert.Equal(t, "\n  view", screen.String())
+	screen.Write([]byte("\x1b[?1049l"))
+	assert.Equal(t, "prompt>", screen.String())
+	x, _ := screen.Cursor()
+	assert.Equal(t, 8, x)
+
+	screen.Resize(4, 2)
+	assert.Equal(t, "prom", screen.String())
+	width, height := screen.Size()
+	assert.Equal(t, 4, width)
+	assert.Equal(t, 2, height)
+}
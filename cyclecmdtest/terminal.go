@@ -0,0 +1,436 @@
+// Package cyclecmdtest runs console applications against an in-memory terminal, so that they can be tested the
+// way a user interacts with them: keys are sent by name, the output is interpreted into a grid of characters and
+// the rendered screen can be compared to the expected text or to golden files. On Linux, OpenPTY allocates a real
+// pseudo-terminal pair for integration tests that depend on the terminal attributes.
+package cyclecmdtest
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/RaphSku/cyclecmd"
+)
+
+// DEFAULT_TIMEOUT is a timeout for waiting on the console application that is long enough for slow CI machines.
+const DEFAULT_TIMEOUT = 5 * time.Second
+
+// update makes AssertGolden write the golden files instead of comparing them, e.g. `go test ./... -update`.
+var update = flag.Bool("update", false, "update the golden files of cyclecmdtest")
+
+// Terminal is an in-memory terminal that a console application runs against. The input of the console
+// application receives the keys that are sent to the terminal one by one, just like a terminal in raw mode
+// delivers them, and its output is interpreted by a Screen. All methods are safe for concurrent use.
+type Terminal struct {
+	consoleApp *cyclecmd.ConsoleApp
+	input      *keyInput
+
+	// mutex guards screen, output and changed
+	mutex  sync.Mutex
+	screen *Screen
+	output strings.Builder
+	// changed is closed and replaced whenever the console application writes output
+	changed chan struct{}
+	// done is closed once the event loop concluded
+	done    chan struct{}
+	started bool
+	// exitReason and exitError are recorded by an exit hook
+	exitReason cyclecmd.ExitReason
+	exitError  error
+}
+
+// NewTerminal initialises a terminal and attaches the console application to it, i.e. the input, the output
+// and the size of the console application are set and an exit hook records why the event loop concluded.
+//
+// Parameters:
+//   - `consoleApp` : Console application that runs in the terminal
+//   - `width` : Width of the terminal in columns
+//   - `height` : Height of the terminal in rows
+//
+// Returns:
+//   - `*Terminal` : Returns an instance of Terminal
+func NewTerminal(consoleApp *cyclecmd.ConsoleApp, width int, height int) *Terminal {
+	t := &Terminal{
+		consoleApp: consoleApp,
+		input:      newKeyInput(),
+		screen:     NewScreen(width, height),
+		changed:    make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	consoleApp.SetInput(t.input)
+	consoleApp.SetOutput(t)
+	consoleApp.Resize(width, height)
+	consoleApp.OnExit(func(reason cyclecmd.ExitReason, err error) {
+		t.mutex.Lock()
+		defer t.mutex.Unlock()
+		t.exitReason, t.exitError = reason, err
+	})
+	return t
+}
+
+// Start starts the event loop of the console application in a separate goroutine.
+//
+// Returns:
+//   - `error` : Returns an error when the terminal was already started
+func (t *Terminal) Start() error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.started {
+		return fmt.Errorf("terminal was already started")
+	}
+	t.started = true
+	go func() {
+		defer close(t.done)
+		t.consoleApp.Start()
+	}()
+	return nil
+}
+
+// Write interprets the output of the console application, it implements io.Writer.
+//
+// Parameters:
+//   - `p` : Output of the console application
+//
+// Returns:
+//   - `int` : Always the length of the output
+//   - `error` : Always nil
+func (t *Terminal) Write(p []byte) (int, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.screen.Write(p)
+	t.output.Write(p)
+	close(t.changed)
+	t.changed = make(chan struct{})
+	return len(p), nil
+}
+
+// Send types the input, every character and every escape sequence is read as a separate key.
+//
+// Parameters:
+//   - `input` : Input that is typed, e.g. "help\r"
+func (t *Terminal) Send(input string) {
+	t.input.send(splitKeys(input)...)
+}
+
+// SendKeys presses the keys, see KeyBytes for the names of the keys.
+//
+// Parameters:
+//   - `names` : Names of the keys, e.g. "up", "ctrl+c" or "enter"
+//
+// Returns:
+//   - `error` : Returns an error when a key is unknown, no key is pressed in that case
+func (t *Terminal) SendKeys(names ...string) error {
+	var keyBytes []string
+	for _, name := range names {
+		key, err := KeyBytes(name)
+		if err != nil {
+			return err
+		}
+		keyBytes = append(keyBytes, key)
+	}
+	t.input.send(keyBytes...)
+	return nil
+}
+
+// Resize changes the size of the terminal and notifies the console application.
+//
+// Parameters:
+//   - `width` : Width in columns
+//   - `height` : Height in rows
+func (t *Terminal) Resize(width int, height int) {
+	t.mutex.Lock()
+	t.screen.Resize(width, height)
+	t.mutex.Unlock()
+	t.consoleApp.Resize(width, height)
+}
+
+// Close closes the input, the event loop concludes once it read all keys that were sent.
+func (t *Terminal) Close() {
+	t.input.close()
+}
+
+// Wait waits until the event loop concluded.
+//
+// Parameters:
+//   - `timeout` : Maximum time to wait
+//
+// Returns:
+//   - `error` : Returns an error when the event loop did not conclude within the timeout
+func (t *Terminal) Wait(timeout time.Duration) error {
+	select {
+	case <-t.done:
+		return nil
+	case <-time.After(timeout):
+		return fmt.Errorf("event loop did not conclude within %v", timeout)
+	}
+}
+
+// WaitFor waits until the text appears on the screen.
+//
+// Parameters:
+//   - `text` : Text that is expected on the screen, it might span several rows that are separated by newlines
+//   - `timeout` : Maximum time to wait
+//
+// Returns:
+//   - `error` : Returns an error with the content of the screen when the text did not appear within the timeout
+func (t *Terminal) WaitFor(text string, timeout time.Duration) error {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		t.mutex.Lock()
+		screen, changed := t.screen.String(), t.changed
+		t.mutex.Unlock()
+		if strings.Contains(screen, text) {
+			return nil
+		}
+		select {
+		case <-changed:
+		case <-timer.C:
+			return fmt.Errorf("text %q did not appear within %v, screen:\n%s", text, timeout, screen)
+		}
+	}
+}
+
+// WaitIdle waits until the event loop handled all keys that were sent and waits for the next key, or until the
+// event loop concluded. Output that is posted from other goroutines might still follow, see WaitFor.
+//
+// Parameters:
+//   - `timeout` : Maximum time to wait
+//
+// Returns:
+//   - `error` : Returns an error when the event loop did not become idle within the timeout
+func (t *Terminal) WaitIdle(timeout time.Duration) error {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		idle, changed := t.input.idle()
+		if idle {
+			return nil
+		}
+		select {
+		case <-changed:
+		case <-t.done:
+			return nil
+		case <-timer.C:
+			return fmt.Errorf("event loop did not become idle within %v", timeout)
+		}
+	}
+}
+
+// Concluded checks whether the event loop concluded.
+//
+// Returns:
+//   - `bool` : True if the event loop concluded
+func (t *Terminal) Concluded() bool {
+	select {
+	case <-t.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// ExitReason returns why the event loop concluded.
+//
+// Returns:
+//   - `cyclecmd.ExitReason` : Reason why the event loop concluded
+//   - `error` : The error that stopped the event loop, if any
+func (t *Terminal) ExitReason() (cyclecmd.ExitReason, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.exitReason, t.exitError
+}
+
+// Screen returns the content of the screen, rows are separated by newlines, trailing spaces and trailing empty
+// rows are omitted.
+//
+// Returns:
+//   - `string` : Content of the screen
+func (t *Terminal) Screen() string {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.screen.String()
+}
+
+// Lines returns all rows of the screen without trailing spaces.
+//
+// Returns:
+//   - `[]string` : Content of every row
+func (t *Terminal) Lines() []string {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.screen.Lines()
+}
+
+// Cursor returns the position of the cursor.
+//
+// Returns:
+//   - `int` : Column of the cursor, starting at 0
+//   - `int` : Row of the cursor, starting at 0
+func (t *Terminal) Cursor() (int, int) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.screen.Cursor()
+}
+
+// Output returns everything the console application wrote, including escape sequences.
+//
+// Returns:
+//   - `string` : Raw output
+func (t *Terminal) Output() string {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.output.String()
+}
+
+// AssertScreen checks that the screen shows the expected content, see Screen. The test is marked as failed
+// otherwise.
+//
+// Parameters:
+//   - `tb` : Test that is marked as failed
+//   - `expected` : Expected content of the screen
+//
+// Returns:
+//   - `bool` : True if the screen shows the expected content
+func (t *Terminal) AssertScreen(tb testing.TB, expected string) bool {
+	tb.Helper()
+	actual := t.Screen()
+	if actual != expected {
+		tb.Errorf("screen does not show the expected content\n--- expected\n%s\n--- actual\n%s", expected, actual)
+		return false
+	}
+	return true
+}
+
+// AssertGolden checks that the screen shows the content of the golden file testdata/<name>.golden. The test
+// is marked as failed otherwise. If the test runs with the -update flag, the golden file is written instead.
+//
+// Parameters:
+//   - `tb` : Test that is marked as failed
+//   - `name` : Name of the golden file without extension
+//
+// Returns:
+//   - `bool` : True if the screen shows the content of the golden file
+func (t *Terminal) AssertGolden(tb testing.TB, name string) bool {
+	tb.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	actual := t.Screen()
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			tb.Errorf("directory of golden file %v could not be created! error: %v", path, err)
+			return false
+		}
+		if err := os.WriteFile(path, []byte(actual+"\n"), 0o644); err != nil {
+			tb.Errorf("golden file %v could not be written! error: %v", path, err)
+			return false
+		}
+		return true
+	}
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		tb.Errorf("golden file %v could not be read, run the test with -update to create it! error: %v", path, err)
+		return false
+	}
+	if actual != strings.TrimSuffix(string(expected), "\n") {
+		tb.Errorf("screen does not match golden file %v, run the test with -update to update it\n--- expected\n%s\n--- actual\n%s", path, expected, actual)
+		return false
+	}
+	return true
+}
+
+// keyInput is the input of the console application, every read returns a single key.
+type keyInput struct {
+	// mutex guards all fields
+	mutex  sync.Mutex
+	keys   []string
+	closed bool
+	// waiting is true while a read waits for keys
+	waiting bool
+	// changed is closed and replaced whenever keys are sent, the input is closed or a read starts waiting
+	changed chan struct{}
+}
+
+// newKeyInput initialises an input without keys.
+//
+// Returns:
+//   - `*keyInput` : Returns an instance of keyInput
+func newKeyInput() *keyInput {
+	return &keyInput{changed: make(chan struct{})}
+}
+
+// Read blocks until a key was sent and returns it, it returns io.EOF once the input was closed and all keys
+// were read.
+//
+// Parameters:
+//   - `p` : Buffer for the key
+//
+// Returns:
+//   - `int` : Number of bytes of the key
+//   - `error` : Returns io.EOF once the input was closed
+func (ki *keyInput) Read(p []byte) (int, error) {
+	ki.mutex.Lock()
+	defer ki.mutex.Unlock()
+	for len(ki.keys) == 0 && !ki.closed {
+		ki.waiting = true
+		ki.broadcast()
+		changed := ki.changed
+		ki.mutex.Unlock()
+		<-changed
+		ki.mutex.Lock()
+	}
+	ki.waiting = false
+	if len(ki.keys) == 0 {
+		return 0, io.EOF
+	}
+	key := ki.keys[0]
+	n := copy(p, key)
+	if n < len(key) {
+		ki.keys[0] = key[n:]
+	} else {
+		ki.keys = ki.keys[1:]
+	}
+	return n, nil
+}
+
+// send appends the keys.
+//
+// Parameters:
+//   - `keys` : Bytes of the keys
+func (ki *keyInput) send(keys ...string) {
+	ki.mutex.Lock()
+	defer ki.mutex.Unlock()
+	ki.keys = append(ki.keys, keys...)
+	ki.broadcast()
+}
+
+// close closes the input.
+func (ki *keyInput) close() {
+	ki.mutex.Lock()
+	defer ki.mutex.Unlock()
+	ki.closed = true
+	ki.broadcast()
+}
+
+// idle checks whether a read waits for keys while no keys are left. Since the event loop only reads the next
+// key once it handled the previous one, this means that it handled all keys that were sent.
+//
+// Returns:
+//   - `bool` : True if the event loop waits for the next key
+//   - `<-chan struct{}` : Channel that is closed once the state of the input changed
+func (ki *keyInput) idle() (bool, <-chan struct{}) {
+	ki.mutex.Lock()
+	defer ki.mutex.Unlock()
+	return ki.waiting && len(ki.keys) == 0 && !ki.closed, ki.changed
+}
+
+// broadcast notifies everyone who waits for a change of the input, the mutex must be locked.
+func (ki *keyInput) broadcast() {
+	close(ki.changed)
+	ki.changed = make(chan struct{})
+}
@@ -0,0 +1,152 @@
+//go:build unit_test
+
+package cyclecmdtest_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/RaphSku/cyclecmd"
+	"github.com/RaphSku/cyclecmd/cyclecmdtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupTerminal(t *testing.T, width int, height int) (*cyclecmdtest.Terminal, *cyclecmd.EventHistory) {
+	eventRegistry := cyclecmd.NewEventRegistry(cyclecmd.EventInformation{})
+	eventHistory := cyclecmd.NewEventHistory()
+	consoleApp := cyclecmd.NewConsoleApp("test", "0.1.0", "This is a test console application", eventRegistry, eventHistory)
+	commandRegistry := cyclecmd.NewCommandRegistry(consoleApp)
+	err := commandRegistry.RegisterCommand(cyclecmd.Command{
+		Name:        "greet",
+		Description: "Greets the person whose name is passed as argument",
+		Run: func(args []string) (error, *cyclecmd.ControlEvent) {
+			fmt.Fprintf(consoleApp.Output(), "Hello %v!\r\n", args)
+			return nil, nil
+		},
+	})
+	assert.NoError(t, err)
+	lineEditor := cyclecmd.NewLineEditor(consoleApp, commandRegistry.Submit)
+	err = lineEditor.RegisterEvents(eventRegistry)
+	assert.NoError(t, err)
+	consoleApp.SetLineDelimiter("> ", cyclecmd.KEY_ENTER)
+	return cyclecmdtest.NewTerminal(consoleApp, width, height), eventHistory
+}
+
+func TestTerminal(t *testing.T) {
+	t.Parallel()
+
+	terminal, eventHistory := setupTerminal(t, 40, 8)
+	err := terminal.Start()
+	assert.NoError(t, err)
+	assert.Error(t, terminal.Start())
+
+	terminal.Send("greet World")
+	err = terminal.SendKeys("left", "left", "backspace", "ctrl+e", "enter")
+	assert.NoError(t, err)
+	err = terminal.WaitFor("Hello [Wold]!", cyclecmdtest.DEFAULT_TIMEOUT)
+	assert.NoError(t, err)
+
+	terminal.Send("help\r")
+	err = terminal.WaitFor("help   Lists all available commands", cyclecmdtest.DEFAULT_TIMEOUT)
+	assert.NoError(t, err)
+	terminal.AssertGolden(t, "help")
+	x, y := terminal.Cursor()
+	assert.Equal(t, 2, x)
+	assert.Equal(t, 7, y)
+
+	err = terminal.SendKeys("unknown key")
+	assert.EqualError(t, err, "key unknown key is unknown")
+
+	terminal.Close()
+	err = terminal.Wait(cyclecmdtest.DEFAULT_TIMEOUT)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"greet Wold", "help"}, eventHistory.SubmittedLines())
+}
+
+func TestTerminalResize(t *testing.T) {
+	t.Parallel()
+
+	terminal, _ := setupTerminal(t, 40, 8)
+	err := terminal.Start()
+	assert.NoError(t, err)
+	terminal.Resize(30, 8)
+	terminal.Send("help\r")
+	err = terminal.WaitFor("> ", cyclecmdtest.DEFAULT_TIMEOUT)
+	assert.NoError(t, err)
+	// The descriptions are wrapped to the new width
+	err = terminal.WaitFor("greet  Greets the person whose\n       name is passed as", cyclecmdtest.DEFAULT_TIMEOUT)
+	assert.NoError(t, err)
+
+	err = terminal.Wait(0)
+	assert.Error(t, err)
+	terminal.Close()
+	assert.NoError(t, terminal.Wait(cyclecmdtest.DEFAULT_TIMEOUT))
+}
+
+func TestKeyBytes(t *testing.T) {
+	t.Parallel()
+
+	for name, expKeyBytes := range map[string]string{
+		"a":      "a",
+		"Enter":  "\r",
+		"ctrl+c": "\x03",
+		"CTRL+R": "\x12",
+		"alt+b":  "\x1bb",
+		"pgdown": "\x1b[6~",
+		"up":     "\x1b[A",
+	} {
+		actKeyBytes, err := cyclecmdtest.KeyBytes(name)
+		assert.NoError(t, err)
+		assert.Equal(t, expKeyBytes, actKeyBytes, name)
+	}
+	_, err := cyclecmdtest.KeyBytes("ctrl+1")
+	assert.Error(t, err)
+}
+
+func TestTerminalPager(t *testing.T) {
+	t.Parallel()
+
+	eventRegistry := cyclecmd.NewEventRegistry(cyclecmd.EventInformation{})
+	consoleApp := cyclecmd.NewConsoleApp("test", "0.1.0", "This is a test console application", eventRegistry, cyclecmd.NewEventHistory())
+	commandRegistry := cyclecmd.NewCommandRegistry(consoleApp)
+	err := commandRegistry.RegisterCommand(cyclecmd.Command{
+		Name:        "page",
+		Description: "Shows ten lines in the pager",
+		Run: func(args []string) (error, *cyclecmd.ControlEvent) {
+			content := ""
+			for i := 1; i <= 10; i++ {
+				content += fmt.Sprintf("line %d\n", i)
+			}
+			pager, err := cyclecmd.NewPager(consoleApp, strings.NewReader(content))
+			if err != nil {
+				return err, nil
+			}
+			return pager.Run(), nil
+		},
+	})
+	assert.NoError(t, err)
+	lineEditor := cyclecmd.NewLineEditor(consoleApp, commandRegistry.Submit)
+	assert.NoError(t, lineEditor.RegisterEvents(eventRegistry))
+	consoleApp.SetLineDelimiter("> ", cyclecmd.KEY_ENTER)
+	consoleApp.DisableBanner()
+	terminal := cyclecmdtest.NewTerminal(consoleApp, 20, 4)
+	assert.NoError(t, terminal.Start())
+
+	terminal.Send("page\r")
+	assert.NoError(t, terminal.WaitFor("lines 1-3/10", cyclecmdtest.DEFAULT_TIMEOUT))
+	// Enter scrolls down and accepts the search without printing the delimiter into the pager
+	terminal.Send("\r")
+	assert.NoError(t, terminal.WaitFor("lines 2-4/10", cyclecmdtest.DEFAULT_TIMEOUT))
+	terminal.Send("/line 7\r")
+	assert.NoError(t, terminal.WaitFor("lines 7-9/10", cyclecmdtest.DEFAULT_TIMEOUT))
+	assert.NoError(t, terminal.WaitIdle(cyclecmdtest.DEFAULT_TIMEOUT))
+	terminal.AssertScreen(t, "line 7\nline 8\nline 9\nlines 7-9/10")
+
+	terminal.Send("q")
+	assert.NoError(t, terminal.WaitIdle(cyclecmdtest.DEFAULT_TIMEOUT))
+	// The prompt is printed once after the pager was quit
+	assert.Equal(t, "> page\n>", strings.TrimRight(terminal.Screen(), "\n"))
+	terminal.Close()
+	assert.NoError(t, terminal.Wait(cyclecmdtest.DEFAULT_TIMEOUT))
+}
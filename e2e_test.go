@@ -3,23 +3,38 @@
 package cyclecmd_test
 
 import (
-	"os"
+	"fmt"
 	"testing"
-	"time"
 
 	"github.com/RaphSku/cyclecmd"
+	"github.com/RaphSku/cyclecmd/cyclecmdtest"
 	"github.com/stretchr/testify/assert"
 )
 
+// EchoEvent prints the token to the output of the console application.
+type EchoEvent struct {
+	consoleApp *cyclecmd.ConsoleApp
+}
+
+func (ee *EchoEvent) Handle(token string) (error, *cyclecmd.ControlEvent) {
+	fmt.Fprint(ee.consoleApp.Output(), token)
+	return nil, nil
+}
+
+// EraseEvent erases the character in front of the cursor.
+type EraseEvent struct {
+	consoleApp *cyclecmd.ConsoleApp
+}
+
+func (ee *EraseEvent) Handle(token string) (error, *cyclecmd.ControlEvent) {
+	fmt.Fprint(ee.consoleApp.Output(), "\b \b")
+	return nil, nil
+}
+
 func TestEventLifecycle(t *testing.T) {
 	t.Parallel()
 
-	defaultEventInformation := cyclecmd.EventInformation{
-		EventName: "Default",
-		Event:     &DefaultEvent{},
-	}
-	eventRegistry := cyclecmd.NewEventRegistry(defaultEventInformation)
-
+	eventRegistry := cyclecmd.NewEventRegistry(cyclecmd.EventInformation{})
 	eventHistory := cyclecmd.NewEventHistory()
 
 	consoleApp := cyclecmd.NewConsoleApp(
@@ -31,34 +46,30 @@ func TestEventLifecycle(t *testing.T) {
 	)
 	consoleApp.ChangeToDebugMode()
 
+	eventRegistry.DefaultEventInformation = cyclecmd.EventInformation{
+		EventName: "Default",
+		Event:     &EchoEvent{consoleApp: consoleApp},
+	}
 	backspaceEventInformation := cyclecmd.EventInformation{
 		EventName: "Backspace",
-		Event:     &BackspaceEvent{},
+		Event:     &EraseEvent{consoleApp: consoleApp},
 	}
 	err := eventRegistry.RegisterEvent("\b", backspaceEventInformation)
 	assert.NoError(t, err)
 
-	userInput := []byte("Hello W\borld")
-
-	r, w, err := os.Pipe()
+	terminal := cyclecmdtest.NewTerminal(consoleApp, 80, 24)
+	expect := cyclecmdtest.NewExpect(t, terminal)
+	err = terminal.Start()
 	assert.NoError(t, err)
 
-	prevStdin := os.Stdin
-	defer func() { os.Stdin = prevStdin }()
-	os.Stdin = r
+	expect.Expect(`^Welcome to TestConsoleApp! Version: v0\.1\.0\r\nTest Console Application\r`)
+	expect.Send("Hello W\borld")
+	expect.ExpectEvent("Backspace")
+	expect.Expect(`^Hello W\x08 \x08orld$`)
+	terminal.Close()
+	expect.ExpectTerminated()
 
-	go func() {
-		for _, b := range userInput {
-			w.Write([]byte{b})
-			time.Sleep(10 * time.Millisecond)
-		}
-		w.Close()
-	}()
-
-	actOutput, err := captureStdOutput(func() {
-		consoleApp.Start()
-	})
-	assert.NoError(t, err)
-	expOutput := "Welcome to TestConsoleApp! Version: v0.1.0\r\nTest Console Application\rHello W\b \borld"
-	assert.Equal(t, expOutput, actOutput)
+	assert.Equal(t, 12, eventHistory.Len())
+	// The backspace erased the W and the text overwrote the description, since it ends with a carriage return
+	assert.Equal(t, "Hello orld", terminal.Lines()[1][:len("Hello orld")])
 }
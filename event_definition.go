@@ -23,10 +23,29 @@ type EventInformation struct {
 // This is especially useful for the DefaultEvent since that event gets triggered by every token
 // that is not already registered with another event.
 type EventHistoryEntry struct {
-	// Token is the same as event trigger
+	// Token is the same as event trigger, the tokens of a key sequence are separated by spaces
 	Token string
+	// Tokens contains the tokens of a key sequence in the order they were received, nil for a single token
+	Tokens []string
 	// The name of the event
 	EventName string
 	// The event instance itself
 	Event Event
 }
+
+// funcEvent adapts a function, usually a method, to the Event interface.
+type funcEvent struct {
+	handle func(token string) (error, *ControlEvent)
+}
+
+// Handle calls the adapted function with the token.
+//
+// Parameters:
+//   - `token` : Token that triggered the event
+//
+// Returns:
+//   - `error` : Error returned by the adapted function
+//   - `*ControlEvent` : Control event returned by the adapted function
+func (fe *funcEvent) Handle(token string) (error, *ControlEvent) {
+	return fe.handle(token)
+}
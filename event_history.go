@@ -1,6 +1,14 @@
 package cyclecmd
 
-import "fmt"
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// SUBMITTED_LINE_EVENT_NAME is the event name of event history entries that record a line that was
+// submitted in the line editor, the token of such an entry is the submitted line.
+const SUBMITTED_LINE_EVENT_NAME string = "SubmittedLine"
 
 // EventHistory records events and offers behavior to manipulate the history and to
 // read events from history.
@@ -61,20 +69,31 @@ func (eh *EventHistory) RemoveNthEventFromHistory(n int) {
 	eh.entries = append(eh.entries[:n], eh.entries[n+1:]...)
 }
 
-// PrintLastEventHistoryEntries will print information related to the last n events that
-// were recorded.
+// PrintLastEventHistoryEntries will print a table with the name and the token of the last n events that
+// were recorded to Stdout, see WriteLastEventHistoryEntries.
+//
+// Deprecated: The table bypasses the output of the console application, i.e. SetOutput, the session recording
+// and remote sessions. Use WriteLastEventHistoryEntries with ConsoleApp.Output instead.
 //
 // Parameters:
 //   - `n` : Number of events
 func (eh *EventHistory) PrintLastEventHistoryEntries(n int) {
-	count := 0
-	for i := eh.Len() - 1; i >= 0; i-- {
-		if count == n {
-			break
-		}
-		fmt.Printf("Event Name: %s, Token: %s\r\n", eh.entries[i].EventName, eh.entries[i].Token)
-		count += 1
+	eh.WriteLastEventHistoryEntries(os.Stdout, n)
+}
+
+// WriteLastEventHistoryEntries will write a table with the name and the token of the last n events that
+// were recorded, the most recent event comes first. If the writer is not a terminal, the table is written as
+// tab-separated values. Events should write to ConsoleApp.Output, so that the table reaches remote sessions.
+//
+// Parameters:
+//   - `w` : Writer the table is written to
+//   - `n` : Number of events
+func (eh *EventHistory) WriteLastEventHistoryEntries(w io.Writer, n int) {
+	table := NewTable("Event Name", "Token")
+	for i := eh.Len() - 1; i >= 0 && i >= eh.Len()-n; i-- {
+		table.AddRow(eh.entries[i].EventName, eh.entries[i].Token)
 	}
+	table.Write(w)
 }
 
 // GetLastEventsFromHistoryToEventReference will return all event names that followed after a specific event happened.
@@ -130,6 +149,20 @@ func (eh *EventHistory) MostRecentSpliceEventsOfHistory(eventName string) []Even
 	return reverseArray(splicedEvents)
 }
 
+// SubmittedLines returns all lines that were submitted in the line editor, starting with the oldest line.
+//
+// Returns:
+//   - `[]string` : Submitted lines in the order they were submitted
+func (eh *EventHistory) SubmittedLines() []string {
+	var lines []string
+	for _, eventHistoryEntry := range eh.entries {
+		if eventHistoryEntry.EventName == SUBMITTED_LINE_EVENT_NAME {
+			lines = append(lines, eventHistoryEntry.Token)
+		}
+	}
+	return lines
+}
+
 // Generic implementation to reverse a slice.
 //
 // Parameters:
@@ -4,6 +4,7 @@ package cyclecmd_test
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/RaphSku/cyclecmd"
@@ -138,12 +139,24 @@ func TestPrintLastEventHistoryEntries(t *testing.T) {
 		EventName: "B",
 		Event:     &TestEvent{},
 	}
-	expOutput1 := fmt.Sprintf("Event Name: %s, Token: %s\r\n", expEventHistoryEntryC.EventName, expEventHistoryEntryC.Token)
-	expOutput2 := fmt.Sprintf("Event Name: %s, Token: %s\r\n", expEventHistoryEntryB.EventName, expEventHistoryEntryB.Token)
-	expOutput := expOutput1 + expOutput2
+	// Stdout is a pipe, so the table is printed as tab-separated values
+	expOutput1 := fmt.Sprintf("%s\t%s\n", expEventHistoryEntryC.EventName, expEventHistoryEntryC.Token)
+	expOutput2 := fmt.Sprintf("%s\t%s\n", expEventHistoryEntryB.EventName, expEventHistoryEntryB.Token)
+	expOutput := "Event Name\tToken\n" + expOutput1 + expOutput2
 	assert.Equal(t, expOutput, actOutput)
 }
 
+func TestWriteLastEventHistoryEntries(t *testing.T) {
+	t.Parallel()
+
+	eventHistory, err := setupPopulatedEventHistory()
+	assert.NoError(t, err)
+
+	var output strings.Builder
+	eventHistory.WriteLastEventHistoryEntries(&output, 1)
+	assert.Equal(t, "Event Name\tToken\nC\tc\n", output.String())
+}
+
 func TestGetLastEventsFromHistoryToEventReference(t *testing.T) {
 	t.Parallel()
 
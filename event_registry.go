@@ -2,8 +2,17 @@ package cyclecmd
 
 import (
 	"fmt"
+	"strings"
 )
 
+// MASKED_TOKEN replaces the tokens of events that are handled by an event registry that masks its tokens, e.g. the
+// event registry of a password prompt, in the event history and the event hooks.
+const MASKED_TOKEN string = "*"
+
+// sequenceSeparator separates the tokens of a key sequence in the event trigger it is registered under, tokens
+// never contain it since the input is cut off at the first null byte.
+const sequenceSeparator = "\x00"
+
 // nonDefaultEvent is used when an event trigger is not registered and the byte length of
 // the token is greater than 3.
 type nonDefaultEvent struct{}
@@ -29,6 +38,14 @@ type EventRegistry struct {
 	// the EventInformation related to the event that was triggered by an event
 	registry map[string]EventInformation
 
+	// maskTokens hides the tokens in the event history and the session recording, so that secrets are not recorded
+	maskTokens bool
+
+	// sequences is the number of registered key sequences, tokens are only collected into sequences if it is positive
+	sequences int
+	// pendingSequence contains the tokens that were received so far of a key sequence that is not complete yet
+	pendingSequence string
+
 	// DefaultEventInformation contains information related to the default event that is triggered whenever
 	// a token does not match with any other event that is registered.
 	DefaultEventInformation EventInformation
@@ -58,6 +75,8 @@ func NewEventRegistry(defaultEventInformation EventInformation) *EventRegistry {
 // ResetEventRegistry resets the event registry, so all registered events are deleted from the registry.
 func (er *EventRegistry) ResetEventRegistry() {
 	er.registry = make(map[string]EventInformation)
+	er.sequences = 0
+	er.pendingSequence = ""
 }
 
 // RegisterEvent registers an event with an event trigger.
@@ -77,8 +96,148 @@ func (er *EventRegistry) RegisterEvent(eventTrigger string, eventInformation Eve
 	return nil
 }
 
+// RegisterEventSequence registers an event with a sequence of event triggers that have to be received one after
+// another, e.g. the keys `g g` of vi. The event is handled with the last token of the sequence. Tokens that start
+// a sequence are not dispatched on their own, if a token does not continue the sequence, the previous tokens are
+// dropped.
+//
+// Parameters:
+//   - `eventTriggers` : Triggers that kick off the event together, see ParseKey
+//   - `eventInformation` : Information related to the event that will be triggered by `eventTriggers`
+//
+// Returns:
+//   - `error` : Returns an error when the sequence is empty or the event is already registered
+func (er *EventRegistry) RegisterEventSequence(eventTriggers []string, eventInformation EventInformation) error {
+	if len(eventTriggers) == 0 {
+		return fmt.Errorf("event sequence has no event triggers")
+	}
+	if len(eventTriggers) == 1 {
+		return er.RegisterEvent(eventTriggers[0], eventInformation)
+	}
+	eventTrigger := strings.Join(eventTriggers, sequenceSeparator)
+	if _, ok := er.registry[eventTrigger]; ok {
+		return fmt.Errorf("event is already registered under event triggers %v", strings.Join(eventTriggers, " "))
+	}
+	er.bind(eventTriggers, eventInformation)
+	return nil
+}
+
+// bind registers the event under the sequence of event triggers and replaces the event that was registered
+// under them before.
+//
+// Parameters:
+//   - `eventTriggers` : Triggers that kick off the event together
+//   - `eventInformation` : Information related to the event
+func (er *EventRegistry) bind(eventTriggers []string, eventInformation EventInformation) {
+	eventTrigger := strings.Join(eventTriggers, sequenceSeparator)
+	if _, ok := er.registry[eventTrigger]; !ok && len(eventTriggers) > 1 {
+		er.sequences++
+	}
+	er.registry[eventTrigger] = eventInformation
+}
+
+// unbind removes the event that is registered under the sequence of event triggers.
+//
+// Parameters:
+//   - `eventTriggers` : Triggers the event is registered under
+func (er *EventRegistry) unbind(eventTriggers []string) {
+	eventTrigger := strings.Join(eventTriggers, sequenceSeparator)
+	if _, ok := er.registry[eventTrigger]; ok && len(eventTriggers) > 1 {
+		er.sequences--
+	}
+	delete(er.registry, eventTrigger)
+	er.pendingSequence = ""
+}
+
+// advanceSequence adds the token to the pending key sequence.
+//
+// Parameters:
+//   - `token` : Token that was received
+//
+// Returns:
+//   - `string` : Event trigger the token completes, either a registered sequence or the token itself
+//   - `bool` : False if the token starts or continues a sequence that is not complete yet
+func (er *EventRegistry) advanceSequence(token string) (string, bool) {
+	if er.sequences == 0 {
+		return token, true
+	}
+	sequence := token
+	if er.pendingSequence != "" {
+		sequence = er.pendingSequence + sequenceSeparator + token
+	}
+	er.pendingSequence = ""
+	if _, ok := er.registry[sequence]; ok {
+		return sequence, true
+	}
+	if er.isSequencePrefix(sequence) {
+		er.pendingSequence = sequence
+		return "", false
+	}
+	if sequence != token {
+		// The pending tokens are dropped, the token might start a new sequence on its own
+		return er.advanceSequence(token)
+	}
+	return token, true
+}
+
+// isSequencePrefix returns whether a registered key sequence starts with the sequence.
+//
+// Parameters:
+//   - `sequence` : Tokens joined by the sequence separator
+//
+// Returns:
+//   - `bool` : True if a longer sequence starts with the sequence
+func (er *EventRegistry) isSequencePrefix(sequence string) bool {
+	for eventTrigger := range er.registry {
+		if strings.HasPrefix(eventTrigger, sequence+sequenceSeparator) {
+			return true
+		}
+	}
+	return false
+}
+
+// RebindEvent binds a registered event to another event trigger, e.g. to let the user customise the keys of a
+// console application. The event keeps its previous event triggers and replaces the event that was registered
+// under the event trigger, if any.
+//
+// Parameters:
+//   - `eventTrigger` : Trigger that will kick off the event
+//   - `eventName` : Name of a registered event or of the default event
+//
+// Returns:
+//   - `error` : Returns an error when no event with the name is registered
+func (er *EventRegistry) RebindEvent(eventTrigger string, eventName string) error {
+	eventInformation, ok := er.eventInformationByName(eventName)
+	if !ok {
+		return fmt.Errorf("event %v is not registered", eventName)
+	}
+	er.registry[eventTrigger] = eventInformation
+	return nil
+}
+
+// eventInformationByName looks up a registered event or the default event by its name.
+//
+// Parameters:
+//   - `eventName` : Name of the event
+//
+// Returns:
+//   - `EventInformation` : Information related to the event
+//   - `bool` : False if no event with the name is registered
+func (er *EventRegistry) eventInformationByName(eventName string) (EventInformation, bool) {
+	if er.DefaultEventInformation.Event != nil && er.DefaultEventInformation.EventName == eventName {
+		return er.DefaultEventInformation, true
+	}
+	for _, eventInformation := range er.registry {
+		if eventInformation.EventName == eventName {
+			return eventInformation, true
+		}
+	}
+	return EventInformation{}, false
+}
+
 // GetMatchingEventInformation retrieves the information related to the event that gets triggered by `eventTrigger`.
-// The default event is returned when the event trigger matches no event registered in the event registry.
+// The default event is returned when the event trigger is a single byte or a printable character that matches no
+// event registered in the event registry.
 //
 // Parameters:
 //   - `eventTrigger` : Trigger for the event that should be returned
@@ -89,7 +248,8 @@ func (er *EventRegistry) RegisterEvent(eventTrigger string, eventInformation Eve
 func (er *EventRegistry) GetMatchingEventInformation(eventTrigger string) (EventInformation, error) {
 	eventInformation, ok := er.registry[eventTrigger]
 	if !ok {
-		if len([]byte(eventTrigger)) == 1 {
+		// Printable characters of more than one byte, like "é", are typed like any other character
+		if _, printable := printableCharacter(eventTrigger); printable || len([]byte(eventTrigger)) == 1 {
 			defaultEvent := er.DefaultEventInformation.Event
 			if defaultEvent == nil {
 				return EventInformation{}, fmt.Errorf("default event is not set! Please set it via InitEventRegistry")
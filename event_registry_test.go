@@ -3,13 +3,24 @@
 package cyclecmd_test
 
 import (
+	"bytes"
 	"fmt"
+	"io"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/RaphSku/cyclecmd"
 	"github.com/stretchr/testify/assert"
 )
 
+// TerminateEvent concludes the event loop.
+type TerminateEvent struct{}
+
+func (te *TerminateEvent) Handle(token string) (error, *cyclecmd.ControlEvent) {
+	return nil, cyclecmd.NewControlEvent(cyclecmd.CYCLE_TERMINATE)
+}
+
 func TestInitDefaultEvent(t *testing.T) {
 	t.Parallel()
 	defaultEventInformation := setupDefaultEventInformation()
@@ -91,3 +102,147 @@ func TestNoDefaultEventInRegistry(t *testing.T) {
 		assert.Equal(t, fmt.Errorf("default event is not set! Please set it via InitEventRegistry"), err)
 	}
 }
+
+func TestRebindEvent(t *testing.T) {
+	t.Parallel()
+
+	eventRegistry := cyclecmd.NewEventRegistry(setupDefaultEventInformation())
+	testEventInformation := cyclecmd.EventInformation{EventName: "Test", Event: &TestEvent{}}
+	err := eventRegistry.RegisterEvent("t", testEventInformation)
+	assert.NoError(t, err)
+
+	err = eventRegistry.RebindEvent(cyclecmd.KEY_CTRL_R, "Test")
+	assert.NoError(t, err)
+	actEventInformation, err := eventRegistry.GetMatchingEventInformation(cyclecmd.KEY_CTRL_R)
+	assert.NoError(t, err)
+	assert.Equal(t, testEventInformation, actEventInformation)
+	actEventInformation, err = eventRegistry.GetMatchingEventInformation("t")
+	assert.NoError(t, err)
+	assert.Equal(t, testEventInformation, actEventInformation)
+
+	err = eventRegistry.RebindEvent("t", "Default")
+	assert.NoError(t, err)
+	actEventInformation, err = eventRegistry.GetMatchingEventInformation("t")
+	assert.NoError(t, err)
+	assert.Equal(t, "Default", actEventInformation.EventName)
+
+	err = eventRegistry.RebindEvent("x", "Unknown")
+	assert.Equal(t, fmt.Errorf("event %v is not registered", "Unknown"), err)
+}
+
+func TestParseKey(t *testing.T) {
+	t.Parallel()
+
+	for name, expToken := range map[string]string{
+		"a":         "a",
+		"enter":     cyclecmd.KEY_ENTER,
+		"Ctrl+R":    cyclecmd.KEY_CTRL_R,
+		"up":        cyclecmd.KEY_ARROW_UP,
+		"pgdown":    cyclecmd.KEY_PAGE_DOWN,
+		"alt+b":     "\"\\x1bb\"",
+		"backspace": cyclecmd.KEY_BACKSPACE,
+	} {
+		actToken, err := cyclecmd.ParseKey(name)
+		assert.NoError(t, err)
+		assert.Equal(t, expToken, actToken, name)
+	}
+	_, err := cyclecmd.ParseKey("ctrl+shift+x")
+	assert.EqualError(t, err, "key ctrl+shift+x is unknown")
+}
+
+func TestNewKeyReader(t *testing.T) {
+	t.Parallel()
+
+	keyReader := cyclecmd.NewKeyReader(strings.NewReader("ab\x1b[A\x1bbü\x1b[5~\r\x1b\x1b"))
+	var keys []string
+	for {
+		key := make([]byte, 8)
+		n, err := keyReader.Read(key)
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		keys = append(keys, string(key[:n]))
+	}
+	assert.Equal(t, []string{"a", "b", "\x1b[A", "\x1bb", "ü", "\x1b[5~", "\r", "\x1b", "\x1b"}, keys)
+}
+
+func TestNewKeyReaderSplitEscapeSequences(t *testing.T) {
+	t.Parallel()
+
+	input, inputWriter := io.Pipe()
+	defer inputWriter.Close()
+	keyReader := cyclecmd.NewKeyReader(input)
+	readKey := func() string {
+		key := make([]byte, 8)
+		n, err := keyReader.Read(key)
+		assert.NoError(t, err)
+		return string(key[:n])
+	}
+
+	// Escape sequences that are split between two reads are completed
+	go func() {
+		for _, part := range []string{"a\x1b", "[A", "b\x1bO", "B"} {
+			inputWriter.Write([]byte(part))
+		}
+	}()
+	assert.Equal(t, "a", readKey())
+	assert.Equal(t, "\x1b[A", readKey())
+	assert.Equal(t, "b", readKey())
+	assert.Equal(t, "\x1bOB", readKey())
+
+	// A trailing ESC is the Escape key once no further input arrives
+	go inputWriter.Write([]byte("a\x1b"))
+	assert.Equal(t, "a", readKey())
+	assert.Equal(t, cyclecmd.KEY_ESCAPE, readKey())
+	go inputWriter.Write([]byte("x"))
+	assert.Equal(t, "x", readKey())
+
+	// A read that returns nothing but ESC is the Escape key
+	go func() {
+		inputWriter.Write([]byte("\x1b"))
+		inputWriter.Write([]byte("h"))
+	}()
+	assert.Equal(t, cyclecmd.KEY_ESCAPE, readKey())
+	assert.Equal(t, "h", readKey())
+}
+
+func TestEventLoopKeepsPendingRead(t *testing.T) {
+	t.Parallel()
+
+	// The pipe blocks between the writes, so that the trailing ESC is only split off once the escape timeout passed
+	input, inputWriter := io.Pipe()
+	defer inputWriter.Close()
+	eventRegistry := cyclecmd.NewEventRegistry(setupDefaultEventInformation())
+	eventHistory := cyclecmd.NewEventHistory()
+	consoleApp := cyclecmd.NewConsoleApp("test", "0.1.0", "This is a test console application", eventRegistry, eventHistory)
+	consoleApp.SetInput(input)
+	consoleApp.SetOutput(&bytes.Buffer{})
+	consoleApp.DisableBanner()
+	assert.NoError(t, eventRegistry.RegisterEvent(cyclecmd.KEY_ESCAPE, cyclecmd.EventInformation{EventName: "Quit", Event: &TerminateEvent{}}))
+	assert.NoError(t, eventRegistry.RegisterEvent("q", cyclecmd.EventInformation{EventName: "Quit", Event: &TerminateEvent{}}))
+
+	go inputWriter.Write([]byte("a\x1b"))
+	consoleApp.Start()
+	// The read that outlasted the escape timeout is still in progress, it returns the input of the next event loop
+	go inputWriter.Write([]byte("q"))
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		consoleApp.Start()
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		inputWriter.Close()
+		<-done
+	}
+
+	tokens := []string{}
+	for index := range eventHistory.Len() {
+		eventHistoryEntry, err := eventHistory.RetrieveEventEntryByIndex(index)
+		assert.NoError(t, err)
+		tokens = append(tokens, eventHistoryEntry.Token)
+	}
+	assert.Equal(t, []string{"a", cyclecmd.KEY_ESCAPE, "q"}, tokens)
+}
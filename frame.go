@@ -0,0 +1,132 @@
+package cyclecmd
+
+import (
+	"github.com/RaphSku/cyclecmd/style"
+)
+
+// Cell is a single character on the screen together with its style.
+type Cell struct {
+	// Rune is the character of the cell
+	Rune rune
+	// Style of the character
+	Style style.Style
+}
+
+// blankCell is the content of a cell that was not drawn.
+var blankCell = Cell{Rune: ' '}
+
+// Frame is a buffer of styled cells with the size of the screen, views draw into a frame which is then written
+// to the terminal. Coordinates start at 0 in the upper left corner, drawing outside of the frame is ignored.
+type Frame struct {
+	width  int
+	height int
+	cells  []Cell
+}
+
+// NewFrame initialises a frame where all cells are blank.
+//
+// Parameters:
+//   - `width` : Width in columns
+//   - `height` : Height in rows
+//
+// Returns:
+//   - `*Frame` : Returns an instance of Frame
+func NewFrame(width int, height int) *Frame {
+	width, height = max(0, width), max(0, height)
+	frame := &Frame{width: width, height: height, cells: make([]Cell, width*height)}
+	frame.Clear()
+	return frame
+}
+
+// Width returns the width of the frame.
+//
+// Returns:
+//   - `int` : Width in columns
+func (f *Frame) Width() int {
+	return f.width
+}
+
+// Height returns the height of the frame.
+//
+// Returns:
+//   - `int` : Height in rows
+func (f *Frame) Height() int {
+	return f.height
+}
+
+// Clear makes all cells blank.
+func (f *Frame) Clear() {
+	for i := range f.cells {
+		f.cells[i] = blankCell
+	}
+}
+
+// Set sets the cell at the position.
+//
+// Parameters:
+//   - `x` : Column of the cell
+//   - `y` : Row of the cell
+//   - `cell` : Content of the cell
+func (f *Frame) Set(x int, y int, cell Cell) {
+	if x < 0 || y < 0 || x >= f.width || y >= f.height {
+		return
+	}
+	f.cells[y*f.width+x] = cell
+}
+
+// Get returns the cell at the position.
+//
+// Parameters:
+//   - `x` : Column of the cell
+//   - `y` : Row of the cell
+//
+// Returns:
+//   - `Cell` : Content of the cell, a blank cell if the position is outside of the frame
+func (f *Frame) Get(x int, y int) Cell {
+	if x < 0 || y < 0 || x >= f.width || y >= f.height {
+		return blankCell
+	}
+	return f.cells[y*f.width+x]
+}
+
+// SetString draws the text starting at the position, the text is cut off at the right edge of the frame.
+// Wide characters occupy two cells, the second cell is a continuation cell whose rune is 0.
+//
+// Parameters:
+//   - `x` : Column of the first character
+//   - `y` : Row of the text
+//   - `text` : Text without escape sequences
+//   - `textStyle` : Style of the text
+//
+// Returns:
+//   - `int` : Column after the last character
+func (f *Frame) SetString(x int, y int, text string, textStyle style.Style) int {
+	for _, character := range text {
+		width := style.RuneWidth(character)
+		if width == 0 {
+			continue
+		}
+		f.Set(x, y, Cell{Rune: character, Style: textStyle})
+		if width == 2 {
+			f.Set(x+1, y, Cell{Style: textStyle})
+		}
+		x += width
+	}
+	return x
+}
+
+// Fill sets all cells of the rectangle.
+//
+// Parameters:
+//   - `x` : Column of the upper left corner
+//   - `y` : Row of the upper left corner
+//   - `width` : Width of the rectangle in columns
+//   - `height` : Height of the rectangle in rows
+//   - `cell` : Content of the cells
+func (f *Frame) Fill(x int, y int, width int, height int, cell Cell) {
+	for row := y; row < y+height; row++ {
+		for column := x; column < x+width; column++ {
+			f.Set(column, row, cell)
+		}
+	}
+}
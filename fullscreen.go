@@ -0,0 +1,138 @@
+package cyclecmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/RaphSku/cyclecmd/style"
+	"go.uber.org/zap"
+)
+
+// Escape sequences of the full-screen mode.
+const (
+	enterAlternateScreen = "\x1b[?1049h"
+	exitAlternateScreen  = "\x1b[?1049l"
+	hideCursor           = "\x1b[?25l"
+	showCursor           = "\x1b[?25h"
+	clearScreen          = "\x1b[2J"
+	resetStyle           = "\x1b[0m"
+)
+
+// View is an interface that defines how a full-screen view is drawn.
+//
+// View expects the following method to be implemented:
+//
+// Behavior:
+//   - `Render(frame *Frame)` : it expects a blank frame with the size of the screen that the view draws into,
+//     it is called after every dispatched event, async event, tick and resize
+type View interface {
+	Render(frame *Frame)
+}
+
+// ViewFunc adapts a function to the View interface.
+type ViewFunc func(frame *Frame)
+
+// Render calls the adapted function.
+//
+// Parameters:
+//   - `frame` : Frame the view draws into
+func (vf ViewFunc) Render(frame *Frame) {
+	vf(frame)
+}
+
+// EnterFullScreen switches to the alternate screen buffer and renders the view. From then on, the view is
+// rendered again after every dispatched event, async event, tick and resize. Only the cells that changed since
+// the previous frame are written to the terminal. If the full-screen mode is already active, the view is
+// replaced. The fixed regions of the screen are hidden while the full-screen mode is active.
+//
+// Parameters:
+//   - `view` : View that is rendered
+//
+// Returns:
+//   - `error` : Returns an error when the view is nil
+func (ca *ConsoleApp) EnterFullScreen(view View) error {
+	if view == nil {
+		return fmt.Errorf("full-screen mode cannot be entered without a view")
+	}
+	if ca.view == nil {
+		ca.screen.deactivate()
+		fmt.Fprint(ca.Output(), enterAlternateScreen+hideCursor)
+		ca.logger.Debug("Entered full-screen mode", zap.String("func", "EnterFullScreen"))
+	}
+	ca.view = view
+	ca.previousFrame = nil
+	ca.renderView()
+	return nil
+}
+
+// ExitFullScreen leaves the alternate screen buffer, the content of the terminal before the full-screen mode
+// was entered is restored. The full-screen mode is left automatically once the event loop concluded.
+//
+// Returns:
+//   - `error` : Returns an error when the full-screen mode is not active
+func (ca *ConsoleApp) ExitFullScreen() error {
+	if ca.view == nil {
+		return fmt.Errorf("full-screen mode is not active")
+	}
+	ca.view = nil
+	ca.previousFrame = nil
+	fmt.Fprint(ca.Output(), resetStyle+showCursor+exitAlternateScreen)
+	ca.screen.activate()
+	ca.logger.Debug("Left full-screen mode", zap.String("func", "ExitFullScreen"))
+	return nil
+}
+
+// IsFullScreen checks whether the full-screen mode is active.
+//
+// Returns:
+//   - `bool` : True if a view is rendered on the alternate screen buffer
+func (ca *ConsoleApp) IsFullScreen() bool {
+	return ca.view != nil
+}
+
+// renderView renders the view into a new frame and writes the cells that differ from the previous frame. The
+// whole screen is redrawn if there is no previous frame or the size of the screen changed.
+func (ca *ConsoleApp) renderView() {
+	if ca.view == nil {
+		return
+	}
+	width, height := ca.Size()
+	frame := NewFrame(width, height)
+	ca.view.Render(frame)
+
+	var output strings.Builder
+	previousFrame := ca.previousFrame
+	if previousFrame == nil || previousFrame.Width() != width || previousFrame.Height() != height {
+		output.WriteString(resetStyle + clearScreen)
+		previousFrame = NewFrame(width, height)
+	}
+	profile := ca.ColorProfile()
+	// The style is reset at the end of every frame, so every frame starts with the plain style
+	currentSequence := ""
+	cursorX, cursorY := -1, -1
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			cell := frame.Get(x, y)
+			// Continuation cells of wide characters are covered by the wide character
+			if cell == previousFrame.Get(x, y) || cell.Rune == 0 {
+				continue
+			}
+			if x != cursorX || y != cursorY {
+				fmt.Fprintf(&output, "\x1b[%d;%dH", y+1, x+1)
+			}
+			if sequence := cell.Style.Sequence(profile); sequence != currentSequence {
+				output.WriteString(resetStyle + sequence)
+				currentSequence = sequence
+			}
+			output.WriteRune(cell.Rune)
+			cursorX, cursorY = x+style.RuneWidth(cell.Rune), y
+		}
+	}
+	if currentSequence != "" {
+		output.WriteString(resetStyle)
+	}
+	ca.previousFrame = frame
+	if output.Len() > 0 {
+		fmt.Fprint(ca.Output(), output.String())
+	}
+}
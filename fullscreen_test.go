@@ -0,0 +1,96 @@
+//go:build unit_test
+
+package cyclecmd_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/RaphSku/cyclecmd"
+	"github.com/RaphSku/cyclecmd/style"
+	"github.com/stretchr/testify/assert"
+)
+
+// CounterView shows a counter that is incremented by the counter event.
+type CounterView struct {
+	count int
+}
+
+func (cv *CounterView) Render(frame *cyclecmd.Frame) {
+	frame.SetString(0, 0, fmt.Sprint(cv.count), style.New())
+	frame.SetString(0, 1, "abc", style.New().WithBold())
+}
+
+func (cv *CounterView) Handle(token string) (error, *cyclecmd.ControlEvent) {
+	cv.count += 1
+	return nil, nil
+}
+
+// FullScreenEvent enters the full-screen mode with the view.
+type FullScreenEvent struct {
+	consoleApp *cyclecmd.ConsoleApp
+	view       cyclecmd.View
+}
+
+func (fse *FullScreenEvent) Handle(token string) (error, *cyclecmd.ControlEvent) {
+	return fse.consoleApp.EnterFullScreen(fse.view), nil
+}
+
+func TestFrame(t *testing.T) {
+	t.Parallel()
+
+	frame := cyclecmd.NewFrame(3, 2)
+	assert.Equal(t, 3, frame.Width())
+	assert.Equal(t, 2, frame.Height())
+	assert.Equal(t, cyclecmd.Cell{Rune: ' '}, frame.Get(0, 0))
+
+	bold := style.New().WithBold()
+	assert.Equal(t, 5, frame.SetString(1, 0, "abcd", bold))
+	assert.Equal(t, cyclecmd.Cell{Rune: 'a', Style: bold}, frame.Get(1, 0))
+	assert.Equal(t, cyclecmd.Cell{Rune: 'b', Style: bold}, frame.Get(2, 0))
+	assert.Equal(t, cyclecmd.Cell{Rune: ' '}, frame.Get(3, 0))
+
+	// Wide characters occupy two cells
+	assert.Equal(t, 2, frame.SetString(0, 1, "日", bold))
+	assert.Equal(t, cyclecmd.Cell{Rune: '日', Style: bold}, frame.Get(0, 1))
+	assert.Equal(t, cyclecmd.Cell{Style: bold}, frame.Get(1, 1))
+
+	frame.Fill(0, 1, 10, 10, cyclecmd.Cell{Rune: '#'})
+	assert.Equal(t, cyclecmd.Cell{Rune: '#'}, frame.Get(2, 1))
+	frame.Clear()
+	assert.Equal(t, cyclecmd.Cell{Rune: ' '}, frame.Get(2, 1))
+}
+
+func TestFullScreenDiffRendering(t *testing.T) {
+	t.Parallel()
+
+	eventRegistry := cyclecmd.NewEventRegistry(setupDefaultEventInformation())
+	consoleApp := cyclecmd.NewConsoleApp("test", "0.1.0", "This is a test console application", eventRegistry, cyclecmd.NewEventHistory())
+	output := &bytes.Buffer{}
+	consoleApp.SetOutput(output)
+	consoleApp.SetInput(newTokenReader("v", "x", "y"))
+	consoleApp.SetColorProfile(style.PROFILE_ANSI16)
+	consoleApp.DisableBanner()
+	consoleApp.Resize(4, 2)
+
+	view := &CounterView{}
+	err := eventRegistry.RegisterEvent("v", cyclecmd.EventInformation{EventName: "FullScreen", Event: &FullScreenEvent{consoleApp: consoleApp, view: view}})
+	assert.NoError(t, err)
+	err = eventRegistry.RegisterEvent("x", cyclecmd.EventInformation{EventName: "Count", Event: view})
+	assert.NoError(t, err)
+	var isFullScreen bool
+	consoleApp.OnExit(func(reason cyclecmd.ExitReason, err error) {
+		isFullScreen = consoleApp.IsFullScreen()
+	})
+
+	consoleApp.Start()
+	expOutput := "\x1b[?1049h\x1b[?25l" +
+		"\x1b[0m\x1b[2J\x1b[1;1H0\x1b[2;1H\x1b[0m\x1b[1mabc\x1b[0m" +
+		"\x1b[1;1H1" +
+		"\x1b[0m\x1b[?25h\x1b[?1049l"
+	assert.Equal(t, expOutput, output.String())
+	assert.False(t, isFullScreen)
+	assert.Error(t, consoleApp.ExitFullScreen())
+	assert.Error(t, consoleApp.EnterFullScreen(nil))
+}
@@ -2,17 +2,20 @@ module github.com/RaphSku/cyclecmd
 
 go 1.24.2
 
-require github.com/stretchr/testify v1.9.0
-
 require (
-	go.uber.org/multierr v1.10.0 // indirect
-	golang.org/x/sys v0.21.0 // indirect
+	github.com/BurntSushi/toml v1.4.0
+	github.com/gorilla/websocket v1.5.3
+	github.com/stretchr/testify v1.9.0
+	golang.org/x/crypto v0.24.0
+	golang.org/x/sys v0.21.0
 )
 
+require go.uber.org/multierr v1.10.0 // indirect
+
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	go.uber.org/zap v1.27.0
 	golang.org/x/term v0.21.0
-	gopkg.in/yaml.v3 v3.0.1 // indirect
+	gopkg.in/yaml.v3 v3.0.1
 )
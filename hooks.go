@@ -0,0 +1,214 @@
+package cyclecmd
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ExitReason describes why the event loop concluded.
+type ExitReason int
+
+const (
+	// EXIT_END_OF_INPUT indicates that the input was exhausted.
+	EXIT_END_OF_INPUT ExitReason = iota
+	// EXIT_TERMINATED indicates that an event or a hook returned a control event with the termination flag.
+	EXIT_TERMINATED
+	// EXIT_ERROR indicates that reading the input, a hook or the event handling failed.
+	EXIT_ERROR
+	// EXIT_SIGNAL indicates that the process received a terminating signal like SIGINT, SIGTERM or SIGHUP.
+	EXIT_SIGNAL
+)
+
+// String returns a human readable representation of the exit reason.
+//
+// Returns:
+//   - `string` : Name of the exit reason
+func (er ExitReason) String() string {
+	switch er {
+	case EXIT_END_OF_INPUT:
+		return "end of input"
+	case EXIT_TERMINATED:
+		return "terminated"
+	case EXIT_ERROR:
+		return "error"
+	case EXIT_SIGNAL:
+		return "signal"
+	}
+	return "unknown"
+}
+
+// hooks contains all lifecycle hooks that are registered with a console application.
+type hooks struct {
+	onStart     []func() error
+	beforeEvent []func(eventHistoryEntry EventHistoryEntry) *ControlEvent
+	afterEvent  []func(eventHistoryEntry EventHistoryEntry) *ControlEvent
+	onError     []func(err error)
+	onResize    []func(width int, height int)
+	onTick      []func(now time.Time) *ControlEvent
+	onExit      []func(reason ExitReason, err error)
+	// onConfigReload is called whenever a watched configuration file was reloaded
+	onConfigReload []func(path string, err error)
+}
+
+// OnStart registers a hook that is called after the terminal entered raw mode and before the welcome message
+// is printed. The event loop is not entered if a hook returns an error.
+//
+// Parameters:
+//   - `hook` : Hook that is called on start
+func (ca *ConsoleApp) OnStart(hook func() error) {
+	ca.hooks.onStart = append(ca.hooks.onStart, hook)
+}
+
+// BeforeEvent registers a hook that is called with the event history entry before the event is recorded and
+// handled. The hook can return a control event with the veto flag to skip the event or with the termination flag
+// to stop the event loop, otherwise it should return nil.
+//
+// Parameters:
+//   - `hook` : Hook that is called before every event
+func (ca *ConsoleApp) BeforeEvent(hook func(eventHistoryEntry EventHistoryEntry) *ControlEvent) {
+	ca.hooks.beforeEvent = append(ca.hooks.beforeEvent, hook)
+}
+
+// AfterEvent registers a hook that is called with the event history entry after the event was handled. The hook
+// can return a control event with the termination flag to stop the event loop, otherwise it should return nil.
+//
+// Parameters:
+//   - `hook` : Hook that is called after every event
+func (ca *ConsoleApp) AfterEvent(hook func(eventHistoryEntry EventHistoryEntry) *ControlEvent) {
+	ca.hooks.afterEvent = append(ca.hooks.afterEvent, hook)
+}
+
+// OnError registers a hook that is called whenever reading the input, a hook or the event handling fails.
+//
+// Parameters:
+//   - `hook` : Hook that is called with the error
+func (ca *ConsoleApp) OnError(hook func(err error)) {
+	ca.hooks.onError = append(ca.hooks.onError, hook)
+}
+
+// OnResize registers a hook that is called with the new size of the terminal whenever the terminal is resized.
+//
+// Parameters:
+//   - `hook` : Hook that is called with the width and height in columns and rows
+func (ca *ConsoleApp) OnResize(hook func(width int, height int)) {
+	ca.hooks.onResize = append(ca.hooks.onResize, hook)
+}
+
+// OnTick registers a hook that is called on every tick of the event loop, see SetTickInterval. The hook can
+// return a control event with the termination flag to stop the event loop, otherwise it should return nil.
+//
+// Parameters:
+//   - `hook` : Hook that is called with the time of the tick
+func (ca *ConsoleApp) OnTick(hook func(now time.Time) *ControlEvent) {
+	ca.hooks.onTick = append(ca.hooks.onTick, hook)
+}
+
+// OnExit registers a hook that is called once the event loop concluded and before the terminal state is restored.
+//
+// Parameters:
+//   - `hook` : Hook that is called with the exit reason and the error that stopped the event loop, if any
+func (ca *ConsoleApp) OnExit(hook func(reason ExitReason, err error)) {
+	ca.hooks.onExit = append(ca.hooks.onExit, hook)
+}
+
+// OnConfigReload registers a hook that is called between events whenever a watched configuration file changed,
+// see WatchKeyBindings and WatchTheme.
+//
+// Parameters:
+//   - `hook` : Hook that is called with the path of the file and the error if the file is invalid, in that case
+//     the previous configuration is kept
+func (ca *ConsoleApp) OnConfigReload(hook func(path string, err error)) {
+	ca.hooks.onConfigReload = append(ca.hooks.onConfigReload, hook)
+}
+
+// runStartHooks calls all start hooks until a hook fails.
+//
+// Returns:
+//   - `error` : The error of the first failing hook
+func (ca *ConsoleApp) runStartHooks() error {
+	for _, hook := range ca.hooks.onStart {
+		if err := hook(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runEventHooks calls the event hooks with the event history entry until a hook returns a control event.
+//
+// Parameters:
+//   - `eventHooks` : Either the before or after event hooks
+//   - `eventHistoryEntry` : Entry of the event that is handled
+//
+// Returns:
+//   - `*ControlEvent` : The control event of the first hook that returned one
+func (ca *ConsoleApp) runEventHooks(eventHooks []func(eventHistoryEntry EventHistoryEntry) *ControlEvent, eventHistoryEntry EventHistoryEntry) *ControlEvent {
+	for _, hook := range eventHooks {
+		if controlEvent := hook(eventHistoryEntry); controlEvent != nil {
+			return controlEvent
+		}
+	}
+	return nil
+}
+
+// runErrorHooks calls all error hooks with the error.
+//
+// Parameters:
+//   - `err` : The error that occurred
+func (ca *ConsoleApp) runErrorHooks(err error) {
+	ca.logger.Debug("Running error hooks", zap.Error(err), zap.String("func", "runErrorHooks"))
+	for _, hook := range ca.hooks.onError {
+		hook(err)
+	}
+}
+
+// runResizeHooks calls all resize hooks with the new size of the terminal.
+//
+// Parameters:
+//   - `width` : Width in columns
+//   - `height` : Height in rows
+func (ca *ConsoleApp) runResizeHooks(width int, height int) {
+	for _, hook := range ca.hooks.onResize {
+		hook(width, height)
+	}
+}
+
+// runTickHooks calls the tick hooks until a hook returns a control event.
+//
+// Parameters:
+//   - `now` : Time of the tick
+//
+// Returns:
+//   - `*ControlEvent` : The control event of the first hook that returned one
+func (ca *ConsoleApp) runTickHooks(now time.Time) *ControlEvent {
+	for _, hook := range ca.hooks.onTick {
+		if controlEvent := hook(now); controlEvent != nil {
+			return controlEvent
+		}
+	}
+	return nil
+}
+
+// runExitHooks calls all exit hooks with the exit reason.
+//
+// Parameters:
+//   - `reason` : Reason why the event loop concluded
+//   - `err` : The error that stopped the event loop, if any
+func (ca *ConsoleApp) runExitHooks(reason ExitReason, err error) {
+	ca.logger.Debug("Running exit hooks", zap.String("reason", reason.String()), zap.String("func", "runExitHooks"))
+	for _, hook := range ca.hooks.onExit {
+		hook(reason, err)
+	}
+}
+
+// runConfigReloadHooks calls all config reload hooks.
+//
+// Parameters:
+//   - `path` : Path of the configuration file that was reloaded
+//   - `err` : The error if the file is invalid
+func (ca *ConsoleApp) runConfigReloadHooks(path string, err error) {
+	for _, hook := range ca.hooks.onConfigReload {
+		hook(path, err)
+	}
+}
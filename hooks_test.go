@@ -0,0 +1,131 @@
+//go:build unit_test
+
+package cyclecmd_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"testing/iotest"
+
+	"github.com/RaphSku/cyclecmd"
+	"github.com/stretchr/testify/assert"
+)
+
+type FailingEvent struct{}
+
+func (fe *FailingEvent) Handle(token string) (error, *cyclecmd.ControlEvent) {
+	return fmt.Errorf("event failed"), nil
+}
+
+func setupHooksConsoleApp(tokens ...string) (*cyclecmd.ConsoleApp, *cyclecmd.EventRegistry, *cyclecmd.EventHistory, *bytes.Buffer) {
+	output := &bytes.Buffer{}
+	eventRegistry := cyclecmd.NewEventRegistry(setupDefaultEventInformation())
+	eventHistory := cyclecmd.NewEventHistory()
+	consoleApp := cyclecmd.NewConsoleApp("test", "0.1.0", "This is a test console application", eventRegistry, eventHistory)
+	consoleApp.SetInput(newTokenReader(tokens...))
+	consoleApp.SetOutput(output)
+	return consoleApp, eventRegistry, eventHistory, output
+}
+
+func TestLifecycleHooks(t *testing.T) {
+	t.Parallel()
+
+	consoleApp, _, eventHistory, _ := setupHooksConsoleApp("a", "b")
+	calls := []string{}
+	consoleApp.OnStart(func() error {
+		calls = append(calls, "start")
+		return nil
+	})
+	consoleApp.BeforeEvent(func(eventHistoryEntry cyclecmd.EventHistoryEntry) *cyclecmd.ControlEvent {
+		calls = append(calls, "before "+eventHistoryEntry.Token)
+		return nil
+	})
+	consoleApp.AfterEvent(func(eventHistoryEntry cyclecmd.EventHistoryEntry) *cyclecmd.ControlEvent {
+		calls = append(calls, "after "+eventHistoryEntry.Token)
+		return nil
+	})
+	consoleApp.OnExit(func(reason cyclecmd.ExitReason, err error) {
+		calls = append(calls, "exit "+reason.String())
+		assert.NoError(t, err)
+	})
+
+	_, err := captureStdOutput(func() {
+		consoleApp.Start()
+	})
+	assert.NoError(t, err)
+	expCalls := []string{"start", "before a", "after a", "before b", "after b", "exit end of input"}
+	assert.Equal(t, expCalls, calls)
+	assert.Equal(t, 2, eventHistory.Len())
+}
+
+func TestBeforeEventHookVeto(t *testing.T) {
+	t.Parallel()
+
+	consoleApp, _, eventHistory, _ := setupHooksConsoleApp("a", "x", "b", "q", "c")
+	consoleApp.BeforeEvent(func(eventHistoryEntry cyclecmd.EventHistoryEntry) *cyclecmd.ControlEvent {
+		switch eventHistoryEntry.Token {
+		case "x":
+			return cyclecmd.NewControlEvent(cyclecmd.CYCLE_VETO)
+		case "q":
+			return cyclecmd.NewControlEvent(cyclecmd.CYCLE_TERMINATE)
+		}
+		return nil
+	})
+	var actReason cyclecmd.ExitReason
+	consoleApp.OnExit(func(reason cyclecmd.ExitReason, err error) {
+		actReason = reason
+	})
+
+	_, err := captureStdOutput(func() {
+		consoleApp.Start()
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, cyclecmd.EXIT_TERMINATED, actReason)
+	assert.Equal(t, 2, eventHistory.Len())
+	for i, expToken := range []string{"a", "b"} {
+		actEventHistoryEntry, err := eventHistory.RetrieveEventEntryByIndex(i)
+		assert.NoError(t, err)
+		assert.Equal(t, expToken, actEventHistoryEntry.Token)
+	}
+}
+
+func TestErrorAndStartHooks(t *testing.T) {
+	t.Parallel()
+
+	consoleApp, eventRegistry, _, _ := setupHooksConsoleApp("f")
+	err := eventRegistry.RegisterEvent("f", cyclecmd.EventInformation{EventName: "Failing", Event: &FailingEvent{}})
+	assert.NoError(t, err)
+	var actErrors []error
+	consoleApp.OnError(func(err error) {
+		actErrors = append(actErrors, err)
+	})
+	var actReason cyclecmd.ExitReason
+	consoleApp.OnExit(func(reason cyclecmd.ExitReason, err error) {
+		actReason = reason
+	})
+	consoleApp.Start()
+	assert.Equal(t, []error{fmt.Errorf("event failed")}, actErrors)
+	assert.Equal(t, cyclecmd.EXIT_ERROR, actReason)
+
+	consoleApp, _, _, output := setupHooksConsoleApp("a")
+	consoleApp.OnStart(func() error {
+		return fmt.Errorf("start failed")
+	})
+	consoleApp.Start()
+	assert.Equal(t, "", output.String())
+
+	// A failing input concludes the event loop with the error even if nothing was read
+	consoleApp, _, _, _ = setupHooksConsoleApp()
+	consoleApp.SetInput(iotest.ErrReader(fmt.Errorf("connection reset")))
+	actErrors = nil
+	consoleApp.OnError(func(err error) {
+		actErrors = append(actErrors, err)
+	})
+	consoleApp.OnExit(func(reason cyclecmd.ExitReason, err error) {
+		actReason = reason
+	})
+	consoleApp.Start()
+	assert.Equal(t, []error{fmt.Errorf("connection reset")}, actErrors)
+	assert.Equal(t, cyclecmd.EXIT_ERROR, actReason)
+}
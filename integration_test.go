@@ -1,3 +1,209 @@
-//go:build integration_test
+//go:build integration_test && linux
 
 package cyclecmd_test
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/RaphSku/cyclecmd"
+	"github.com/RaphSku/cyclecmd/cyclecmdtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ptyHelperEnv selects the mode of TestPTYHelperProcess, the helper process only runs if it is set.
+const ptyHelperEnv = "CYCLECMD_PTY_HELPER"
+
+type QuitEvent struct{}
+
+func (qe *QuitEvent) Handle(token string) (error, *cyclecmd.ControlEvent) {
+	return nil, cyclecmd.NewControlEvent(cyclecmd.CYCLE_TERMINATE)
+}
+
+type ErrorEvent struct{}
+
+func (ee *ErrorEvent) Handle(token string) (error, *cyclecmd.ControlEvent) {
+	return fmt.Errorf("event failed"), nil
+}
+
+type PanicEvent struct{}
+
+func (pe *PanicEvent) Handle(token string) (error, *cyclecmd.ControlEvent) {
+	panic("event panicked")
+}
+
+// BlockingEvent never returns, so that the event loop is busy.
+type BlockingEvent struct{}
+
+func (be *BlockingEvent) Handle(token string) (error, *cyclecmd.ControlEvent) {
+	fmt.Fprint(os.Stdout, "blocking\r\n")
+	select {}
+}
+
+// RawModeEvent records whether the terminal is in raw mode while an event is handled.
+type RawModeEvent struct {
+	pty *cyclecmdtest.PTY
+	raw chan bool
+}
+
+func (rme *RawModeEvent) Handle(token string) (error, *cyclecmd.ControlEvent) {
+	raw, err := rme.pty.IsRaw()
+	rme.raw <- raw
+	return err, nil
+}
+
+func setupPTYConsoleApp(input *os.File, output *os.File) (*cyclecmd.ConsoleApp, *cyclecmd.EventRegistry) {
+	eventRegistry := cyclecmd.NewEventRegistry(setupDefaultEventInformation())
+	eventRegistry.RegisterEvent("q", cyclecmd.EventInformation{EventName: "Quit", Event: &QuitEvent{}})
+	eventRegistry.RegisterEvent("e", cyclecmd.EventInformation{EventName: "Fail", Event: &ErrorEvent{}})
+	eventRegistry.RegisterEvent("p", cyclecmd.EventInformation{EventName: "Panic", Event: &PanicEvent{}})
+	consoleApp := cyclecmd.NewConsoleApp("test", "0.1.0", "This is a test console application", eventRegistry, cyclecmd.NewEventHistory())
+	consoleApp.SetInput(input)
+	consoleApp.SetOutput(output)
+	consoleApp.SetBannerTemplate("ready\r\n")
+	return consoleApp, eventRegistry
+}
+
+func TestPTYRestoresTerminal(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		token     string
+		expReason cyclecmd.ExitReason
+	}{
+		{name: "exit", token: "q", expReason: cyclecmd.EXIT_TERMINATED},
+		{name: "error", token: "e", expReason: cyclecmd.EXIT_ERROR},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			pty, err := cyclecmdtest.OpenPTY()
+			require.NoError(t, err)
+			defer pty.Close()
+			expAttributes, err := pty.Attributes()
+			require.NoError(t, err)
+
+			consoleApp, eventRegistry := setupPTYConsoleApp(pty.Slave, pty.Slave)
+			rawModeEvent := &RawModeEvent{pty: pty, raw: make(chan bool, 1)}
+			eventRegistry.RegisterEvent("r", cyclecmd.EventInformation{EventName: "RawMode", Event: rawModeEvent})
+			reasons := make(chan cyclecmd.ExitReason, 1)
+			consoleApp.OnExit(func(reason cyclecmd.ExitReason, err error) {
+				reasons <- reason
+			})
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				consoleApp.Start()
+			}()
+
+			require.NoError(t, pty.WaitFor("ready", cyclecmdtest.DEFAULT_TIMEOUT))
+			require.NoError(t, pty.Send("r"))
+			assert.True(t, <-rawModeEvent.raw)
+			require.NoError(t, pty.Send(test.token))
+			select {
+			case <-done:
+			case <-time.After(cyclecmdtest.DEFAULT_TIMEOUT):
+				t.Fatal("event loop did not conclude")
+			}
+
+			assert.Equal(t, test.expReason, <-reasons)
+			actAttributes, err := pty.Attributes()
+			require.NoError(t, err)
+			assert.Equal(t, expAttributes, actAttributes)
+		})
+	}
+}
+
+func TestPTYRestoresTerminalInHelperProcess(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		interrupt func(pty *cyclecmdtest.PTY, process *os.Process) error
+		expOutput string
+		expFailed bool
+	}{
+		{
+			name:      "panic",
+			interrupt: func(pty *cyclecmdtest.PTY, process *os.Process) error { return pty.Send("p") },
+			expFailed: true,
+		},
+		{
+			name:      "signal",
+			interrupt: func(pty *cyclecmdtest.PTY, process *os.Process) error { return process.Signal(syscall.SIGTERM) },
+			expOutput: "exit: signal",
+		},
+		{
+			// A busy event loop does not swallow the signal, it terminates the process
+			name: "busy",
+			interrupt: func(pty *cyclecmdtest.PTY, process *os.Process) error {
+				if err := pty.Send("b"); err != nil {
+					return err
+				}
+				if err := pty.WaitFor("blocking", cyclecmdtest.DEFAULT_TIMEOUT); err != nil {
+					return err
+				}
+				return process.Signal(syscall.SIGTERM)
+			},
+			expFailed: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			pty, err := cyclecmdtest.OpenPTY()
+			require.NoError(t, err)
+			defer pty.Close()
+			expAttributes, err := pty.Attributes()
+			require.NoError(t, err)
+
+			command := exec.Command(os.Args[0], "-test.run=^TestPTYHelperProcess$")
+			command.Env = append(os.Environ(), ptyHelperEnv+"="+test.name)
+			command.Stdin = pty.Slave
+			command.Stdout = pty.Slave
+			require.NoError(t, command.Start())
+
+			require.NoError(t, pty.WaitFor("ready", cyclecmdtest.DEFAULT_TIMEOUT))
+			raw, err := pty.IsRaw()
+			require.NoError(t, err)
+			assert.True(t, raw)
+			require.NoError(t, test.interrupt(pty, command.Process))
+			err = command.Wait()
+			if test.expFailed {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			if test.expOutput != "" {
+				assert.NoError(t, pty.WaitFor(test.expOutput, cyclecmdtest.DEFAULT_TIMEOUT))
+			}
+
+			actAttributes, err := pty.Attributes()
+			require.NoError(t, err)
+			assert.Equal(t, expAttributes, actAttributes)
+		})
+	}
+}
+
+// TestPTYHelperProcess runs the console application in the process that TestPTYRestoresTerminalInHelperProcess
+// starts on the slave side of a pseudo-terminal, since a panic or a signal affects the whole process.
+func TestPTYHelperProcess(t *testing.T) {
+	if os.Getenv(ptyHelperEnv) == "" {
+		t.Skip("only runs as helper process")
+	}
+
+	consoleApp, eventRegistry := setupPTYConsoleApp(os.Stdin, os.Stdout)
+	eventRegistry.RegisterEvent("b", cyclecmd.EventInformation{EventName: "Block", Event: &BlockingEvent{}})
+	consoleApp.OnExit(func(reason cyclecmd.ExitReason, err error) {
+		fmt.Fprintf(os.Stdout, "exit: %v\r\n", reason)
+	})
+	consoleApp.Start()
+}
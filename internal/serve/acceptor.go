@@ -0,0 +1,165 @@
+// Package serve accepts and tracks the connections of the servers of sshserver and netserver, so that closing a
+// server closes its listeners and connections and waits until the connections are handled.
+package serve
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+)
+
+// Acceptor accepts connections on listeners and handles every connection in a separate goroutine.
+type Acceptor struct {
+	// name of the server in error messages, e.g. "ssh server"
+	name string
+	// errClosed is returned by Serve once the acceptor was closed
+	errClosed error
+	// handle handles a connection, the connection is closed once it returns
+	handle func(conn net.Conn)
+	// maxConnections limits the number of concurrent connections, there is no limit if it is not positive
+	maxConnections int
+	// reject is called with the connections that exceed maxConnections before they are closed
+	reject func(conn net.Conn)
+
+	// mutex guards listeners, connections and closed
+	mutex       sync.Mutex
+	listeners   map[net.Listener]struct{}
+	connections map[net.Conn]struct{}
+	closed      bool
+	// handlers waits for the goroutines that handle the connections
+	handlers sync.WaitGroup
+}
+
+// NewAcceptor initialises an acceptor that handles the connections with handle.
+//
+// Parameters:
+//   - `name` : Name of the server in error messages, e.g. "ssh server"
+//   - `errClosed` : Error that Serve returns once the acceptor was closed
+//   - `handle` : Handles a connection, the connection is closed once it returns
+//
+// Returns:
+//   - `*Acceptor` : Returns an instance of Acceptor
+func NewAcceptor(name string, errClosed error, handle func(conn net.Conn)) *Acceptor {
+	return &Acceptor{
+		name:        name,
+		errClosed:   errClosed,
+		handle:      handle,
+		listeners:   make(map[net.Listener]struct{}),
+		connections: make(map[net.Conn]struct{}),
+	}
+}
+
+// SetMaxConnections limits the number of concurrent connections, further connections are passed to reject and
+// closed right away. It has to be set before the acceptor serves connections.
+//
+// Parameters:
+//   - `maxConnections` : Maximum number of concurrent connections, no limit if it is not positive
+//   - `reject` : Called with every connection that exceeds the limit, e.g. to tell the client to try again later
+func (a *Acceptor) SetMaxConnections(maxConnections int, reject func(conn net.Conn)) {
+	a.maxConnections = maxConnections
+	a.reject = reject
+}
+
+// Serve accepts connections on the listener until the acceptor is closed, every connection is handled in a
+// separate goroutine. The listener is closed once Serve returns.
+//
+// Parameters:
+//   - `listener` : Listener that accepts the connections
+//
+// Returns:
+//   - `error` : Returns an error when a connection could not be accepted, errClosed once the acceptor was closed
+func (a *Acceptor) Serve(listener net.Listener) error {
+	defer listener.Close()
+	if !a.track(func() { a.listeners[listener] = struct{}{} }) {
+		return a.errClosed
+	}
+	defer a.track(func() { delete(a.listeners, listener) })
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if a.isClosed() {
+				return a.errClosed
+			}
+			return fmt.Errorf("%v could not accept a connection! error: %w", a.name, err)
+		}
+		full := false
+		if !a.track(func() {
+			full = a.maxConnections > 0 && len(a.connections) >= a.maxConnections
+			if !full {
+				a.connections[conn] = struct{}{}
+			}
+		}) {
+			conn.Close()
+			return a.errClosed
+		}
+		if full {
+			a.reject(conn)
+			conn.Close()
+			continue
+		}
+		a.handlers.Add(1)
+		go a.handleConnection(conn)
+	}
+}
+
+// Close closes all listeners and connections and waits until all connections are handled.
+//
+// Returns:
+//   - `error` : Returns an error when a listener could not be closed
+func (a *Acceptor) Close() error {
+	a.mutex.Lock()
+	a.closed = true
+	var errs []error
+	for listener := range a.listeners {
+		if err := listener.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+			errs = append(errs, err)
+		}
+	}
+	for conn := range a.connections {
+		conn.Close()
+	}
+	a.mutex.Unlock()
+
+	a.handlers.Wait()
+	return errors.Join(errs...)
+}
+
+// handleConnection handles the connection, closes it and stops tracking it.
+//
+// Parameters:
+//   - `conn` : Accepted connection
+func (a *Acceptor) handleConnection(conn net.Conn) {
+	defer a.handlers.Done()
+	defer a.track(func() { delete(a.connections, conn) })
+	defer conn.Close()
+	a.handle(conn)
+}
+
+// track changes the tracked listeners and connections unless the acceptor was closed.
+//
+// Parameters:
+//   - `change` : Changes the tracked listeners or connections
+//
+// Returns:
+//   - `bool` : False if the acceptor was closed
+func (a *Acceptor) track(change func()) bool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if a.closed {
+		return false
+	}
+	change()
+	return true
+}
+
+// isClosed returns whether the acceptor was closed.
+//
+// Returns:
+//   - `bool` : True if the acceptor was closed
+func (a *Acceptor) isClosed() bool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.closed
+}
@@ -0,0 +1,190 @@
+package cyclecmd
+
+import (
+	"io"
+	"time"
+	"unicode/utf8"
+)
+
+// escapeTimeout is the time the key reader waits for the rest of an escape sequence that was split between two
+// reads, a lone ESC is read as the Escape key once it passed.
+const escapeTimeout = 50 * time.Millisecond
+
+// readChunkSize is the maximum number of bytes that are read from the input at once, a terminal in raw mode
+// returns a single key press per read.
+const readChunkSize = 3
+
+// keyReader splits the bytes of its input into keys, see NewKeyReader.
+type keyReader struct {
+	input io.Reader
+	// pending contains the bytes that were read but not returned yet
+	pending []byte
+	// err is the error of the last read, it is returned once all pending bytes were returned
+	err error
+	// lastReadLength is the number of bytes the last read returned
+	lastReadLength int
+	// reads receives the result of the read that outlasted the escape timeout, nil if no read is in progress
+	reads chan readResult
+}
+
+// NewKeyReader returns a reader that returns a single key per read, just like a terminal in raw mode delivers
+// them. Several keys that were typed quickly or pasted arrive at once, especially over a network connection, and
+// would otherwise be read as a single token. Escape sequences, keys pressed with Alt and multi-byte characters are
+// kept together. The event loop splits its input with a key reader.
+//
+// An ESC at the end of a read waits up to a short timeout for the rest of an escape sequence. If the timeout passes,
+// the read stays in progress and its bytes are returned by the next read, thus the key reader must not be dropped
+// while further input is expected, e.g. the event loop keeps it until the input of the console application changes.
+//
+// Parameters:
+//   - `input` : Reader that provides the bytes of the keys
+//
+// Returns:
+//   - `io.Reader` : Reader that returns one key per read
+func NewKeyReader(input io.Reader) io.Reader {
+	if kr, ok := input.(*keyReader); ok {
+		return kr
+	}
+	return &keyReader{input: input}
+}
+
+// Read reads the next key, it implements io.Reader.
+//
+// Parameters:
+//   - `p` : Buffer the key is read into, a key that does not fit is returned over several reads
+//
+// Returns:
+//   - `int` : Number of bytes of the key
+//   - `error` : The error of the input once all keys were returned
+func (kr *keyReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	// A key that was split between two reads is completed with the next read, the rest of an escape sequence has
+	// to arrive within the escape timeout, since the Escape key sends ESC on its own. A read that returns nothing
+	// but ESC is the Escape key, like a terminal delivers it.
+	for kr.err == nil && (len(kr.pending) == 0 || !isCompleteKey(kr.pending)) {
+		if len(kr.pending) == 1 && kr.pending[0] == KEY_ESCAPE[0] && kr.lastReadLength == 1 {
+			break
+		}
+		if !kr.readMore(len(kr.pending) > 0 && kr.pending[0] == KEY_ESCAPE[0]) {
+			break
+		}
+	}
+	if len(kr.pending) == 0 {
+		return 0, kr.err
+	}
+	n := copy(p, kr.pending[:keyLength(kr.pending)])
+	kr.pending = kr.pending[n:]
+	return n, nil
+}
+
+// readMore appends the bytes of the next read to the pending bytes. A read that outlasts the timeout is kept in
+// progress and its result is appended by the next call.
+//
+// Parameters:
+//   - `timeout` : True to stop waiting for the read once the escape timeout passed
+//
+// Returns:
+//   - `bool` : False if the timeout passed before the read returned
+func (kr *keyReader) readMore(timeout bool) bool {
+	if kr.reads == nil {
+		if !timeout {
+			chunk := make([]byte, readChunkSize)
+			n, err := kr.input.Read(chunk)
+			kr.pending, kr.err, kr.lastReadLength = append(kr.pending, chunk[:n]...), err, n
+			return true
+		}
+		kr.reads = make(chan readResult, 1)
+		go func(reads chan<- readResult) {
+			chunk := make([]byte, readChunkSize)
+			n, err := kr.input.Read(chunk)
+			reads <- readResult{chunk: chunk, n: n, err: err}
+		}(kr.reads)
+	}
+
+	var timer <-chan time.Time
+	if timeout {
+		timer = time.After(escapeTimeout)
+	}
+	select {
+	case result := <-kr.reads:
+		kr.reads = nil
+		kr.pending, kr.err, kr.lastReadLength = append(kr.pending, result.chunk[:result.n]...), result.err, result.n
+		return true
+	case <-timer:
+		return false
+	}
+}
+
+// isCompleteKey returns whether the data starts with a complete key. A lone ESC is incomplete, since it might be
+// the start of an escape sequence that was split between two reads.
+//
+// Parameters:
+//   - `data` : Bytes that start with a key, at least one byte
+//
+// Returns:
+//   - `bool` : False if the character or the escape sequence the data starts with is incomplete
+func isCompleteKey(data []byte) bool {
+	if data[0] != KEY_ESCAPE[0] {
+		return utf8.FullRune(data)
+	}
+	if len(data) < 2 {
+		return false
+	}
+	switch data[1] {
+	case '[':
+		_, complete := controlSequenceLength(data)
+		return complete
+	case 'O':
+		// SS3 sequences like ESC O A end with a single final byte
+		return len(data) >= 3
+	case KEY_ESCAPE[0]:
+		return true
+	}
+	return utf8.FullRune(data[1:])
+}
+
+// keyLength returns the number of bytes of the first key in the data.
+//
+// Parameters:
+//   - `data` : Bytes that start with a key, at least one byte
+//
+// Returns:
+//   - `int` : Number of bytes of the key
+func keyLength(data []byte) int {
+	if data[0] != KEY_ESCAPE[0] || len(data) == 1 {
+		_, size := utf8.DecodeRune(data)
+		return size
+	}
+	switch data[1] {
+	case '[':
+		length, _ := controlSequenceLength(data)
+		return length
+	case 'O':
+		return min(3, len(data))
+	case KEY_ESCAPE[0]:
+		return 1
+	}
+	// Keys pressed with Alt are prefixed with ESC
+	_, size := utf8.DecodeRune(data[1:])
+	return 1 + size
+}
+
+// controlSequenceLength returns the number of bytes of the control sequence the data starts with, control
+// sequences end with their final byte, e.g. ESC [ 5 ~.
+//
+// Parameters:
+//   - `data` : Bytes that start with ESC [
+//
+// Returns:
+//   - `int` : Number of bytes of the control sequence, all bytes if it is incomplete
+//   - `bool` : False if the final byte is missing
+func controlSequenceLength(data []byte) (int, bool) {
+	for i := 2; i < len(data); i++ {
+		if data[i] >= 0x40 && data[i] <= 0x7e {
+			return i + 1, true
+		}
+	}
+	return len(data), false
+}
@@ -0,0 +1,370 @@
+package cyclecmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// DEFAULT_MODE is the mode of the key bindings that are bound in the event registry of the console app, the
+// key bindings of all other modes are bound in the event registries of the modes, see SetModeEventRegistry.
+const DEFAULT_MODE string = "default"
+
+// ignoreEventName is the name of the default event of the event registries that are created for modes.
+const ignoreEventName = "Ignore"
+
+// EventCatalog contains the events that can be bound to keys by their name, e.g. in a keybinding file.
+type EventCatalog struct {
+	// events maps the name of an event to the event
+	events map[string]EventInformation
+}
+
+// NewEventCatalog initialises an event catalog that contains the events.
+//
+// Parameters:
+//   - `eventInformations` : Events that can be bound to keys
+//
+// Returns:
+//   - `*EventCatalog` : Returns an instance of the event catalog
+func NewEventCatalog(eventInformations ...EventInformation) *EventCatalog {
+	eventCatalog := &EventCatalog{events: make(map[string]EventInformation)}
+	for _, eventInformation := range eventInformations {
+		eventCatalog.events[eventInformation.EventName] = eventInformation
+	}
+	return eventCatalog
+}
+
+// Add adds an event to the catalog.
+//
+// Parameters:
+//   - `eventInformation` : Event that can be bound to keys by its name
+//
+// Returns:
+//   - `error` : Returns an error when the event has no name or no handler or an event with the name was added
+func (ec *EventCatalog) Add(eventInformation EventInformation) error {
+	if eventInformation.EventName == "" || eventInformation.Event == nil {
+		return fmt.Errorf("event needs a name and an event handler to be added to the catalog")
+	}
+	if _, ok := ec.events[eventInformation.EventName]; ok {
+		return fmt.Errorf("event %v is already in the catalog", eventInformation.EventName)
+	}
+	ec.events[eventInformation.EventName] = eventInformation
+	return nil
+}
+
+// AddEventRegistry adds the default event and all events that are registered in the event registry, e.g. the
+// events of a LineEditor. Events whose name is already in the catalog are skipped.
+//
+// Parameters:
+//   - `eventRegistry` : Event registry whose events can be bound to keys
+func (ec *EventCatalog) AddEventRegistry(eventRegistry *EventRegistry) {
+	eventInformations := []EventInformation{eventRegistry.DefaultEventInformation}
+	for _, eventInformation := range eventRegistry.registry {
+		eventInformations = append(eventInformations, eventInformation)
+	}
+	for _, eventInformation := range eventInformations {
+		if _, ok := ec.events[eventInformation.EventName]; ok || eventInformation.Event == nil {
+			continue
+		}
+		ec.events[eventInformation.EventName] = eventInformation
+	}
+}
+
+// Lookup returns the event with the name.
+//
+// Parameters:
+//   - `eventName` : Name of the event
+//
+// Returns:
+//   - `EventInformation` : The event
+//   - `bool` : False if the catalog contains no event with the name
+func (ec *EventCatalog) Lookup(eventName string) (EventInformation, bool) {
+	eventInformation, ok := ec.events[eventName]
+	return eventInformation, ok
+}
+
+// Names returns the names of all events in the catalog sorted alphabetically.
+//
+// Returns:
+//   - `[]string` : Names of the events
+func (ec *EventCatalog) Names() []string {
+	names := make([]string, 0, len(ec.events))
+	for name := range ec.events {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// KeyBindings map key sequences to the names of events per mode. Key sequences are key names separated by spaces,
+// e.g. `ctrl+s` or `g g`, see ParseKey. An empty event name unbinds the key sequence. In a YAML file:
+//
+//	default:
+//	  ctrl+s: Save
+//	normal:
+//	  g g: GoToTop
+type KeyBindings map[string]map[string]string
+
+// LoadKeyBindings reads key bindings from a YAML, JSON or TOML file, the format is determined by the extension.
+//
+// Parameters:
+//   - `path` : Path of the keybinding file
+//
+// Returns:
+//   - `KeyBindings` : The key bindings of the file
+//   - `error` : Returns an error when the file could not be read or is invalid
+func LoadKeyBindings(path string) (KeyBindings, error) {
+	format, err := ConfigFormatOf(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("keybinding file could not be read! error: %w", err)
+	}
+	keyBindings, err := ParseKeyBindings(data, format)
+	if err != nil {
+		return nil, fmt.Errorf("keybinding file %v is invalid! error: %w", path, err)
+	}
+	return keyBindings, nil
+}
+
+// ParseKeyBindings parses key bindings in the format.
+//
+// Parameters:
+//   - `data` : Content of a keybinding file
+//   - `format` : Format of the content
+//
+// Returns:
+//   - `KeyBindings` : The key bindings
+//   - `error` : Returns an error when the content is invalid
+func ParseKeyBindings(data []byte, format ConfigFormat) (KeyBindings, error) {
+	keyBindings := KeyBindings{}
+	if err := decodeConfig(data, format, &keyBindings); err != nil {
+		return nil, err
+	}
+	return keyBindings, nil
+}
+
+// Merge merges the overrides over the key bindings, e.g. the keybinding file of the user over the default key
+// bindings of the console application. A key sequence of the overrides replaces the same key sequence of the key
+// bindings even if it is spelled differently, e.g. `Ctrl+S` replaces `ctrl+s`. Neither key bindings are changed.
+//
+// Parameters:
+//   - `overrides` : Key bindings that take precedence
+//
+// Returns:
+//   - `KeyBindings` : The merged key bindings
+func (kb KeyBindings) Merge(overrides KeyBindings) KeyBindings {
+	merged := KeyBindings{}
+	for mode, bindings := range kb {
+		merged[mode] = make(map[string]string, len(bindings))
+		for keys, eventName := range bindings {
+			merged[mode][keys] = eventName
+		}
+	}
+	for mode, bindings := range overrides {
+		if merged[mode] == nil {
+			merged[mode] = make(map[string]string, len(bindings))
+		}
+		for keys, eventName := range bindings {
+			if eventTriggers, err := parseKeySequence(keys); err == nil {
+				sequence := strings.Join(eventTriggers, sequenceSeparator)
+				for mergedKeys := range merged[mode] {
+					mergedEventTriggers, err := parseKeySequence(mergedKeys)
+					if err == nil && strings.Join(mergedEventTriggers, sequenceSeparator) == sequence {
+						delete(merged[mode], mergedKeys)
+					}
+				}
+			}
+			merged[mode][keys] = eventName
+		}
+	}
+	return merged
+}
+
+// Validate checks that all key sequences are valid, all events are in the catalog and no bindings conflict.
+// Bindings conflict if two of them bind the same key sequence in a mode or if a key sequence is the start of
+// another key sequence of the mode, since the longer key sequence could never be completed.
+//
+// Parameters:
+//   - `catalog` : Catalog of the events that can be bound
+//
+// Returns:
+//   - `error` : Returns an error that lists all problems, nil if the key bindings are valid
+func (kb KeyBindings) Validate(catalog *EventCatalog) error {
+	var errs []error
+	for _, mode := range sortedKeys(kb) {
+		if mode == "" {
+			errs = append(errs, fmt.Errorf("mode name must not be empty"))
+			continue
+		}
+		// sequences maps the bound key sequences to the keys they were spelled with
+		sequences := make(map[string]string)
+		for _, keys := range sortedKeys(kb[mode]) {
+			eventName := kb[mode][keys]
+			eventTriggers, err := parseKeySequence(keys)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("keys %q of mode %v are invalid! error: %w", keys, mode, err))
+				continue
+			}
+			if _, ok := catalog.Lookup(eventName); !ok && eventName != "" {
+				errs = append(errs, fmt.Errorf("event %v bound to %q in mode %v is not in the catalog", eventName, keys, mode))
+			}
+			sequence := strings.Join(eventTriggers, sequenceSeparator)
+			if otherKeys, ok := sequences[sequence]; ok {
+				errs = append(errs, fmt.Errorf("keys %q and %q of mode %v are the same key sequence", otherKeys, keys, mode))
+				continue
+			}
+			if eventName != "" {
+				sequences[sequence] = keys
+			}
+		}
+		for _, sequence := range sortedKeys(sequences) {
+			for _, otherSequence := range sortedKeys(sequences) {
+				if strings.HasPrefix(otherSequence, sequence+sequenceSeparator) {
+					errs = append(errs, fmt.Errorf("keys %q of mode %v conflict with %q, they start the longer key sequence", sequences[sequence], mode, sequences[otherSequence]))
+				}
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// InstallKeyBindings validates the key bindings and binds them. The key bindings of DEFAULT_MODE are bound in the
+// event registry of the console app, the key bindings of other modes in the event registries of the modes. An event
+// registry is created for a mode that has none, it ignores all tokens that are not bound. Bindings replace the
+// events that were registered under the same key sequences before. Nothing is bound if the key bindings are invalid.
+//
+// Parameters:
+//   - `keyBindings` : Key bindings, usually the defaults merged with the keybinding file of the user
+//   - `catalog` : Catalog of the events that can be bound
+//
+// Returns:
+//   - `error` : Returns an error when the key bindings are invalid, see KeyBindings.Validate
+func (ca *ConsoleApp) InstallKeyBindings(keyBindings KeyBindings, catalog *EventCatalog) error {
+	if err := keyBindings.Validate(catalog); err != nil {
+		return fmt.Errorf("key bindings could not be installed! error: %w", err)
+	}
+	ca.installKeyBindings(keyBindings, catalog)
+	return nil
+}
+
+// keyBindingSnapshot records what key bindings replaced when they were installed, so that the installation can be
+// reverted, e.g. before the key bindings of a changed keybinding file are installed.
+type keyBindingSnapshot struct {
+	// replacedBindings are the bindings in the order they were replaced
+	replacedBindings []replacedBinding
+	// createdModes are the modes whose event registries were created by the installation
+	createdModes []string
+}
+
+// replacedBinding is the binding of a key sequence before key bindings were installed.
+type replacedBinding struct {
+	eventRegistry *EventRegistry
+	eventTriggers []string
+	// eventInformation is the event that was bound, it is only valid if registered is true
+	eventInformation EventInformation
+	registered       bool
+}
+
+// installKeyBindings binds key bindings that were validated.
+//
+// Parameters:
+//   - `keyBindings` : Key bindings that are valid for the catalog
+//   - `catalog` : Catalog of the events that can be bound
+//
+// Returns:
+//   - `*keyBindingSnapshot` : Snapshot that reverts the installation, see revertKeyBindings
+func (ca *ConsoleApp) installKeyBindings(keyBindings KeyBindings, catalog *EventCatalog) *keyBindingSnapshot {
+	snapshot := &keyBindingSnapshot{}
+	for _, mode := range sortedKeys(keyBindings) {
+		eventRegistry := ca.eventRegistry
+		if mode != DEFAULT_MODE {
+			eventRegistry = ca.ModeEventRegistry(mode)
+			if eventRegistry == nil {
+				eventRegistry = NewEventRegistry(EventInformation{EventName: ignoreEventName, Event: &nonDefaultEvent{}})
+				ca.SetModeEventRegistry(mode, eventRegistry)
+				snapshot.createdModes = append(snapshot.createdModes, mode)
+			}
+		}
+		for _, keys := range sortedKeys(keyBindings[mode]) {
+			eventTriggers, _ := parseKeySequence(keys)
+			eventInformation, registered := eventRegistry.registry[strings.Join(eventTriggers, sequenceSeparator)]
+			snapshot.replacedBindings = append(snapshot.replacedBindings, replacedBinding{
+				eventRegistry:    eventRegistry,
+				eventTriggers:    eventTriggers,
+				eventInformation: eventInformation,
+				registered:       registered,
+			})
+			if eventName := keyBindings[mode][keys]; eventName != "" {
+				eventInformation, _ := catalog.Lookup(eventName)
+				eventRegistry.bind(eventTriggers, eventInformation)
+				continue
+			}
+			eventRegistry.unbind(eventTriggers)
+		}
+	}
+	return snapshot
+}
+
+// revertKeyBindings restores the bindings that were replaced by the installation of key bindings and removes
+// the event registries it created.
+//
+// Parameters:
+//   - `snapshot` : Snapshot that was taken by installKeyBindings
+func (ca *ConsoleApp) revertKeyBindings(snapshot *keyBindingSnapshot) {
+	for i := len(snapshot.replacedBindings) - 1; i >= 0; i-- {
+		replacedBinding := snapshot.replacedBindings[i]
+		if replacedBinding.registered {
+			replacedBinding.eventRegistry.bind(replacedBinding.eventTriggers, replacedBinding.eventInformation)
+			continue
+		}
+		replacedBinding.eventRegistry.unbind(replacedBinding.eventTriggers)
+	}
+	for _, mode := range snapshot.createdModes {
+		ca.SetModeEventRegistry(mode, nil)
+	}
+}
+
+// parseKeySequence parses key names separated by spaces into the tokens of the keys.
+//
+// Parameters:
+//   - `keys` : Key names separated by spaces, e.g. `g g`
+//
+// Returns:
+//   - `[]string` : Tokens of the keys
+//   - `error` : Returns an error when the sequence is empty or a key is unknown
+func parseKeySequence(keys string) ([]string, error) {
+	keyNames := strings.Fields(keys)
+	if len(keyNames) == 0 {
+		return nil, fmt.Errorf("key sequence is empty")
+	}
+	eventTriggers := make([]string, 0, len(keyNames))
+	for _, keyName := range keyNames {
+		eventTrigger, err := ParseKey(keyName)
+		if err != nil {
+			return nil, err
+		}
+		eventTriggers = append(eventTriggers, eventTrigger)
+	}
+	return eventTriggers, nil
+}
+
+// sortedKeys returns the keys of the map sorted alphabetically.
+//
+// Parameters:
+//   - `m` : Map whose keys are returned
+//
+// Returns:
+//   - `[]string` : Sorted keys
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,187 @@
+//go:build unit_test
+
+package cyclecmd_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/RaphSku/cyclecmd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type RecordingEvent struct {
+	name    string
+	handled *[]string
+}
+
+func (re *RecordingEvent) Handle(token string) (error, *cyclecmd.ControlEvent) {
+	*re.handled = append(*re.handled, re.name)
+	return nil, nil
+}
+
+type ModeEvent struct {
+	consoleApp *cyclecmd.ConsoleApp
+	mode       string
+}
+
+func (me *ModeEvent) Handle(token string) (error, *cyclecmd.ControlEvent) {
+	me.consoleApp.SetMode(me.mode)
+	return nil, nil
+}
+
+func setupEventCatalog(handled *[]string, names ...string) *cyclecmd.EventCatalog {
+	eventCatalog := cyclecmd.NewEventCatalog()
+	for _, name := range names {
+		eventCatalog.Add(cyclecmd.EventInformation{EventName: name, Event: &RecordingEvent{name: name, handled: handled}})
+	}
+	return eventCatalog
+}
+
+func TestParseKeyBindings(t *testing.T) {
+	t.Parallel()
+
+	expKeyBindings := cyclecmd.KeyBindings{
+		"default": {"ctrl+s": "Save"},
+		"normal":  {"g g": "GoToTop", "x": ""},
+	}
+	files := map[string]string{
+		"keys.yaml": "default:\n  ctrl+s: Save\nnormal:\n  g g: GoToTop\n  x: \"\"\n",
+		"keys.json": `{"default": {"ctrl+s": "Save"}, "normal": {"g g": "GoToTop", "x": ""}}`,
+		"keys.toml": "[default]\n\"ctrl+s\" = \"Save\"\n\n[normal]\n\"g g\" = \"GoToTop\"\nx = \"\"\n",
+	}
+	directory := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(directory, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+		actKeyBindings, err := cyclecmd.LoadKeyBindings(path)
+		require.NoError(t, err, name)
+		assert.Equal(t, expKeyBindings, actKeyBindings, name)
+	}
+
+	_, err := cyclecmd.LoadKeyBindings(filepath.Join(directory, "keys.ini"))
+	assert.Error(t, err)
+
+	_, err = cyclecmd.LoadKeyBindings(filepath.Join(directory, "missing.yaml"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	_, err = cyclecmd.ParseKeyBindings([]byte("default: [Save]\n"), cyclecmd.CONFIG_FORMAT_YAML)
+	assert.Error(t, err)
+}
+
+func TestKeyBindingsMerge(t *testing.T) {
+	t.Parallel()
+
+	defaults := cyclecmd.KeyBindings{
+		"default": {"ctrl+s": "Save", "ctrl+q": "Quit"},
+		"normal":  {"g g": "GoToTop"},
+	}
+	overrides := cyclecmd.KeyBindings{
+		"default": {"Ctrl+S": "SaveAll", "ctrl+q": ""},
+		"insert":  {"esc": "Normal"},
+	}
+
+	merged := defaults.Merge(overrides)
+
+	expKeyBindings := cyclecmd.KeyBindings{
+		"default": {"Ctrl+S": "SaveAll", "ctrl+q": ""},
+		"normal":  {"g g": "GoToTop"},
+		"insert":  {"esc": "Normal"},
+	}
+	assert.Equal(t, expKeyBindings, merged)
+	assert.Equal(t, "Save", defaults["default"]["ctrl+s"])
+}
+
+func TestKeyBindingsValidate(t *testing.T) {
+	t.Parallel()
+
+	eventCatalog := setupEventCatalog(&[]string{}, "Save", "GoToTop", "Delete")
+
+	validKeyBindings := cyclecmd.KeyBindings{
+		"default": {"ctrl+s": "Save"},
+		"normal":  {"g g": "GoToTop", "d d": "Delete", "g": ""},
+	}
+	assert.NoError(t, validKeyBindings.Validate(eventCatalog))
+
+	invalidKeyBindings := cyclecmd.KeyBindings{
+		"default": {"ctrl+s": "Save", "Ctrl+S": "Save", "hyper+x": "Save"},
+		"normal":  {"g g": "GoToTop", "g": "Delete", "d d": "Unknown"},
+	}
+	err := invalidKeyBindings.Validate(eventCatalog)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `keys "Ctrl+S" and "ctrl+s" of mode default are the same key sequence`)
+	assert.Contains(t, err.Error(), `keys "hyper+x" of mode default are invalid`)
+	assert.Contains(t, err.Error(), `event Unknown bound to "d d" in mode normal is not in the catalog`)
+	assert.Contains(t, err.Error(), `keys "g" of mode normal conflict with "g g"`)
+}
+
+func TestEventCatalog(t *testing.T) {
+	t.Parallel()
+
+	eventRegistry := cyclecmd.NewEventRegistry(setupDefaultEventInformation())
+	require.NoError(t, eventRegistry.RegisterEvent("t", cyclecmd.EventInformation{EventName: "Test", Event: &TestEvent{}}))
+	eventCatalog := setupEventCatalog(&[]string{}, "Save")
+	eventCatalog.AddEventRegistry(eventRegistry)
+
+	assert.Equal(t, []string{"Default", "Save", "Test"}, eventCatalog.Names())
+	_, ok := eventCatalog.Lookup("Test")
+	assert.True(t, ok)
+	assert.Error(t, eventCatalog.Add(cyclecmd.EventInformation{EventName: "Save", Event: &TestEvent{}}))
+	assert.Error(t, eventCatalog.Add(cyclecmd.EventInformation{EventName: "Empty"}))
+}
+
+func TestInstallKeyBindings(t *testing.T) {
+	t.Parallel()
+
+	var handled []string
+	eventRegistry := cyclecmd.NewEventRegistry(setupDefaultEventInformation())
+	consoleApp := cyclecmd.NewConsoleApp("test", "0.1.0", "This is a test console application", eventRegistry, cyclecmd.NewEventHistory())
+	consoleApp.SetOutput(&bytes.Buffer{})
+	require.NoError(t, eventRegistry.RegisterEvent("\x11", cyclecmd.EventInformation{EventName: "Quit", Event: &RecordingEvent{name: "Quit", handled: &handled}}))
+
+	eventCatalog := setupEventCatalog(&handled, "Save", "GoToTop")
+	require.NoError(t, eventCatalog.Add(cyclecmd.EventInformation{EventName: "Normal", Event: &ModeEvent{consoleApp: consoleApp, mode: "normal"}}))
+	defaults := cyclecmd.KeyBindings{
+		"default": {"ctrl+s": "Save", "esc": "Normal"},
+	}
+	userKeyBindings := cyclecmd.KeyBindings{
+		"default": {"ctrl+q": ""},
+		"normal":  {"g g": "GoToTop", "s": "Save"},
+	}
+
+	err := consoleApp.InstallKeyBindings(defaults.Merge(cyclecmd.KeyBindings{"default": {"ctrl+s": "Unknown"}}), eventCatalog)
+	assert.Error(t, err)
+	assert.Nil(t, consoleApp.ModeEventRegistry("normal"))
+
+	require.NoError(t, consoleApp.InstallKeyBindings(defaults.Merge(userKeyBindings), eventCatalog))
+	require.NotNil(t, consoleApp.ModeEventRegistry("normal"))
+
+	consoleApp.SetInput(newTokenReader("\x13", "\x11", "\x1b", "g", "x", "g", "g", "s"))
+	consoleApp.Start()
+
+	assert.Equal(t, []string{"Save", "GoToTop", "Save"}, handled)
+}
+
+func TestRegisterEventSequence(t *testing.T) {
+	t.Parallel()
+
+	var handled []string
+	eventRegistry := cyclecmd.NewEventRegistry(setupDefaultEventInformation())
+	consoleApp := cyclecmd.NewConsoleApp("test", "0.1.0", "This is a test console application", eventRegistry, cyclecmd.NewEventHistory())
+	consoleApp.SetOutput(&bytes.Buffer{})
+	eventInformation := cyclecmd.EventInformation{EventName: "Delete", Event: &RecordingEvent{name: "Delete", handled: &handled}}
+
+	assert.Error(t, eventRegistry.RegisterEventSequence(nil, eventInformation))
+	require.NoError(t, eventRegistry.RegisterEventSequence([]string{"d", "d"}, eventInformation))
+	assert.Error(t, eventRegistry.RegisterEventSequence([]string{"d", "d"}, eventInformation))
+	require.NoError(t, eventRegistry.RegisterEventSequence([]string{"x"}, eventInformation))
+
+	consoleApp.SetInput(newTokenReader("d", "a", "d", "d", "x"))
+	consoleApp.Start()
+
+	assert.Equal(t, []string{"Delete", "Delete"}, handled)
+}
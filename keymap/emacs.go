@@ -0,0 +1,52 @@
+package keymap
+
+import "github.com/RaphSku/cyclecmd"
+
+// Emacs returns the keymap of readline in its default emacs editing mode, all keys are bound in
+// cyclecmd.DEFAULT_MODE:
+//   - `ctrl+b`, `ctrl+f`, `alt+b`, `alt+f`, `ctrl+a`, `ctrl+e` : Moves the cursor by characters, words or to the
+//     start and the end of the line, the arrow keys, Home and End move the cursor as well
+//   - `ctrl+d`, `ctrl+h`, `alt+d`, `ctrl+w`, `ctrl+k`, `ctrl+u` : Deletes characters, kills words or the text
+//     after or in front of the cursor
+//   - `ctrl+y` : Yanks the text that was killed last
+//   - `ctrl+p`, `ctrl+n` : Browses the submitted lines, just like the arrow keys Up and Down
+//   - `ctrl+r` : Starts the reverse incremental history search
+//
+// Returns:
+//   - `Keymap` : The emacs keymap
+func Emacs() Keymap {
+	return Keymap{
+		Name: "emacs",
+		KeyBindings: cyclecmd.KeyBindings{
+			cyclecmd.DEFAULT_MODE: {
+				"enter":         "Submit",
+				"tab":           "Complete",
+				"backspace":     "Backspace",
+				"ctrl+h":        "Backspace",
+				"ctrl+d":        "Delete",
+				"delete":        "Delete",
+				"ctrl+b":        "CursorLeft",
+				"left":          "CursorLeft",
+				"ctrl+f":        "CursorRight",
+				"right":         "CursorRight",
+				"ctrl+a":        "CursorStart",
+				"home":          "CursorStart",
+				"ctrl+e":        "CursorEnd",
+				"end":           "CursorEnd",
+				"alt+b":         "WordBackward",
+				"alt+f":         "WordForward",
+				"ctrl+w":        "DeleteWordBackward",
+				"alt+backspace": "DeleteWordBackward",
+				"alt+d":         "DeleteWordForward",
+				"ctrl+k":        "KillToEnd",
+				"ctrl+u":        "KillToStart",
+				"ctrl+y":        "Yank",
+				"ctrl+p":        "HistoryPrevious",
+				"up":            "HistoryPrevious",
+				"ctrl+n":        "HistoryNext",
+				"down":          "HistoryNext",
+				"ctrl+r":        "ReverseSearch",
+			},
+		},
+	}
+}
@@ -0,0 +1,177 @@
+// Package keymap provides presets of key bindings for the line editor of cyclecmd, so that console applications
+// built with cyclecmd feel consistent. Emacs binds the keys of readline, Vi binds the keys of vi with an insert,
+// a normal and a visual mode. A keymap is installed with a single call and can be overridden by key bindings,
+// e.g. from the keybinding file of the user:
+//
+//	keymap.Vi().Install(consoleApp, lineEditor, nil)
+package keymap
+
+import (
+	"fmt"
+
+	"github.com/RaphSku/cyclecmd"
+)
+
+// Keymap is a preset of key bindings for the events of a cyclecmd.LineEditor and the mode events of the keymap
+// package, see Catalog.
+type Keymap struct {
+	// Name of the keymap, e.g. "emacs"
+	Name string
+	// DefaultMode is the mode whose key bindings are bound in the event registry of the console application,
+	// the console application is set to this mode once the keymap is installed
+	DefaultMode string
+	// KeyBindings of the keymap per mode
+	KeyBindings cyclecmd.KeyBindings
+}
+
+// modeEvent handles events of the line editor one after another and sets the mode of the console application
+// afterwards, e.g. to leave the insert mode of vi.
+type modeEvent struct {
+	consoleApp *cyclecmd.ConsoleApp
+	events     []cyclecmd.Event
+	mode       string
+	// modeFirst sets the mode before the events are handled, so that it is set even if an event fails
+	modeFirst bool
+}
+
+// Handle handles the events with the token and sets the mode, the mode stays unchanged if an event fails unless
+// it is set first.
+//
+// Parameters:
+//   - `token` : Token that triggered the event
+//
+// Returns:
+//   - `error` : Error returned by the first event that failed
+//   - `*ControlEvent` : Control event returned by the first event that returned one
+func (me *modeEvent) Handle(token string) (error, *cyclecmd.ControlEvent) {
+	if me.modeFirst {
+		me.consoleApp.SetMode(me.mode)
+	}
+	for _, event := range me.events {
+		if err, controlEvent := event.Handle(token); err != nil || controlEvent != nil {
+			return err, controlEvent
+		}
+	}
+	me.consoleApp.SetMode(me.mode)
+	return nil, nil
+}
+
+// Catalog returns a catalog of the events of the line editor, see LineEditor.EventCatalog, and of the mode events
+// the keymaps bind:
+//   - `InsertMode`, `AppendMode` : Enters the insert mode of vi in front of or after the cursor
+//   - `InsertAtStartMode`, `AppendAtEndMode` : Enters the insert mode at the start or the end of the line
+//   - `ChangeLine`, `ChangeToEnd`, `Substitute` : Kills the line, the text after the cursor or the character
+//     under the cursor and enters the insert mode
+//   - `NormalMode` : Enters the normal mode of vi, the cursor moves onto the last inserted character
+//   - `VisualMode` : Enters the visual mode of vi and starts selecting text
+//   - `ExitVisualMode`, `VisualDelete`, `VisualYank` : Clears, kills or copies the selection and enters the
+//     normal mode
+//   - `YankAfter` : Inserts the text that was killed last after the character under the cursor, `Yank` inserts it
+//     in front of it
+//   - `SubmitInsertMode` : Submits the line and enters the insert mode of vi for the next line, even if the
+//     submitted line failed
+//
+// The catalog can be used for keybinding files, see ConsoleApp.WatchKeyBindings.
+//
+// Parameters:
+//   - `consoleApp` : Console application whose mode is set
+//   - `lineEditor` : Line editor whose events are bound
+//
+// Returns:
+//   - `*cyclecmd.EventCatalog` : Catalog of the events
+func Catalog(consoleApp *cyclecmd.ConsoleApp, lineEditor *cyclecmd.LineEditor) *cyclecmd.EventCatalog {
+	eventCatalog := lineEditor.EventCatalog()
+	lineEditorEvents := func(eventNames ...string) []cyclecmd.Event {
+		events := make([]cyclecmd.Event, 0, len(eventNames))
+		for _, eventName := range eventNames {
+			eventInformation, _ := eventCatalog.Lookup(eventName)
+			events = append(events, eventInformation.Event)
+		}
+		return events
+	}
+	modeEvents := []struct {
+		eventName  string
+		eventNames []string
+		mode       string
+		modeFirst  bool
+	}{
+		{"InsertMode", nil, VI_INSERT_MODE, false},
+		{"AppendMode", []string{"CursorRight"}, VI_INSERT_MODE, false},
+		{"InsertAtStartMode", []string{"CursorStart"}, VI_INSERT_MODE, false},
+		{"AppendAtEndMode", []string{"CursorEnd"}, VI_INSERT_MODE, false},
+		{"ChangeLine", []string{"KillLine"}, VI_INSERT_MODE, false},
+		{"ChangeToEnd", []string{"KillToEnd"}, VI_INSERT_MODE, false},
+		{"Substitute", []string{"Delete"}, VI_INSERT_MODE, false},
+		{"SubmitInsertMode", []string{"Submit"}, VI_INSERT_MODE, true},
+		{"NormalMode", []string{"CursorLeft"}, VI_NORMAL_MODE, false},
+		{"YankAfter", []string{"CursorRight", "Yank"}, VI_NORMAL_MODE, false},
+		{"VisualMode", []string{"StartSelection"}, VI_VISUAL_MODE, false},
+		{"ExitVisualMode", []string{"ClearSelection"}, VI_NORMAL_MODE, false},
+		{"VisualDelete", []string{"DeleteSelection"}, VI_NORMAL_MODE, false},
+		{"VisualYank", []string{"CopySelection"}, VI_NORMAL_MODE, false},
+	}
+	for _, event := range modeEvents {
+		_ = eventCatalog.Add(cyclecmd.EventInformation{
+			EventName: event.eventName,
+			Event: &modeEvent{
+				consoleApp: consoleApp,
+				events:     lineEditorEvents(event.eventNames...),
+				mode:       event.mode,
+				modeFirst:  event.modeFirst,
+			},
+		})
+	}
+	return eventCatalog
+}
+
+// Resolve returns the key bindings of the keymap merged with the overrides, the key bindings of the default
+// mode are moved to cyclecmd.DEFAULT_MODE, so that they are bound in the event registry of the console
+// application. Overrides can address the default mode by its name, e.g. `insert` for vi.
+//
+// Parameters:
+//   - `overrides` : Key bindings that take precedence, an empty event name unbinds a key, might be nil
+//
+// Returns:
+//   - `cyclecmd.KeyBindings` : The merged key bindings
+func (km Keymap) Resolve(overrides cyclecmd.KeyBindings) cyclecmd.KeyBindings {
+	return km.resolveDefaultMode(km.KeyBindings).Merge(km.resolveDefaultMode(overrides))
+}
+
+// Install binds the key bindings of the keymap merged with the overrides, see Resolve, and sets the console
+// application to the default mode of the keymap. The events of the line editor have to be registered in the
+// event registry of the console application before, see LineEditor.RegisterEvents.
+//
+// Parameters:
+//   - `consoleApp` : Console application the keymap is installed in
+//   - `lineEditor` : Line editor whose events are bound
+//   - `overrides` : Key bindings that take precedence, an empty event name unbinds a key, might be nil
+//
+// Returns:
+//   - `error` : Returns an error when the overrides are invalid, in that case nothing is bound
+func (km Keymap) Install(consoleApp *cyclecmd.ConsoleApp, lineEditor *cyclecmd.LineEditor, overrides cyclecmd.KeyBindings) error {
+	if err := consoleApp.InstallKeyBindings(km.Resolve(overrides), Catalog(consoleApp, lineEditor)); err != nil {
+		return fmt.Errorf("keymap %v could not be installed! error: %w", km.Name, err)
+	}
+	if km.DefaultMode != "" {
+		consoleApp.SetMode(km.DefaultMode)
+	}
+	return nil
+}
+
+// resolveDefaultMode moves the key bindings of the default mode to cyclecmd.DEFAULT_MODE.
+//
+// Parameters:
+//   - `keyBindings` : Key bindings whose modes are resolved
+//
+// Returns:
+//   - `cyclecmd.KeyBindings` : Key bindings with the default mode resolved
+func (km Keymap) resolveDefaultMode(keyBindings cyclecmd.KeyBindings) cyclecmd.KeyBindings {
+	resolvedKeyBindings := cyclecmd.KeyBindings{}
+	for mode, bindings := range keyBindings {
+		if mode == km.DefaultMode {
+			mode = cyclecmd.DEFAULT_MODE
+		}
+		resolvedKeyBindings = resolvedKeyBindings.Merge(cyclecmd.KeyBindings{mode: bindings})
+	}
+	return resolvedKeyBindings
+}
@@ -0,0 +1,153 @@
+//go:build unit_test
+
+package keymap_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/RaphSku/cyclecmd"
+	"github.com/RaphSku/cyclecmd/cyclecmdtest"
+	"github.com/RaphSku/cyclecmd/keymap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type DefaultEvent struct{}
+
+func (de *DefaultEvent) Handle(token string) (error, *cyclecmd.ControlEvent) {
+	return nil, nil
+}
+
+func setupKeymapTerminal(t *testing.T, km keymap.Keymap, overrides cyclecmd.KeyBindings) (*cyclecmd.ConsoleApp, *cyclecmdtest.Expect) {
+	eventRegistry := cyclecmd.NewEventRegistry(cyclecmd.EventInformation{EventName: "Default", Event: &DefaultEvent{}})
+	consoleApp := cyclecmd.NewConsoleApp("test", "0.1.0", "This is a test console application", eventRegistry, cyclecmd.NewEventHistory())
+	consoleApp.DisableBanner()
+	lineEditor := cyclecmd.NewLineEditor(consoleApp, func(line string) (error, *cyclecmd.ControlEvent) {
+		fmt.Fprintf(consoleApp.Output(), "line: [%v]\r\n", line)
+		return nil, nil
+	})
+	require.NoError(t, lineEditor.RegisterEvents(eventRegistry))
+	require.NoError(t, km.Install(consoleApp, lineEditor, overrides))
+
+	terminal := cyclecmdtest.NewTerminal(consoleApp, 80, 24)
+	require.NoError(t, terminal.Start())
+	t.Cleanup(terminal.Close)
+	return consoleApp, cyclecmdtest.NewExpect(t, terminal)
+}
+
+func TestEmacs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		keys    []string
+		expLine string
+	}{
+		{name: "cursor", keys: []string{"b", "c", "ctrl+a", "a", "ctrl+e", "d", "ctrl+b", "ctrl+b", "ctrl+f", "x"}, expLine: "abcxd"},
+		{name: "words", keys: []string{"o", "n", "e", " ", "t", "w", "o", "alt+b", "alt+b", "alt+f", "!"}, expLine: "one! two"},
+		{name: "kill and yank", keys: []string{"a", " ", "b", "ctrl+w", "ctrl+a", "ctrl+y", "ctrl+e", "ctrl+u", "ctrl+y", "ctrl+y"}, expLine: "ba ba "},
+		{name: "kill to end", keys: []string{"a", "b", "c", "ctrl+a", "ctrl+f", "ctrl+k", "ctrl+d", "delete"}, expLine: "a"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, expect := setupKeymapTerminal(t, keymap.Emacs(), nil)
+			expect.SendKeys(append(test.keys, "enter")...)
+			assert.Equal(t, []string{fmt.Sprintf("line: [%v]", test.expLine), test.expLine}, expect.Expect(`line: \[(.*)\]`))
+		})
+	}
+}
+
+func TestEmacsHistory(t *testing.T) {
+	t.Parallel()
+
+	_, expect := setupKeymapTerminal(t, keymap.Emacs(), nil)
+	expect.Send("first\rsecond\r")
+	expect.Expect(`line: \[second\]`)
+
+	expect.SendKeys("d", "r", "a", "f", "t", "ctrl+p", "up", "up", "enter")
+	assert.Equal(t, "first", expect.Expect(`line: \[(.*)\]`)[1])
+
+	expect.SendKeys("x", "up", "up", "down", "ctrl+n", "enter")
+	assert.Equal(t, "x", expect.Expect(`line: \[(.*)\]`)[1])
+}
+
+func TestVi(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		keys    []string
+		expLine string
+		expMode string
+	}{
+		{name: "insert", keys: []string{"a", "b"}, expLine: "ab", expMode: keymap.VI_INSERT_MODE},
+		{name: "normal", keys: []string{"a", "b", "c", "esc", "h", "x", "0", "i", "y"}, expLine: "yac", expMode: keymap.VI_INSERT_MODE},
+		{name: "append", keys: []string{"b", "esc", "I", "a", "esc", "A", "c", "esc", "0", "a", "-"}, expLine: "a-bc", expMode: keymap.VI_INSERT_MODE},
+		{name: "sequences", keys: []string{"o", "n", "e", " ", "t", "w", "o", "esc", "0", "d", "w", "d", "x", "P"}, expLine: "onetwo", expMode: keymap.VI_NORMAL_MODE},
+		{name: "put after", keys: []string{"a", "b", "c", "esc", "h", "D", "0", "p"}, expLine: "abc", expMode: keymap.VI_NORMAL_MODE},
+		{name: "put before", keys: []string{"a", "b", "c", "esc", "h", "D", "0", "P"}, expLine: "bca", expMode: keymap.VI_NORMAL_MODE},
+		{name: "delete line", keys: []string{"a", "b", "esc", "d", "d", "i", "c"}, expLine: "c", expMode: keymap.VI_INSERT_MODE},
+		{name: "change", keys: []string{"a", "b", "c", "esc", "h", "C", "x", "esc", "c", "c", "y"}, expLine: "y", expMode: keymap.VI_INSERT_MODE},
+		{name: "visual", keys: []string{"a", "b", "c", "d", "esc", "0", "v", "l", "d", "$", "p"}, expLine: "cdab", expMode: keymap.VI_NORMAL_MODE},
+		{name: "visual yank", keys: []string{"a", "b", "esc", "v", "h", "y", "$", "p", "v", "esc", "x"}, expLine: "abab", expMode: keymap.VI_NORMAL_MODE},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			consoleApp, expect := setupKeymapTerminal(t, keymap.Vi(), nil)
+			var actMode string
+			consoleApp.BeforeEvent(func(eventHistoryEntry cyclecmd.EventHistoryEntry) *cyclecmd.ControlEvent {
+				if eventHistoryEntry.EventName == "Submit" || eventHistoryEntry.EventName == "SubmitInsertMode" {
+					actMode = consoleApp.Mode()
+				}
+				return nil
+			})
+			expect.SendKeys(append(test.keys, "enter")...)
+			assert.Equal(t, test.expLine, expect.Expect(`line: \[(.*)\]`)[1])
+			assert.Equal(t, test.expMode, actMode)
+		})
+	}
+}
+
+func TestViSubmitEntersInsertMode(t *testing.T) {
+	t.Parallel()
+
+	// In the normal mode, x would delete a character instead of inserting it
+	_, expect := setupKeymapTerminal(t, keymap.Vi(), nil)
+	expect.SendKeys("a", "esc", "enter")
+	assert.Equal(t, "a", expect.Expect(`line: \[(.*)\]`)[1])
+	expect.SendKeys("x", "enter")
+	assert.Equal(t, "x", expect.Expect(`line: \[(.*)\]`)[1])
+}
+
+func TestKeymapOverrides(t *testing.T) {
+	t.Parallel()
+
+	overrides := cyclecmd.KeyBindings{
+		keymap.VI_INSERT_MODE: {"ctrl+a": "", "j k": "NormalMode"},
+		keymap.VI_NORMAL_MODE: {"x": "Backspace"},
+	}
+	_, expect := setupKeymapTerminal(t, keymap.Vi(), overrides)
+	expect.SendKeys("a", "b", "c", "ctrl+a", "j", "k", "x", "enter")
+	assert.Equal(t, "ac", expect.Expect(`line: \[(.*)\]`)[1])
+
+	eventRegistry := cyclecmd.NewEventRegistry(cyclecmd.EventInformation{EventName: "Default", Event: &DefaultEvent{}})
+	consoleApp := cyclecmd.NewConsoleApp("test", "0.1.0", "This is a test console application", eventRegistry, cyclecmd.NewEventHistory())
+	lineEditor := cyclecmd.NewLineEditor(consoleApp, nil)
+	err := keymap.Emacs().Install(consoleApp, lineEditor, cyclecmd.KeyBindings{cyclecmd.DEFAULT_MODE: {"ctrl+t": "TransposeChars"}})
+	assert.ErrorContains(t, err, "event TransposeChars bound to \"ctrl+t\" in mode default is not in the catalog")
+}
+
+func TestKeymapsAreValid(t *testing.T) {
+	t.Parallel()
+
+	consoleApp := cyclecmd.NewConsoleApp("test", "0.1.0", "This is a test console application", cyclecmd.NewEventRegistry(cyclecmd.EventInformation{EventName: "Default", Event: &DefaultEvent{}}), cyclecmd.NewEventHistory())
+	catalog := keymap.Catalog(consoleApp, cyclecmd.NewLineEditor(consoleApp, nil))
+	for _, km := range []keymap.Keymap{keymap.Emacs(), keymap.Vi()} {
+		assert.NoError(t, km.Resolve(nil).Validate(catalog), km.Name)
+	}
+}
@@ -0,0 +1,107 @@
+package keymap
+
+import "github.com/RaphSku/cyclecmd"
+
+// Modes of the vi keymap, the console application is in VI_INSERT_MODE once the keymap is installed.
+const (
+	// VI_INSERT_MODE inserts the typed characters into the line
+	VI_INSERT_MODE string = "insert"
+	// VI_NORMAL_MODE moves the cursor and edits the line with commands
+	VI_NORMAL_MODE string = "normal"
+	// VI_VISUAL_MODE selects text that is deleted or copied
+	VI_VISUAL_MODE string = "visual"
+)
+
+// viMotions are the keys that move the cursor in the normal and the visual mode.
+var viMotions = map[string]string{
+	"h":     "CursorLeft",
+	"left":  "CursorLeft",
+	"l":     "CursorRight",
+	"right": "CursorRight",
+	"0":     "CursorStart",
+	"^":     "CursorStart",
+	"home":  "CursorStart",
+	"$":     "CursorEnd",
+	"end":   "CursorEnd",
+	"w":     "NextWord",
+	"b":     "WordBackward",
+	"e":     "WordForward",
+}
+
+// Vi returns the keymap of vi with three modes. The insert mode inserts the typed characters, Escape enters the
+// normal mode. The normal mode moves the cursor with `h`, `l`, `w`, `b`, `e`, `0` and `$`, browses the submitted
+// lines with `k` and `j` and edits the line with `x`, `X`, `D`, `d d`, `d w`, `d b`, `C`, `c c` and `s`. `p` and
+// `P` insert the text that was killed last after or in front of the cursor. `i`, `a`, `I` and `A` enter the insert
+// mode, `v` enters the visual mode and Enter submits the line and enters the insert mode for the next line. The
+// visual mode extends the selection with the motions of the normal mode, `d` deletes and `y` copies the selection,
+// Escape cancels it.
+//
+// Returns:
+//   - `Keymap` : The vi keymap
+func Vi() Keymap {
+	normal := map[string]string{
+		"enter":     "SubmitInsertMode",
+		"x":         "Delete",
+		"delete":    "Delete",
+		"X":         "Backspace",
+		"backspace": "CursorLeft",
+		"space":     "CursorRight",
+		"D":         "KillToEnd",
+		"d d":       "KillLine",
+		"d w":       "DeleteWordForward",
+		"d b":       "DeleteWordBackward",
+		"d 0":       "KillToStart",
+		"d $":       "KillToEnd",
+		"C":         "ChangeToEnd",
+		"c c":       "ChangeLine",
+		"s":         "Substitute",
+		"p":         "YankAfter",
+		"P":         "Yank",
+		"k":         "HistoryPrevious",
+		"up":        "HistoryPrevious",
+		"j":         "HistoryNext",
+		"down":      "HistoryNext",
+		"i":         "InsertMode",
+		"a":         "AppendMode",
+		"I":         "InsertAtStartMode",
+		"A":         "AppendAtEndMode",
+		"v":         "VisualMode",
+		"ctrl+r":    "ReverseSearch",
+	}
+	visual := map[string]string{
+		"d":   "VisualDelete",
+		"x":   "VisualDelete",
+		"y":   "VisualYank",
+		"v":   "ExitVisualMode",
+		"esc": "ExitVisualMode",
+	}
+	for keys, eventName := range viMotions {
+		normal[keys] = eventName
+		visual[keys] = eventName
+	}
+	return Keymap{
+		Name:        "vi",
+		DefaultMode: VI_INSERT_MODE,
+		KeyBindings: cyclecmd.KeyBindings{
+			VI_INSERT_MODE: {
+				"enter":     "Submit",
+				"tab":       "Complete",
+				"backspace": "Backspace",
+				"ctrl+h":    "Backspace",
+				"delete":    "Delete",
+				"left":      "CursorLeft",
+				"right":     "CursorRight",
+				"home":      "CursorStart",
+				"end":       "CursorEnd",
+				"up":        "HistoryPrevious",
+				"down":      "HistoryNext",
+				"ctrl+w":    "DeleteWordBackward",
+				"ctrl+u":    "KillToStart",
+				"ctrl+r":    "ReverseSearch",
+				"esc":       "NormalMode",
+			},
+			VI_NORMAL_MODE: normal,
+			VI_VISUAL_MODE: visual,
+		},
+	}
+}
@@ -0,0 +1,148 @@
+package cyclecmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Tokens of commonly used keys as they are passed to the events. Tokens that consist of more than one
+// byte, like the arrow keys, are quoted, see ConsoleApp.convertByteTokenToStringToken.
+const (
+	// KEY_CTRL_A moves the cursor to the start of the line in most line editors
+	KEY_CTRL_A string = "\x01"
+	// KEY_CTRL_C is sent by Ctrl-C when the terminal is in raw mode, it cancels prompt widgets
+	KEY_CTRL_C string = "\x03"
+	// KEY_CTRL_E moves the cursor to the end of the line in most line editors
+	KEY_CTRL_E string = "\x05"
+	// KEY_CTRL_R starts a reverse incremental history search in most line editors
+	KEY_CTRL_R string = "\x12"
+	// KEY_TAB is sent by the Tab key
+	KEY_TAB string = "\t"
+	// KEY_SPACE is sent by the Space key
+	KEY_SPACE string = " "
+	// KEY_ENTER is sent by the Enter key when the terminal is in raw mode
+	KEY_ENTER string = "\r"
+	// KEY_ESCAPE is sent by the Escape key
+	KEY_ESCAPE string = "\x1b"
+	// KEY_BACKSPACE is sent by the Backspace key
+	KEY_BACKSPACE string = "\x7f"
+	// KEY_ARROW_UP is sent by the Arrow Up key
+	KEY_ARROW_UP string = "\"\\x1b[A\""
+	// KEY_ARROW_DOWN is sent by the Arrow Down key
+	KEY_ARROW_DOWN string = "\"\\x1b[B\""
+	// KEY_ARROW_RIGHT is sent by the Arrow Right key
+	KEY_ARROW_RIGHT string = "\"\\x1b[C\""
+	// KEY_ARROW_LEFT is sent by the Arrow Left key
+	KEY_ARROW_LEFT string = "\"\\x1b[D\""
+	// KEY_PAGE_UP is sent by the Page Up key
+	KEY_PAGE_UP string = "\"\\x1b[5~\""
+	// KEY_PAGE_DOWN is sent by the Page Down key
+	KEY_PAGE_DOWN string = "\"\\x1b[6~\""
+	// KEY_HOME is sent by the Home key
+	KEY_HOME string = "\"\\x1b[H\""
+	// KEY_END is sent by the End key
+	KEY_END string = "\"\\x1b[F\""
+	// KEY_RESIZE is dispatched when the terminal was resized, an event registered for it is recorded in the
+	// event history like any other event. The new size is available via ConsoleApp.Size
+	KEY_RESIZE string = "<resize>"
+)
+
+// keyNames maps the names of keys to the bytes a terminal in raw mode sends for them.
+var keyNames = map[string]string{
+	"enter":     "\r",
+	"tab":       "\t",
+	"space":     " ",
+	"backspace": "\x7f",
+	"esc":       "\x1b",
+	"escape":    "\x1b",
+	"up":        "\x1b[A",
+	"down":      "\x1b[B",
+	"right":     "\x1b[C",
+	"left":      "\x1b[D",
+	"home":      "\x1b[H",
+	"end":       "\x1b[F",
+	"pgup":      "\x1b[5~",
+	"pageup":    "\x1b[5~",
+	"pgdown":    "\x1b[6~",
+	"pagedown":  "\x1b[6~",
+	"insert":    "\x1b[2~",
+	"delete":    "\x1b[3~",
+}
+
+// KeyBytes returns the bytes a terminal in raw mode sends for a key. Names are case-insensitive, e.g. "enter",
+// "up", "pgdown", "ctrl+c" or "alt+b". A single character is sent as it is.
+//
+// Parameters:
+//   - `name` : Name of the key
+//
+// Returns:
+//   - `string` : Bytes of the key
+//   - `error` : Returns an error when the key is unknown
+func KeyBytes(name string) (string, error) {
+	if len([]rune(name)) == 1 {
+		return name, nil
+	}
+	lowerName := strings.ToLower(name)
+	if key, ok := keyNames[lowerName]; ok {
+		return key, nil
+	}
+	if letter, ok := strings.CutPrefix(lowerName, "ctrl+"); ok && len(letter) == 1 && letter[0] >= 'a' && letter[0] <= 'z' {
+		return string(rune(letter[0] - 'a' + 1)), nil
+	}
+	if strings.HasPrefix(lowerName, "alt+") {
+		keyBytes, err := KeyBytes(name[len("alt+"):])
+		if err != nil {
+			return "", err
+		}
+		return "\x1b" + keyBytes, nil
+	}
+	return "", fmt.Errorf("key %v is unknown", name)
+}
+
+// ParseKey returns the token of a key as it is passed to the events, so that it can be used as event trigger.
+// Tokens of keys that send more than one byte are quoted, e.g. ParseKey("up") returns KEY_ARROW_UP.
+//
+// Parameters:
+//   - `name` : Name of the key, see KeyBytes
+//
+// Returns:
+//   - `string` : Token of the key
+//   - `error` : Returns an error when the key is unknown
+func ParseKey(name string) (string, error) {
+	keyBytes, err := KeyBytes(name)
+	if err != nil {
+		return "", err
+	}
+	if len(keyBytes) > 1 {
+		return fmt.Sprintf("%q", keyBytes), nil
+	}
+	return keyBytes, nil
+}
+
+// printableCharacter decodes a token that consists of a single printable character. Characters that are encoded
+// with more than one byte, like "é" or "日", arrive quoted like every token of more than one byte.
+//
+// Parameters:
+//   - `token` : Token that triggered the event
+//
+// Returns:
+//   - `string` : The unquoted character
+//   - `bool` : True if the token is a printable character
+func printableCharacter(token string) (string, bool) {
+	character := token
+	if len(token) > 1 {
+		unquoted, err := strconv.Unquote(token)
+		if err != nil {
+			return "", false
+		}
+		character = unquoted
+	}
+	r, size := utf8.DecodeRuneInString(character)
+	if size == 0 || size != len(character) || r == utf8.RuneError || !unicode.IsPrint(r) {
+		return "", false
+	}
+	return character, true
+}
@@ -0,0 +1,230 @@
+package cyclecmd
+
+import "unicode"
+
+// isWordRune returns whether the rune belongs to a word, words consist of letters, digits and underscores.
+//
+// Parameters:
+//   - `r` : Rune that is checked
+//
+// Returns:
+//   - `bool` : True if the rune belongs to a word
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// wordStartBefore returns the start of the word in front of the position, characters between the words are skipped.
+//
+// Parameters:
+//   - `position` : Position the search starts at
+//
+// Returns:
+//   - `int` : Start of the word
+func (le *LineEditor) wordStartBefore(position int) int {
+	for position > 0 && !isWordRune(le.buffer[position-1]) {
+		position--
+	}
+	for position > 0 && isWordRune(le.buffer[position-1]) {
+		position--
+	}
+	return position
+}
+
+// wordEndAfter returns the end of the word after the position, characters between the words are skipped.
+//
+// Parameters:
+//   - `position` : Position the search starts at
+//
+// Returns:
+//   - `int` : End of the word
+func (le *LineEditor) wordEndAfter(position int) int {
+	for position < len(le.buffer) && !isWordRune(le.buffer[position]) {
+		position++
+	}
+	for position < len(le.buffer) && isWordRune(le.buffer[position]) {
+		position++
+	}
+	return position
+}
+
+// kill deletes the text between start and end and keeps it in the kill buffer, the cursor is moved to start.
+//
+// Parameters:
+//   - `start` : Start of the text
+//   - `end` : End of the text
+func (le *LineEditor) kill(start int, end int) {
+	if start >= end {
+		return
+	}
+	le.killBuffer = append([]rune{}, le.buffer[start:end]...)
+	le.buffer = append(le.buffer[:start], le.buffer[end:]...)
+	le.cursor = start
+	le.redraw()
+}
+
+// moveCursor moves the cursor to the position.
+//
+// Parameters:
+//   - `position` : New position of the cursor
+func (le *LineEditor) moveCursor(position int) {
+	if position == le.cursor {
+		return
+	}
+	le.cursor = position
+	le.redraw()
+}
+
+// deleteForward deletes the character under the cursor.
+func (le *LineEditor) deleteForward(token string) (error, *ControlEvent) {
+	if le.cursor < len(le.buffer) {
+		le.buffer = append(le.buffer[:le.cursor], le.buffer[le.cursor+1:]...)
+		le.redraw()
+	}
+	return nil, nil
+}
+
+// wordBackward moves the cursor to the start of the word in front of the cursor.
+func (le *LineEditor) wordBackward(token string) (error, *ControlEvent) {
+	le.moveCursor(le.wordStartBefore(le.cursor))
+	return nil, nil
+}
+
+// wordForward moves the cursor to the end of the word after the cursor.
+func (le *LineEditor) wordForward(token string) (error, *ControlEvent) {
+	le.moveCursor(le.wordEndAfter(le.cursor))
+	return nil, nil
+}
+
+// nextWord moves the cursor to the start of the next word.
+func (le *LineEditor) nextWord(token string) (error, *ControlEvent) {
+	position := le.cursor
+	for position < len(le.buffer) && isWordRune(le.buffer[position]) {
+		position++
+	}
+	for position < len(le.buffer) && !isWordRune(le.buffer[position]) {
+		position++
+	}
+	le.moveCursor(position)
+	return nil, nil
+}
+
+// deleteWordBackward kills the word in front of the cursor.
+func (le *LineEditor) deleteWordBackward(token string) (error, *ControlEvent) {
+	le.kill(le.wordStartBefore(le.cursor), le.cursor)
+	return nil, nil
+}
+
+// deleteWordForward kills the word after the cursor.
+func (le *LineEditor) deleteWordForward(token string) (error, *ControlEvent) {
+	le.kill(le.cursor, le.wordEndAfter(le.cursor))
+	return nil, nil
+}
+
+// killToStart kills the text in front of the cursor.
+func (le *LineEditor) killToStart(token string) (error, *ControlEvent) {
+	le.kill(0, le.cursor)
+	return nil, nil
+}
+
+// killToEnd kills the text after the cursor.
+func (le *LineEditor) killToEnd(token string) (error, *ControlEvent) {
+	le.kill(le.cursor, len(le.buffer))
+	return nil, nil
+}
+
+// killLine kills the whole line.
+func (le *LineEditor) killLine(token string) (error, *ControlEvent) {
+	le.kill(0, len(le.buffer))
+	return nil, nil
+}
+
+// yank inserts the text that was killed last at the cursor position.
+func (le *LineEditor) yank(token string) (error, *ControlEvent) {
+	if len(le.killBuffer) > 0 {
+		le.Insert(string(le.killBuffer))
+	}
+	return nil, nil
+}
+
+// historyPrevious replaces the line with the submitted line before the one that is shown, the edited line is kept
+// as draft until the history is left again.
+func (le *LineEditor) historyPrevious(token string) (error, *ControlEvent) {
+	submittedLines := le.consoleApp.eventHistory.SubmittedLines()
+	if le.historyOffset >= len(submittedLines) {
+		return nil, nil
+	}
+	if le.historyOffset == 0 {
+		le.draft = append([]rune{}, le.buffer...)
+	}
+	le.historyOffset++
+	le.SetLine(submittedLines[len(submittedLines)-le.historyOffset])
+	return nil, nil
+}
+
+// historyNext replaces the line with the submitted line after the one that is shown or with the draft once the
+// most recent line is passed.
+func (le *LineEditor) historyNext(token string) (error, *ControlEvent) {
+	if le.historyOffset == 0 {
+		return nil, nil
+	}
+	le.historyOffset--
+	if le.historyOffset == 0 {
+		le.SetLine(string(le.draft))
+		le.draft = nil
+		return nil, nil
+	}
+	submittedLines := le.consoleApp.eventHistory.SubmittedLines()
+	le.SetLine(submittedLines[len(submittedLines)-le.historyOffset])
+	return nil, nil
+}
+
+// selection returns the selected text, the selection includes the characters under the anchor and the cursor.
+//
+// Returns:
+//   - `int` : Start of the selection
+//   - `int` : End of the selection
+//   - `bool` : False if no text is selected
+func (le *LineEditor) selection() (int, int, bool) {
+	if !le.selecting || len(le.buffer) == 0 {
+		return 0, 0, false
+	}
+	start, end := min(le.selectionAnchor, le.cursor), max(le.selectionAnchor, le.cursor)+1
+	return min(start, len(le.buffer)), min(end, len(le.buffer)), true
+}
+
+// startSelection starts selecting text at the cursor position, moving the cursor extends the selection.
+func (le *LineEditor) startSelection(token string) (error, *ControlEvent) {
+	le.selecting = true
+	le.selectionAnchor = le.cursor
+	le.redraw()
+	return nil, nil
+}
+
+// clearSelection clears the selection, the text stays unchanged.
+func (le *LineEditor) clearSelection(token string) (error, *ControlEvent) {
+	if le.selecting {
+		le.selecting = false
+		le.redraw()
+	}
+	return nil, nil
+}
+
+// deleteSelection kills the selected text.
+func (le *LineEditor) deleteSelection(token string) (error, *ControlEvent) {
+	start, end, ok := le.selection()
+	le.selecting = false
+	if !ok {
+		return nil, nil
+	}
+	le.kill(start, end)
+	return nil, nil
+}
+
+// copySelection keeps the selected text in the kill buffer and clears the selection.
+func (le *LineEditor) copySelection(token string) (error, *ControlEvent) {
+	start, end, ok := le.selection()
+	if ok {
+		le.killBuffer = append([]rune{}, le.buffer[start:end]...)
+	}
+	return le.clearSelection(token)
+}
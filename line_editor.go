@@ -0,0 +1,458 @@
+package cyclecmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/RaphSku/cyclecmd/style"
+	"go.uber.org/zap"
+)
+
+// LineEditor is a single line editor that collects the typed tokens into a line and submits the line on Enter.
+// Submitted lines are recorded in the event history of the console application, see EventHistory.SubmittedLines.
+type LineEditor struct {
+	consoleApp *ConsoleApp
+
+	// buffer contains the line that is currently edited
+	buffer []rune
+	// cursor is the position of the cursor within the buffer
+	cursor int
+
+	// displayLength is the number of columns that are displayed for the line editor on the screen
+	displayLength int
+	// displayCursor is the column of the cursor on the screen relative to the start of the line editor
+	displayCursor int
+
+	// onSubmit is called with the submitted line
+	onSubmit func(line string) (error, *ControlEvent)
+
+	reverseSearch *reverseSearch
+
+	// completer completes the word in front of the cursor, might be nil
+	completer Completer
+	// completion is the state of the completion in progress, nil if no completion is in progress
+	completion *completionState
+
+	// killBuffer contains the text that was killed last, it is inserted again by the Yank event
+	killBuffer []rune
+	// historyOffset counts the submitted lines that were browsed back, 0 if the line is not from the history
+	historyOffset int
+	// draft is the line that was edited before the history was browsed
+	draft []rune
+	// selecting is true while text is selected, the selection spans from selectionAnchor to the cursor
+	selecting       bool
+	selectionAnchor int
+
+	// FuzzyHistorySearch enables fuzzy matching for the reverse history search instead of substring matching
+	FuzzyHistorySearch bool
+}
+
+// NewLineEditor initialises a line editor that edits lines in the given console application.
+//
+// Parameters:
+//   - `consoleApp` : Console application the line editor prints to and records submitted lines in
+//   - `onSubmit` : Called with the line once it is submitted, might be nil
+//
+// Returns:
+//   - `*LineEditor` : Returns an instance of LineEditor
+func NewLineEditor(consoleApp *ConsoleApp, onSubmit func(line string) (error, *ControlEvent)) *LineEditor {
+	lineEditor := &LineEditor{
+		consoleApp: consoleApp,
+		onSubmit:   onSubmit,
+	}
+	lineEditor.reverseSearch = newReverseSearch(lineEditor)
+	return lineEditor
+}
+
+// RegisterEvents registers the events of the line editor in the event registry. The line editor becomes the
+// default event, so that every printable token is inserted into the line. The following keys are bound:
+//   - `KEY_ENTER` : Submits the line
+//   - `KEY_BACKSPACE` : Deletes the character in front of the cursor
+//   - `KEY_ARROW_LEFT`, `KEY_ARROW_RIGHT` : Moves the cursor
+//   - `KEY_CTRL_A`, `KEY_CTRL_E` : Moves the cursor to the start or the end of the line
+//   - `KEY_CTRL_R` : Starts the reverse incremental history search
+//   - `KEY_TAB` : Completes the word in front of the cursor, see SetCompleter
+//
+// Further events are not bound to keys, they can be bound by name via EventCatalog, e.g. by a keymap of the
+// keymap package.
+//
+// Parameters:
+//   - `eventRegistry` : Event registry the events are registered with
+//
+// Returns:
+//   - `error` : Returns an error when a key is already registered
+func (le *LineEditor) RegisterEvents(eventRegistry *EventRegistry) error {
+	eventRegistry.DefaultEventInformation = EventInformation{
+		EventName: "Insert",
+		Event:     &funcEvent{handle: le.insert},
+	}
+	for _, event := range le.events() {
+		if event.eventTrigger == "" {
+			continue
+		}
+		err := eventRegistry.RegisterEvent(event.eventTrigger, EventInformation{
+			EventName: event.eventName,
+			Event:     &funcEvent{handle: event.handle},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EventCatalog returns a catalog of all events of the line editor, so that they can be bound to keys by name,
+// see InstallKeyBindings. Besides the events that RegisterEvents binds, the catalog contains:
+//   - `Insert` : Inserts the printable token
+//   - `Delete` : Deletes the character under the cursor
+//   - `WordBackward`, `WordForward` : Moves the cursor to the start of the previous or the end of the next word
+//   - `NextWord` : Moves the cursor to the start of the next word
+//   - `DeleteWordBackward`, `DeleteWordForward` : Kills the word in front of or after the cursor
+//   - `KillToStart`, `KillToEnd`, `KillLine` : Kills the text in front of the cursor, after the cursor or the line
+//   - `Yank` : Inserts the text that was killed last
+//   - `HistoryPrevious`, `HistoryNext` : Replaces the line with the previous or next submitted line
+//   - `StartSelection`, `ClearSelection` : Starts selecting text from the cursor or clears the selection
+//   - `DeleteSelection`, `CopySelection` : Kills the selected text or copies it, so that it can be yanked
+//
+// Returns:
+//   - `*EventCatalog` : Catalog of the events of the line editor
+func (le *LineEditor) EventCatalog() *EventCatalog {
+	eventCatalog := NewEventCatalog(EventInformation{
+		EventName: "Insert",
+		Event:     &funcEvent{handle: le.insert},
+	})
+	for _, event := range le.events() {
+		eventCatalog.events[event.eventName] = EventInformation{
+			EventName: event.eventName,
+			Event:     &funcEvent{handle: event.handle},
+		}
+	}
+	return eventCatalog
+}
+
+// lineEditorEvent is an event of the line editor.
+type lineEditorEvent struct {
+	// eventTrigger is the key RegisterEvents binds the event to, empty if the event is not bound
+	eventTrigger string
+	eventName    string
+	handle       func(token string) (error, *ControlEvent)
+}
+
+// events returns all events of the line editor except for the default event.
+//
+// Returns:
+//   - `[]lineEditorEvent` : Events of the line editor
+func (le *LineEditor) events() []lineEditorEvent {
+	return []lineEditorEvent{
+		{KEY_ENTER, "Submit", le.submit},
+		{KEY_BACKSPACE, "Backspace", le.backspace},
+		{KEY_ARROW_LEFT, "CursorLeft", le.cursorLeft},
+		{KEY_ARROW_RIGHT, "CursorRight", le.cursorRight},
+		{KEY_CTRL_A, "CursorStart", le.cursorStart},
+		{KEY_CTRL_E, "CursorEnd", le.cursorEnd},
+		{KEY_CTRL_R, "ReverseSearch", le.reverseSearch.start},
+		{KEY_TAB, "Complete", le.complete},
+		{"", "Delete", le.deleteForward},
+		{"", "WordBackward", le.wordBackward},
+		{"", "WordForward", le.wordForward},
+		{"", "NextWord", le.nextWord},
+		{"", "DeleteWordBackward", le.deleteWordBackward},
+		{"", "DeleteWordForward", le.deleteWordForward},
+		{"", "KillToStart", le.killToStart},
+		{"", "KillToEnd", le.killToEnd},
+		{"", "KillLine", le.killLine},
+		{"", "Yank", le.yank},
+		{"", "HistoryPrevious", le.historyPrevious},
+		{"", "HistoryNext", le.historyNext},
+		{"", "StartSelection", le.startSelection},
+		{"", "ClearSelection", le.clearSelection},
+		{"", "DeleteSelection", le.deleteSelection},
+		{"", "CopySelection", le.copySelection},
+	}
+}
+
+// SetCompleter sets the completer that completes the word in front of the cursor when Tab is pressed.
+//
+// Parameters:
+//   - `completer` : Completer for the line editor
+func (le *LineEditor) SetCompleter(completer Completer) {
+	le.completer = completer
+}
+
+// Line returns the line that is currently edited.
+//
+// Returns:
+//   - `string` : The current line
+func (le *LineEditor) Line() string {
+	return string(le.buffer)
+}
+
+// Cursor returns the position of the cursor within the current line.
+//
+// Returns:
+//   - `int` : Position of the cursor counted in runes
+func (le *LineEditor) Cursor() int {
+	return le.cursor
+}
+
+// SetLine replaces the current line, the cursor is moved to the end of the line.
+//
+// Parameters:
+//   - `line` : The new line
+func (le *LineEditor) SetLine(line string) {
+	le.buffer = []rune(line)
+	le.cursor = len(le.buffer)
+	le.selecting = false
+	le.redraw()
+}
+
+// Insert inserts text at the cursor position.
+//
+// Parameters:
+//   - `text` : Text that is inserted
+func (le *LineEditor) Insert(text string) {
+	runes := []rune(text)
+	atEnd := le.cursor == len(le.buffer) && le.displayCursor == le.displayLength && le.displayLength == displayWidth(le.Line())
+	le.buffer = append(le.buffer[:le.cursor], append(runes, le.buffer[le.cursor:]...)...)
+	le.cursor += len(runes)
+	if atEnd {
+		// Appending at the end of the line does not require redrawing the line
+		fmt.Fprint(le.consoleApp.Output(), text)
+		le.displayLength += displayWidth(text)
+		le.displayCursor += displayWidth(text)
+		return
+	}
+	le.redraw()
+}
+
+// Submit submits the current line. The line is recorded in the event history and passed to the submit callback,
+// afterwards the line editor starts with an empty line.
+//
+// Returns:
+//   - `error` : Error returned by the submit callback
+//   - `*ControlEvent` : Control event returned by the submit callback
+func (le *LineEditor) Submit() (error, *ControlEvent) {
+	line := le.Line()
+	le.buffer = nil
+	le.cursor = 0
+	le.historyOffset = 0
+	le.draft = nil
+	le.selecting = false
+	le.displayLength = 0
+	le.displayCursor = 0
+	fmt.Fprint(le.consoleApp.Output(), "\r\n")
+	le.consoleApp.logger.Debug("Line submitted", zap.String("line", line), zap.String("func", "Submit"))
+
+	if strings.TrimSpace(line) != "" {
+		le.consoleApp.eventHistory.AddEvent(EventHistoryEntry{
+			Token:     line,
+			EventName: SUBMITTED_LINE_EVENT_NAME,
+			Event:     &funcEvent{handle: le.submit},
+		})
+	}
+	if le.onSubmit == nil {
+		return nil, nil
+	}
+	return le.onSubmit(line)
+}
+
+// redraw renders the line with the cursor, the selected text is highlighted.
+func (le *LineEditor) redraw() {
+	start, end, ok := le.selection()
+	if !ok {
+		le.render(le.Line(), le.cursor)
+		return
+	}
+	content := string(le.buffer[:start]) +
+		le.consoleApp.render(le.consoleApp.theme.Selection, string(le.buffer[start:end])) +
+		string(le.buffer[end:])
+	le.render(content, le.cursor)
+}
+
+// render replaces everything that the line editor displays on the screen with content.
+//
+// Parameters:
+//   - `content` : Content that is displayed, it can contain style sequences
+//   - `cursor` : Position of the cursor within the content counted in runes without the style sequences
+func (le *LineEditor) render(content string, cursor int) {
+	var output strings.Builder
+	if le.displayCursor > 0 {
+		fmt.Fprintf(&output, "\x1b[%dD", le.displayCursor)
+	}
+	output.WriteString(content)
+	output.WriteString("\x1b[K")
+	plainContent := []rune(style.Strip(content))
+	contentLength := displayWidth(string(plainContent))
+	cursorColumn := displayWidth(string(plainContent[:min(cursor, len(plainContent))]))
+	if contentLength > cursorColumn {
+		fmt.Fprintf(&output, "\x1b[%dD", contentLength-cursorColumn)
+	}
+	fmt.Fprint(le.consoleApp.Output(), output.String())
+	le.displayLength = contentLength
+	le.displayCursor = cursorColumn
+}
+
+// insert inserts a printable token at the cursor position, all other tokens are ignored.
+func (le *LineEditor) insert(token string) (error, *ControlEvent) {
+	character, ok := printableCharacter(token)
+	if !ok {
+		return nil, nil
+	}
+	le.Insert(character)
+	return nil, nil
+}
+
+// submit submits the current line.
+func (le *LineEditor) submit(token string) (error, *ControlEvent) {
+	return le.Submit()
+}
+
+// backspace deletes the character in front of the cursor.
+func (le *LineEditor) backspace(token string) (error, *ControlEvent) {
+	if le.cursor == 0 {
+		return nil, nil
+	}
+	// Only characters that occupy a single column can be erased without redrawing the line
+	atEnd := le.cursor == len(le.buffer) && le.displayCursor == le.displayLength && le.displayLength == displayWidth(le.Line()) &&
+		style.RuneWidth(le.buffer[le.cursor-1]) == 1
+	le.buffer = append(le.buffer[:le.cursor-1], le.buffer[le.cursor:]...)
+	le.cursor -= 1
+	if atEnd {
+		fmt.Fprint(le.consoleApp.Output(), "\b \b")
+		le.displayLength -= 1
+		le.displayCursor -= 1
+		return nil, nil
+	}
+	le.redraw()
+	return nil, nil
+}
+
+// cursorLeft moves the cursor one character to the left.
+func (le *LineEditor) cursorLeft(token string) (error, *ControlEvent) {
+	if le.cursor > 0 {
+		le.cursor -= 1
+		le.redraw()
+	}
+	return nil, nil
+}
+
+// cursorRight moves the cursor one character to the right.
+func (le *LineEditor) cursorRight(token string) (error, *ControlEvent) {
+	if le.cursor < len(le.buffer) {
+		le.cursor += 1
+		le.redraw()
+	}
+	return nil, nil
+}
+
+// cursorStart moves the cursor to the start of the line.
+func (le *LineEditor) cursorStart(token string) (error, *ControlEvent) {
+	le.cursor = 0
+	le.redraw()
+	return nil, nil
+}
+
+// cursorEnd moves the cursor to the end of the line.
+func (le *LineEditor) cursorEnd(token string) (error, *ControlEvent) {
+	le.cursor = len(le.buffer)
+	le.redraw()
+	return nil, nil
+}
+
+// completionState is the state of a completion that spans several Tab presses.
+type completionState struct {
+	// candidates of the completion
+	candidates []Candidate
+	// index of the candidate that is currently inserted, -1 if no candidate is inserted
+	index int
+	// wordStart is the position of the word that is completed
+	wordStart int
+	// listed is true once the candidates were listed under the prompt
+	listed bool
+}
+
+// complete completes the word in front of the cursor. A single candidate is inserted right away, otherwise the
+// common prefix of the candidates is inserted. If there is no common prefix to insert, the candidates are listed
+// under the prompt and repeated Tab presses cycle through the candidates.
+func (le *LineEditor) complete(token string) (error, *ControlEvent) {
+	if le.completer == nil {
+		return nil, nil
+	}
+	if le.completion != nil && le.previousEventName() == "Complete" && le.completion.listed {
+		le.completion.index = (le.completion.index + 1) % len(le.completion.candidates)
+		le.replaceWord(le.completion.wordStart, le.completion.candidates[le.completion.index].Value)
+		return nil, nil
+	}
+
+	le.completion = nil
+	candidates := le.completer.Complete(le.Line(), le.cursor)
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+	sortCandidates(candidates)
+	wordStart, word := wordInFrontOfCursor(le.Line(), le.cursor)
+	if len(candidates) == 1 {
+		value := candidates[0].Value
+		if !strings.HasSuffix(value, string(filepath.Separator)) {
+			value += " "
+		}
+		le.replaceWord(wordStart, value)
+		return nil, nil
+	}
+
+	le.completion = &completionState{candidates: candidates, index: -1, wordStart: wordStart}
+	prefix := commonPrefix(candidates)
+	if len([]rune(prefix)) > len([]rune(word)) {
+		le.replaceWord(wordStart, prefix)
+		return nil, nil
+	}
+	le.listCandidates(candidates)
+	le.completion.listed = true
+	return nil, nil
+}
+
+// previousEventName returns the name of the event that was recorded before the event that is currently handled.
+//
+// Returns:
+//   - `string` : Name of the previous event, empty if there is no previous event
+func (le *LineEditor) previousEventName() string {
+	eventHistoryEntry, err := le.consoleApp.eventHistory.RetrieveEventEntryByIndex(le.consoleApp.eventHistory.Len() - 2)
+	if err != nil {
+		return ""
+	}
+	return eventHistoryEntry.EventName
+}
+
+// replaceWord replaces the text between wordStart and the cursor with value.
+//
+// Parameters:
+//   - `wordStart` : Position where the replaced word starts
+//   - `value` : Text that replaces the word
+func (le *LineEditor) replaceWord(wordStart int, value string) {
+	tail := append([]rune{}, le.buffer[le.cursor:]...)
+	buffer := append(append([]rune{}, le.buffer[:wordStart]...), []rune(value)...)
+	le.cursor = len(buffer)
+	le.buffer = append(buffer, tail...)
+	le.redraw()
+}
+
+// listCandidates lists the candidates under the prompt and redraws the prompt together with the line.
+//
+// Parameters:
+//   - `candidates` : Candidates that are listed
+func (le *LineEditor) listCandidates(candidates []Candidate) {
+	var output strings.Builder
+	output.WriteString("\r\n")
+	width, _ := le.consoleApp.Size()
+	for _, line := range formatCandidates(candidates, width) {
+		output.WriteString(line)
+		output.WriteString("\r\n")
+	}
+	output.WriteString(le.consoleApp.prompt())
+	output.WriteString(le.Line())
+	fmt.Fprint(le.consoleApp.Output(), output.String())
+	le.displayLength = displayWidth(le.Line())
+	le.displayCursor = le.displayLength
+	le.redraw()
+}
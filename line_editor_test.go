@@ -0,0 +1,151 @@
+//go:build unit_test
+
+package cyclecmd_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/RaphSku/cyclecmd"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupLineEditorConsoleApp(tokens ...string) (*cyclecmd.ConsoleApp, *cyclecmd.LineEditor, *cyclecmd.EventHistory, *[]string) {
+	eventRegistry := cyclecmd.NewEventRegistry(setupDefaultEventInformation())
+	eventHistory := cyclecmd.NewEventHistory()
+	consoleApp := cyclecmd.NewConsoleApp(
+		"test",
+		"0.1.0",
+		"This is a test console application",
+		eventRegistry,
+		eventHistory,
+	)
+	consoleApp.SetInput(newTokenReader(tokens...))
+	consoleApp.SetOutput(&bytes.Buffer{})
+
+	submittedLines := &[]string{}
+	lineEditor := cyclecmd.NewLineEditor(consoleApp, func(line string) (error, *cyclecmd.ControlEvent) {
+		*submittedLines = append(*submittedLines, line)
+		return nil, nil
+	})
+	lineEditor.RegisterEvents(eventRegistry)
+	return consoleApp, lineEditor, eventHistory, submittedLines
+}
+
+func TestLineEditorEditing(t *testing.T) {
+	t.Parallel()
+
+	consoleApp, lineEditor, eventHistory, submittedLines := setupLineEditorConsoleApp(
+		"h", "l", "o", "\x1b[D", "\x1b[D", "e", "l", cyclecmd.KEY_CTRL_E, "!", cyclecmd.KEY_BACKSPACE, cyclecmd.KEY_ENTER,
+		"w", "i", "p",
+	)
+	consoleApp.Start()
+
+	assert.Equal(t, []string{"hello"}, *submittedLines)
+	assert.Equal(t, []string{"hello"}, eventHistory.SubmittedLines())
+	assert.Equal(t, "wip", lineEditor.Line())
+	assert.Equal(t, 3, lineEditor.Cursor())
+}
+
+func TestLineEditorPastedInput(t *testing.T) {
+	t.Parallel()
+
+	// Pasted input arrives at once and is split into keys
+	consoleApp, lineEditor, _, submittedLines := setupLineEditorConsoleApp("hello wörld\r", "ab\x1b[D\x1b[5~c")
+	consoleApp.Start()
+
+	assert.Equal(t, []string{"hello wörld"}, *submittedLines)
+	assert.Equal(t, "acb", lineEditor.Line())
+}
+
+func TestLineEditorUnicode(t *testing.T) {
+	t.Parallel()
+
+	// Characters of more than one byte arrive as quoted tokens
+	consoleApp, lineEditor, _, submittedLines := setupLineEditorConsoleApp(
+		"c", "a", "f", "é", cyclecmd.KEY_ENTER, "日", "本", "\xff", cyclecmd.KEY_BACKSPACE, "\x1b[D", "ü",
+		cyclecmd.KEY_CTRL_R, "é", cyclecmd.KEY_ENTER,
+	)
+	consoleApp.Start()
+
+	assert.Equal(t, []string{"café"}, *submittedLines)
+	assert.Equal(t, "café", lineEditor.Line())
+
+	output := &bytes.Buffer{}
+	consoleApp, lineEditor, _, _ = setupLineEditorConsoleApp("日", "本", "\x1b[D", "ü")
+	consoleApp.SetOutput(output)
+	consoleApp.DisableBanner()
+	consoleApp.Start()
+	assert.Equal(t, "日ü本", lineEditor.Line())
+	// The cursor moves by the two columns of the wide character
+	assert.Equal(t, "日本\x1b[4D日本\x1b[K\x1b[2D\x1b[2D日ü本\x1b[K\x1b[2D", output.String())
+}
+
+func TestLineEditorEventCatalog(t *testing.T) {
+	t.Parallel()
+
+	consoleApp, lineEditor, _, submittedLines := setupLineEditorConsoleApp(
+		"o", "n", "e", " ", "t", "w", "o", "\x1bb", "\x1bd", cyclecmd.KEY_CTRL_E, "\x19", cyclecmd.KEY_ENTER,
+		"x", "\x10", "\x10", "\x0e", "\x10", "!", cyclecmd.KEY_CTRL_A, "\x02", cyclecmd.KEY_CTRL_E,
+	)
+	keyBindings := cyclecmd.KeyBindings{cyclecmd.DEFAULT_MODE: {
+		"alt+b":  "WordBackward",
+		"alt+d":  "DeleteWordForward",
+		"ctrl+y": "Yank",
+		"ctrl+p": "HistoryPrevious",
+		"ctrl+n": "HistoryNext",
+		"ctrl+b": "StartSelection",
+	}}
+	assert.NoError(t, consoleApp.InstallKeyBindings(keyBindings, lineEditor.EventCatalog()))
+	consoleApp.Start()
+
+	assert.Equal(t, []string{"one two"}, *submittedLines)
+	assert.Equal(t, "one two!", lineEditor.Line())
+	assert.Contains(t, lineEditor.EventCatalog().Names(), "Insert")
+}
+
+func TestReverseSearch(t *testing.T) {
+	t.Parallel()
+
+	tokens := []string{}
+	for _, line := range []string{"git status", "go test", "git commit"} {
+		for _, character := range line {
+			tokens = append(tokens, string(character))
+		}
+		tokens = append(tokens, cyclecmd.KEY_ENTER)
+	}
+	tokens = append(tokens, cyclecmd.KEY_CTRL_R, "g", "i", "t", cyclecmd.KEY_CTRL_R, cyclecmd.KEY_ENTER)
+	consoleApp, lineEditor, _, _ := setupLineEditorConsoleApp(tokens...)
+	consoleApp.Start()
+	assert.Equal(t, "git status", lineEditor.Line())
+
+	consoleApp, lineEditor, _, _ = setupLineEditorConsoleApp("a", cyclecmd.KEY_ENTER, "b", cyclecmd.KEY_CTRL_R, "a", cyclecmd.KEY_ESCAPE)
+	consoleApp.Start()
+	assert.Equal(t, "b", lineEditor.Line())
+
+	// Accepting the search keeps the line in the editor, so no delimiter is printed
+	output := &bytes.Buffer{}
+	consoleApp, lineEditor, _, _ = setupLineEditorConsoleApp("a", "b", cyclecmd.KEY_ENTER, cyclecmd.KEY_CTRL_R, "a", cyclecmd.KEY_ENTER)
+	consoleApp.SetOutput(output)
+	consoleApp.DisableBanner()
+	consoleApp.SetLineDelimiter("\r\n>>> ", cyclecmd.KEY_ENTER)
+	consoleApp.Start()
+	assert.Equal(t, "ab", lineEditor.Line())
+	assert.Equal(t, 2, strings.Count(output.String(), "\r\n>>> "))
+	assert.False(t, strings.HasSuffix(output.String(), "\r\n>>> "))
+}
+
+func TestFuzzyReverseSearch(t *testing.T) {
+	t.Parallel()
+
+	tokens := []string{}
+	for _, character := range "git commit" {
+		tokens = append(tokens, string(character))
+	}
+	tokens = append(tokens, cyclecmd.KEY_ENTER, cyclecmd.KEY_CTRL_R, "g", "c", "m", cyclecmd.KEY_ENTER)
+	consoleApp, lineEditor, _, _ := setupLineEditorConsoleApp(tokens...)
+	lineEditor.FuzzyHistorySearch = true
+	consoleApp.Start()
+	assert.Equal(t, "git commit", lineEditor.Line())
+}
@@ -0,0 +1,323 @@
+package cyclecmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"go.uber.org/zap"
+)
+
+// maxMacroPlaybackDepth limits how deep macros can play back other macros.
+const maxMacroPlaybackDepth = 16
+
+// MacroRegistry records runs of tokens into named registers and replays them through the
+// event dispatch of a console application, comparable to vim's `q`/`@` or Emacs' `C-x (`.
+//
+// Recording piggybacks on the event history: a recording remembers where it started in the
+// event history and collects the tokens that were recorded in the meantime once it is stopped.
+type MacroRegistry struct {
+	consoleApp *ConsoleApp
+
+	// macros maps a register to the sequence of tokens that were recorded into it
+	macros map[string][]string
+
+	recording         bool
+	recordingRegister string
+	// recordingStart is the position in the event history where the recording started
+	recordingStart int
+	// playbackRanges contains the event history ranges that were produced by a macro playback
+	// while recording, those tokens are not recorded again since the playback token itself is recorded
+	playbackRanges [][2]int
+
+	// playing contains the registers that are currently played back, used for recursion protection
+	playing map[string]bool
+}
+
+// NewMacroRegistry initialises a macro registry whose macros are recorded from and played back
+// through the given console application.
+//
+// Parameters:
+//   - `consoleApp` : Console application whose event history is recorded and whose dispatch is used for playback
+//
+// Returns:
+//   - `*MacroRegistry` : Returns an instance of the macro registry
+func NewMacroRegistry(consoleApp *ConsoleApp) *MacroRegistry {
+	return &MacroRegistry{
+		consoleApp: consoleApp,
+		macros:     make(map[string][]string),
+		playing:    make(map[string]bool),
+	}
+}
+
+// IsRecording returns whether a macro is currently being recorded.
+//
+// Returns:
+//   - `bool` : True if a recording is in progress
+//   - `string` : The register that is recorded into, empty if no recording is in progress
+func (mr *MacroRegistry) IsRecording() (bool, string) {
+	return mr.recording, mr.recordingRegister
+}
+
+// StartRecording starts recording all tokens that are dispatched from now on into the register.
+//
+// Parameters:
+//   - `register` : Name of the register the macro is recorded into
+//
+// Returns:
+//   - `error` : Returns an error when a recording is already in progress
+func (mr *MacroRegistry) StartRecording(register string) error {
+	if mr.recording {
+		return fmt.Errorf("macro recording into register %v is already in progress", mr.recordingRegister)
+	}
+	mr.recording = true
+	mr.recordingRegister = register
+	mr.recordingStart = mr.consoleApp.eventHistory.Len()
+	mr.playbackRanges = nil
+	mr.consoleApp.logger.Debug("Macro recording started", zap.String("register", register), zap.String("func", "StartRecording"))
+	return nil
+}
+
+// StopRecording stops the recording in progress and stores the recorded tokens in the register.
+//
+// Returns:
+//   - `error` : Returns an error when no recording is in progress
+func (mr *MacroRegistry) StopRecording() error {
+	return mr.stopRecording(0)
+}
+
+// stopRecording stops the recording in progress, the last `skipLast` tokens of the event history are
+// not recorded, e.g. the token that triggered the stop of the recording.
+//
+// Parameters:
+//   - `skipLast` : Number of the most recent event history entries that should not be recorded
+//
+// Returns:
+//   - `error` : Returns an error when no recording is in progress
+func (mr *MacroRegistry) stopRecording(skipLast int) error {
+	if !mr.recording {
+		return fmt.Errorf("no macro recording is in progress")
+	}
+	end := mr.consoleApp.eventHistory.Len() - skipLast
+	tokens := []string{}
+	for i := mr.recordingStart; i < end; i++ {
+		if mr.isPlaybackIndex(i) {
+			continue
+		}
+		eventHistoryEntry, err := mr.consoleApp.eventHistory.RetrieveEventEntryByIndex(i)
+		if err != nil {
+			break
+		}
+		// Submitted lines are recorded by the line editor and not dispatched, thus they are no tokens
+		if eventHistoryEntry.EventName == SUBMITTED_LINE_EVENT_NAME {
+			continue
+		}
+		if eventHistoryEntry.Tokens != nil {
+			tokens = append(tokens, eventHistoryEntry.Tokens...)
+			continue
+		}
+		tokens = append(tokens, eventHistoryEntry.Token)
+	}
+	mr.macros[mr.recordingRegister] = tokens
+	mr.consoleApp.logger.Debug("Macro recording stopped", zap.String("register", mr.recordingRegister), zap.Int("tokens", len(tokens)), zap.String("func", "stopRecording"))
+	mr.recording = false
+	mr.recordingRegister = ""
+	mr.playbackRanges = nil
+	return nil
+}
+
+// isPlaybackIndex checks whether the event history entry at index was produced by a macro playback.
+//
+// Parameters:
+//   - `index` : Position in the event history
+//
+// Returns:
+//   - `bool` : True if the entry was produced by a playback
+func (mr *MacroRegistry) isPlaybackIndex(index int) bool {
+	for _, playbackRange := range mr.playbackRanges {
+		if index >= playbackRange[0] && index < playbackRange[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// SetMacro stores a sequence of tokens in the register, an existing macro is overwritten.
+//
+// Parameters:
+//   - `register` : Name of the register
+//   - `tokens` : Sequence of tokens that make up the macro
+func (mr *MacroRegistry) SetMacro(register string, tokens []string) {
+	mr.macros[register] = append([]string{}, tokens...)
+}
+
+// GetMacro returns the sequence of tokens stored in the register.
+//
+// Parameters:
+//   - `register` : Name of the register
+//
+// Returns:
+//   - `[]string` : Sequence of tokens that make up the macro
+//   - `error` : Returns an error when the register is empty
+func (mr *MacroRegistry) GetMacro(register string) ([]string, error) {
+	tokens, ok := mr.macros[register]
+	if !ok {
+		return nil, fmt.Errorf("no macro is recorded in register %v", register)
+	}
+	return append([]string{}, tokens...), nil
+}
+
+// Play replays the macro stored in the register n times. The tokens are sent through the normal
+// event dispatch, thus they are recorded in the event history and handled by the registered events.
+// A macro can play back other macros but not itself.
+//
+// Parameters:
+//   - `register` : Name of the register that should be played back
+//   - `n` : Number of times the macro is played back
+//
+// Returns:
+//   - `*ControlEvent` : The control event that stopped the playback, nil if the playback ran through
+//   - `error` : Returns an error when the register is empty, the playback is recursive or the dispatch failed
+func (mr *MacroRegistry) Play(register string, n int) (*ControlEvent, error) {
+	tokens, ok := mr.macros[register]
+	if !ok {
+		return nil, fmt.Errorf("no macro is recorded in register %v", register)
+	}
+	if mr.playing[register] {
+		return nil, fmt.Errorf("macro in register %v is already being played back, recursive playback is not allowed", register)
+	}
+	if len(mr.playing) >= maxMacroPlaybackDepth {
+		return nil, fmt.Errorf("macro playback depth of %v exceeded", maxMacroPlaybackDepth)
+	}
+
+	mr.playing[register] = true
+	defer delete(mr.playing, register)
+
+	start := mr.consoleApp.eventHistory.Len()
+	if mr.recording {
+		defer func() {
+			mr.playbackRanges = append(mr.playbackRanges, [2]int{start, mr.consoleApp.eventHistory.Len()})
+		}()
+	}
+
+	mr.consoleApp.logger.Debug("Macro playback started", zap.String("register", register), zap.Int("n", n), zap.String("func", "Play"))
+	for i := 0; i < n; i++ {
+		for _, token := range tokens {
+			controlEvent, err := mr.consoleApp.dispatchToken(token)
+			if err != nil {
+				return nil, err
+			}
+			if controlEvent != nil && controlEvent.Terminate {
+				return controlEvent, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// SaveToFile stores all macros as JSON in the file at path.
+//
+// Parameters:
+//   - `path` : Path of the file the macros are written to
+//
+// Returns:
+//   - `error` : Returns an error when the macros could not be encoded or written
+func (mr *MacroRegistry) SaveToFile(path string) error {
+	data, err := json.MarshalIndent(mr.macros, "", "  ")
+	if err != nil {
+		return fmt.Errorf("macros could not be encoded! error: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("macros could not be written to %v! error: %w", path, err)
+	}
+	return nil
+}
+
+// LoadFromFile loads macros from a JSON file at path that was written by SaveToFile. Loaded macros
+// overwrite macros that are stored in the same register.
+//
+// Parameters:
+//   - `path` : Path of the file the macros are read from
+//
+// Returns:
+//   - `error` : Returns an error when the file could not be read or decoded
+func (mr *MacroRegistry) LoadFromFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("macros could not be read from %v! error: %w", path, err)
+	}
+	macros := make(map[string][]string)
+	if err := json.Unmarshal(data, &macros); err != nil {
+		return fmt.Errorf("macros in %v could not be decoded! error: %w", path, err)
+	}
+	for register, tokens := range macros {
+		mr.macros[register] = tokens
+	}
+	return nil
+}
+
+// ToggleRecordingEvent returns an event that starts recording into the register and stops the
+// recording when triggered again. The tokens that toggle the recording are not recorded.
+//
+// Parameters:
+//   - `register` : Name of the register the macro is recorded into
+//
+// Returns:
+//   - `Event` : Event that toggles the recording
+func (mr *MacroRegistry) ToggleRecordingEvent(register string) Event {
+	return &macroRecordingEvent{macroRegistry: mr, register: register}
+}
+
+// PlaybackEvent returns an event that plays back the macro in the register n times.
+//
+// Parameters:
+//   - `register` : Name of the register that should be played back
+//   - `n` : Number of times the macro is played back
+//
+// Returns:
+//   - `Event` : Event that plays back the macro
+func (mr *MacroRegistry) PlaybackEvent(register string, n int) Event {
+	return &macroPlaybackEvent{macroRegistry: mr, register: register, n: n}
+}
+
+// macroRecordingEvent toggles the recording of a macro.
+type macroRecordingEvent struct {
+	macroRegistry *MacroRegistry
+	register      string
+}
+
+// Handle starts the recording if no recording is in progress, otherwise the recording is stopped.
+//
+// Parameters:
+//   - `token` : Token that triggered the event
+//
+// Returns:
+//   - `error` : Returns an error when the recording could not be toggled
+//   - `*ControlEvent` : Returns no control event in this case
+func (mre *macroRecordingEvent) Handle(token string) (error, *ControlEvent) {
+	if mre.macroRegistry.recording {
+		// The token that triggered this event is already recorded in the event history
+		return mre.macroRegistry.stopRecording(1), nil
+	}
+	return mre.macroRegistry.StartRecording(mre.register), nil
+}
+
+// macroPlaybackEvent plays back a macro.
+type macroPlaybackEvent struct {
+	macroRegistry *MacroRegistry
+	register      string
+	n             int
+}
+
+// Handle plays back the macro.
+//
+// Parameters:
+//   - `token` : Token that triggered the event
+//
+// Returns:
+//   - `error` : Returns an error when the playback failed
+//   - `*ControlEvent` : Returns the control event that stopped the playback
+func (mpe *macroPlaybackEvent) Handle(token string) (error, *ControlEvent) {
+	controlEvent, err := mpe.macroRegistry.Play(mpe.register, mpe.n)
+	return err, controlEvent
+}
@@ -0,0 +1,149 @@
+//go:build unit_test
+
+package cyclecmd_test
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/RaphSku/cyclecmd"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupMacroConsoleApp() (*cyclecmd.ConsoleApp, *cyclecmd.EventRegistry, *cyclecmd.EventHistory) {
+	eventRegistry := cyclecmd.NewEventRegistry(setupDefaultEventInformation())
+	eventHistory := cyclecmd.NewEventHistory()
+	consoleApp := cyclecmd.NewConsoleApp(
+		"test",
+		"0.1.0",
+		"This is a test console application",
+		eventRegistry,
+		eventHistory,
+	)
+	return consoleApp, eventRegistry, eventHistory
+}
+
+func TestMacroRecording(t *testing.T) {
+	t.Parallel()
+
+	consoleApp, _, eventHistory := setupMacroConsoleApp()
+	macroRegistry := cyclecmd.NewMacroRegistry(consoleApp)
+
+	eventHistory.AddEvent(cyclecmd.EventHistoryEntry{Token: "x", EventName: "Default", Event: &DefaultEvent{}})
+	err := macroRegistry.StartRecording("a")
+	assert.NoError(t, err)
+	recording, register := macroRegistry.IsRecording()
+	assert.True(t, recording)
+	assert.Equal(t, "a", register)
+
+	err = macroRegistry.StartRecording("b")
+	if assert.Error(t, err) {
+		assert.Equal(t, fmt.Errorf("macro recording into register %v is already in progress", "a"), err)
+	}
+
+	for _, token := range []string{"h", "i"} {
+		eventHistory.AddEvent(cyclecmd.EventHistoryEntry{Token: token, EventName: "Default", Event: &DefaultEvent{}})
+	}
+	err = macroRegistry.StopRecording()
+	assert.NoError(t, err)
+
+	actTokens, err := macroRegistry.GetMacro("a")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"h", "i"}, actTokens)
+
+	err = macroRegistry.StopRecording()
+	if assert.Error(t, err) {
+		assert.Equal(t, fmt.Errorf("no macro recording is in progress"), err)
+	}
+}
+
+func TestMacroPlayback(t *testing.T) {
+	t.Parallel()
+
+	consoleApp, _, eventHistory := setupMacroConsoleApp()
+	macroRegistry := cyclecmd.NewMacroRegistry(consoleApp)
+	macroRegistry.SetMacro("a", []string{"h", "i"})
+
+	actOutput, err := captureStdOutput(func() {
+		controlEvent, err := macroRegistry.Play("a", 2)
+		assert.NoError(t, err)
+		assert.Nil(t, controlEvent)
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "hihi", actOutput)
+	assert.Equal(t, 4, eventHistory.Len())
+
+	_, err = macroRegistry.Play("z", 1)
+	if assert.Error(t, err) {
+		assert.Equal(t, fmt.Errorf("no macro is recorded in register %v", "z"), err)
+	}
+}
+
+func TestMacroRecordsKeySequences(t *testing.T) {
+	t.Parallel()
+
+	consoleApp, eventRegistry, eventHistory := setupMacroConsoleApp()
+	macroRegistry := cyclecmd.NewMacroRegistry(consoleApp)
+	killedLines := 0
+	killLine := &WidgetEvent{run: func() (any, error) {
+		killedLines++
+		return nil, nil
+	}}
+	assert.NoError(t, eventRegistry.RegisterEventSequence([]string{"d", "d"}, cyclecmd.EventInformation{EventName: "KillLine", Event: killLine}))
+	assert.NoError(t, eventRegistry.RegisterEvent("q", cyclecmd.EventInformation{EventName: "Record", Event: macroRegistry.ToggleRecordingEvent("a")}))
+	assert.NoError(t, eventRegistry.RegisterEvent("@", cyclecmd.EventInformation{EventName: "Play", Event: macroRegistry.PlaybackEvent("a", 1)}))
+	consoleApp.SetInput(newTokenReader("q", "d", "d", "q", "@"))
+	consoleApp.SetOutput(&bytes.Buffer{})
+	consoleApp.Start()
+
+	actTokens, err := macroRegistry.GetMacro("a")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"d", "d"}, actTokens)
+	assert.Equal(t, 2, killedLines)
+	eventHistoryEntry, err := eventHistory.RetrieveEventEntryByIndex(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "d d", eventHistoryEntry.Token)
+	assert.Equal(t, "KillLine", eventHistoryEntry.EventName)
+}
+
+func TestRecursiveMacroPlayback(t *testing.T) {
+	t.Parallel()
+
+	consoleApp, eventRegistry, _ := setupMacroConsoleApp()
+	macroRegistry := cyclecmd.NewMacroRegistry(consoleApp)
+	err := eventRegistry.RegisterEvent("@", cyclecmd.EventInformation{
+		EventName: "PlayMacroA",
+		Event:     macroRegistry.PlaybackEvent("a", 1),
+	})
+	assert.NoError(t, err)
+	macroRegistry.SetMacro("a", []string{"h", "@"})
+
+	_, err = captureStdOutput(func() {
+		_, err := macroRegistry.Play("a", 1)
+		if assert.Error(t, err) {
+			assert.Equal(t, fmt.Errorf("macro in register %v is already being played back, recursive playback is not allowed", "a"), err)
+		}
+	})
+	assert.NoError(t, err)
+}
+
+func TestMacroSaveAndLoad(t *testing.T) {
+	t.Parallel()
+
+	consoleApp, _, _ := setupMacroConsoleApp()
+	macroRegistry := cyclecmd.NewMacroRegistry(consoleApp)
+	macroRegistry.SetMacro("a", []string{"h", "\x7f", "\r"})
+
+	path := filepath.Join(t.TempDir(), "macros.json")
+	err := macroRegistry.SaveToFile(path)
+	assert.NoError(t, err)
+
+	loadedMacroRegistry := cyclecmd.NewMacroRegistry(consoleApp)
+	err = loadedMacroRegistry.LoadFromFile(path)
+	assert.NoError(t, err)
+	actTokens, err := loadedMacroRegistry.GetMacro("a")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"h", "\x7f", "\r"}, actTokens)
+}
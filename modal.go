@@ -0,0 +1,38 @@
+package cyclecmd
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+// RunModal pushes the event registry and handles events until done returns true, afterwards the event registry
+// is popped again. It allows an event to wait synchronously for further input, e.g. a prompt widget that returns
+// the answer of the user to the event that opened it. It can only be called from within events.
+//
+// Parameters:
+//   - `eventRegistry` : Event registry that handles all tokens until done returns true
+//   - `done` : Is checked after every handled token, resize, async event and tick
+//
+// Returns:
+//   - `error` : Returns an error when the event loop is not running or concluded before done returned true
+func (ca *ConsoleApp) RunModal(eventRegistry *EventRegistry, done func() bool) error {
+	if !ca.running || ca.loop == nil {
+		return fmt.Errorf("modal event handling requires a running event loop")
+	}
+	ca.PushEventRegistry(eventRegistry)
+	defer ca.PopEventRegistry()
+	ca.logger.Debug("Modal event handling started", zap.String("func", "RunModal"))
+	for !done() {
+		reason, err, concluded := ca.processNext()
+		if !concluded {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("event loop concluded during modal event handling! error: %w", err)
+		}
+		return fmt.Errorf("event loop concluded during modal event handling, reason: %v", reason)
+	}
+	ca.logger.Debug("Modal event handling finished", zap.String("func", "RunModal"))
+	return nil
+}
@@ -0,0 +1,153 @@
+package netserver
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// sessionConn is the connection of a session as the console application sees it. The input is decoded according
+// to the protocol and reads time out once the session was idle for too long. Writes are serialized, since telnet
+// replies are written while the event loop writes its output.
+type sessionConn struct {
+	conn        net.Conn
+	protocol    Protocol
+	idleTimeout time.Duration
+	// resize is called with the window size the client reported, might be nil
+	resize func(width int, height int)
+	// writeMutex serializes the writes to the connection
+	writeMutex sync.Mutex
+	// telnet is the state of the telnet protocol
+	telnet telnetState
+	// afterCarriageReturn is true if the last byte that was read is a carriage return
+	afterCarriageReturn bool
+}
+
+// newSessionConn wraps the connection of a session.
+//
+// Parameters:
+//   - `conn` : Connection of the session
+//   - `protocol` : Protocol the connection is served with
+//   - `idleTimeout` : Time a read may wait for input, no timeout if it is not positive
+//
+// Returns:
+//   - `*sessionConn` : The wrapped connection
+func newSessionConn(conn net.Conn, protocol Protocol, idleTimeout time.Duration) *sessionConn {
+	return &sessionConn{conn: conn, protocol: protocol, idleTimeout: idleTimeout, telnet: newTelnetState()}
+}
+
+// Read reads and decodes the input of the client, it implements io.Reader. Enter arrives as CR LF or CR NUL from
+// telnet clients and as LF from line-buffered clients, it is always read as a carriage return like a terminal in
+// raw mode sends it.
+//
+// Parameters:
+//   - `p` : Buffer the decoded input is read into
+//
+// Returns:
+//   - `int` : Number of decoded bytes
+//   - `error` : ErrIdleTimeout once the session was idle for too long, io.EOF once the connection was closed
+func (sc *sessionConn) Read(p []byte) (int, error) {
+	chunk := make([]byte, len(p))
+	for {
+		if sc.idleTimeout > 0 {
+			sc.conn.SetReadDeadline(time.Now().Add(sc.idleTimeout))
+		}
+		n, err := sc.conn.Read(chunk)
+		decoded := sc.decode(chunk[:n], p)
+		// Telnet commands are consumed, so the read is repeated until input or an error arrives
+		if decoded > 0 || err != nil {
+			return decoded, sc.readError(err)
+		}
+	}
+}
+
+// decode decodes the input into the buffer.
+//
+// Parameters:
+//   - `input` : Bytes that were read from the connection
+//   - `p` : Buffer the decoded bytes are written to, it is at least as long as the input
+//
+// Returns:
+//   - `int` : Number of decoded bytes
+func (sc *sessionConn) decode(input []byte, p []byte) int {
+	n := 0
+	for _, b := range input {
+		if sc.protocol == PROTOCOL_TELNET {
+			var ok bool
+			if b, ok = sc.decodeTelnet(b); !ok {
+				continue
+			}
+		}
+		if sc.afterCarriageReturn && (b == '\n' || b == 0) {
+			sc.afterCarriageReturn = false
+			continue
+		}
+		sc.afterCarriageReturn = b == '\r'
+		if b == '\n' {
+			b = '\r'
+		}
+		p[n] = b
+		n++
+	}
+	return n
+}
+
+// readError translates the error of a read.
+//
+// Parameters:
+//   - `err` : Error of the read, might be nil
+//
+// Returns:
+//   - `error` : ErrIdleTimeout if the read timed out, io.EOF if the connection was closed, otherwise the error
+func (sc *sessionConn) readError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, os.ErrDeadlineExceeded):
+		fmt.Fprintf(sc, "\r\nsession closed after being idle for %v\r\n", sc.idleTimeout)
+		return fmt.Errorf("no input for %v! error: %w", sc.idleTimeout, ErrIdleTimeout)
+	case errors.Is(err, net.ErrClosed):
+		return io.EOF
+	}
+	return err
+}
+
+// Write writes the output of the console application to the client, it implements io.Writer. The byte 255 is
+// escaped for telnet clients.
+//
+// Parameters:
+//   - `p` : Output of the console application
+//
+// Returns:
+//   - `int` : Number of bytes of the output that were written
+//   - `error` : Returns an error when the connection failed
+func (sc *sessionConn) Write(p []byte) (int, error) {
+	output := p
+	if sc.protocol == PROTOCOL_TELNET {
+		output = escapeTelnet(p)
+	}
+	sc.writeMutex.Lock()
+	defer sc.writeMutex.Unlock()
+	if _, err := sc.conn.Write(output); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// writeCommand writes a telnet command to the client.
+//
+// Parameters:
+//   - `command` : Bytes of the command, starting with IAC
+//
+// Returns:
+//   - `error` : Returns an error when the connection failed
+func (sc *sessionConn) writeCommand(command ...byte) error {
+	sc.writeMutex.Lock()
+	defer sc.writeMutex.Unlock()
+	_, err := sc.conn.Write(command)
+	return err
+}
@@ -0,0 +1,168 @@
+// Package netserver serves console applications built with cyclecmd over plain TCP connections or Unix domain
+// sockets, e.g. in embedded and lab environments without SSH. Every connection runs its own console application.
+// Telnet clients negotiate the echo, character-at-a-time input and their window size, the raw protocol serves
+// clients like netcat that send the bytes as they are:
+//
+//	server := netserver.NewServer(netserver.PROTOCOL_TELNET, func(session netserver.Session) (*cyclecmd.ConsoleApp, error) {
+//		return newConsoleApp(), nil
+//	})
+//	server.SetMaxSessions(8)
+//	server.SetIdleTimeout(10 * time.Minute)
+//	server.ListenAndServe("tcp", ":2323")
+package netserver
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/RaphSku/cyclecmd"
+	"github.com/RaphSku/cyclecmd/internal/serve"
+	"github.com/RaphSku/cyclecmd/style"
+)
+
+// Protocol selects how the bytes of a connection are interpreted.
+type Protocol int
+
+const (
+	// PROTOCOL_TELNET negotiates ECHO, SGA and NAWS with the client, so that the console application echoes the
+	// input, receives every key right away and is resized with the window of the client.
+	PROTOCOL_TELNET Protocol = iota
+	// PROTOCOL_RAW passes the bytes as they are, line feeds are read as Enter, so that line-buffered clients like
+	// netcat can submit lines. Clients in raw mode, e.g. `stty raw -echo; nc host port`, get the full experience.
+	PROTOCOL_RAW
+)
+
+// ErrServerClosed is returned by Serve and ListenAndServe once the server was closed.
+var ErrServerClosed = errors.New("server was closed")
+
+// ErrIdleTimeout concludes the event loop of a session that did not receive input within the idle timeout.
+var ErrIdleTimeout = errors.New("session was idle for too long")
+
+// AppFactory creates a new console application for every connection, see cyclecmd.ConsoleApp.AttachRemoteTerminal.
+// The input, the output, the error output and the colour profile of the console application are set by the server.
+//
+// Parameters:
+//   - `session` : Session the console application is created for
+//
+// Returns:
+//   - `*cyclecmd.ConsoleApp` : Console application of the session
+//   - `error` : Rejects the connection, the error is reported to the client
+type AppFactory func(session Session) (*cyclecmd.ConsoleApp, error)
+
+// Session describes the connection a console application is created for.
+type Session struct {
+	// RemoteAddr is the address of the client
+	RemoteAddr net.Addr
+	// Protocol the client is served with
+	Protocol Protocol
+}
+
+// Server accepts connections and runs a console application for every connection.
+type Server struct {
+	protocol      Protocol
+	newConsoleApp AppFactory
+	acceptor      *serve.Acceptor
+	// idleTimeout concludes sessions without input, sessions never time out if it is not positive
+	idleTimeout time.Duration
+}
+
+// NewServer initialises a server that serves the connections with the protocol.
+//
+// Parameters:
+//   - `protocol` : PROTOCOL_TELNET or PROTOCOL_RAW
+//   - `newConsoleApp` : Creates the console application of every connection
+//
+// Returns:
+//   - `*Server` : Returns an instance of Server
+func NewServer(protocol Protocol, newConsoleApp AppFactory) *Server {
+	server := &Server{
+		protocol:      protocol,
+		newConsoleApp: newConsoleApp,
+	}
+	server.acceptor = serve.NewAcceptor("server", ErrServerClosed, server.handleConnection)
+	return server
+}
+
+// SetMaxSessions limits the number of concurrent sessions, further connections are told to try again later and
+// closed right away. It has to be set before the server is started.
+//
+// Parameters:
+//   - `maxSessions` : Maximum number of concurrent sessions, no limit if it is not positive
+func (s *Server) SetMaxSessions(maxSessions int) {
+	s.acceptor.SetMaxConnections(maxSessions, func(conn net.Conn) {
+		fmt.Fprintf(conn, "too many sessions, try again later\r\n")
+	})
+}
+
+// SetIdleTimeout sets the time a session may wait for input, the event loop of a session that exceeds it
+// concludes with ErrIdleTimeout and the connection is closed. It has to be set before the server is started.
+//
+// Parameters:
+//   - `idleTimeout` : Time without input, sessions never time out if it is not positive
+func (s *Server) SetIdleTimeout(idleTimeout time.Duration) {
+	s.idleTimeout = idleTimeout
+}
+
+// ListenAndServe listens on the address and serves the connections, see Serve.
+//
+// Parameters:
+//   - `network` : "tcp" for a TCP address or "unix" for the path of a Unix domain socket
+//   - `address` : Address to listen on, e.g. ":2323" or "/run/app.sock"
+//
+// Returns:
+//   - `error` : Returns an error when the server could not listen, ErrServerClosed once it was closed
+func (s *Server) ListenAndServe(network string, address string) error {
+	listener, err := net.Listen(network, address)
+	if err != nil {
+		return fmt.Errorf("server could not listen on %v! error: %w", address, err)
+	}
+	return s.Serve(listener)
+}
+
+// Serve accepts connections on the listener until the server is closed, every connection is handled in a
+// separate goroutine. The listener is closed once Serve returns.
+//
+// Parameters:
+//   - `listener` : Listener that accepts the connections
+//
+// Returns:
+//   - `error` : Returns an error when a connection could not be accepted, ErrServerClosed once it was closed
+func (s *Server) Serve(listener net.Listener) error {
+	return s.acceptor.Serve(listener)
+}
+
+// Close closes all listeners and connections and waits until the console applications of all sessions concluded.
+//
+// Returns:
+//   - `error` : Returns an error when a listener could not be closed
+func (s *Server) Close() error {
+	return s.acceptor.Close()
+}
+
+// handleConnection runs the console application of the connection until it concluded, the acceptor closes the
+// connection once it returns.
+//
+// Parameters:
+//   - `conn` : Accepted connection
+func (s *Server) handleConnection(conn net.Conn) {
+	sessionConn := newSessionConn(conn, s.protocol, s.idleTimeout)
+	consoleApp, err := s.newConsoleApp(Session{RemoteAddr: conn.RemoteAddr(), Protocol: s.protocol})
+	if err != nil {
+		fmt.Fprintf(sessionConn, "session could not be started! error: %v\r\n", err)
+		return
+	}
+	sessionConn.resize = consoleApp.Resize
+	if err := sessionConn.negotiate(); err != nil {
+		return
+	}
+
+	consoleApp.AttachRemoteTerminal(sessionConn, sessionConn)
+	if s.protocol == PROTOCOL_TELNET {
+		consoleApp.SetColorProfile(style.PROFILE_ANSI16)
+	} else {
+		consoleApp.SetColorProfile(style.PROFILE_NO_COLOR)
+	}
+	consoleApp.Start()
+}
@@ -0,0 +1,237 @@
+//go:build unit_test
+
+package netserver_test
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/RaphSku/cyclecmd"
+	"github.com/RaphSku/cyclecmd/netserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type DefaultEvent struct{}
+
+func (de *DefaultEvent) Handle(token string) (error, *cyclecmd.ControlEvent) {
+	return nil, nil
+}
+
+// client reads the output of a connection in the background, so that the test can wait for text.
+type client struct {
+	conn net.Conn
+
+	mutex  sync.Mutex
+	output strings.Builder
+	closed chan struct{}
+}
+
+func dial(t *testing.T, network string, address string) *client {
+	conn, err := net.Dial(network, address)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	c := &client{conn: conn, closed: make(chan struct{})}
+	go func() {
+		defer close(c.closed)
+		chunk := make([]byte, 256)
+		for {
+			n, err := conn.Read(chunk)
+			c.mutex.Lock()
+			c.output.Write(chunk[:n])
+			c.mutex.Unlock()
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return c
+}
+
+func (c *client) send(t *testing.T, input string) {
+	_, err := io.WriteString(c.conn, input)
+	require.NoError(t, err)
+}
+
+func (c *client) Output() string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.output.String()
+}
+
+func (c *client) waitFor(t *testing.T, text string) {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if strings.Contains(c.Output(), text) {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("output %q does not contain %q", c.Output(), text)
+}
+
+func (c *client) waitClosed(t *testing.T) {
+	select {
+	case <-c.closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("connection was not closed")
+	}
+}
+
+// newConsoleApp returns a factory of console applications that print every submitted line together with the number
+// of submitted lines and conclude on "exit", the size of the terminal is printed on every resize. Why the event
+// loops concluded is sent to exits.
+func newConsoleApp(exits chan<- error) netserver.AppFactory {
+	return func(session netserver.Session) (*cyclecmd.ConsoleApp, error) {
+		eventRegistry := cyclecmd.NewEventRegistry(cyclecmd.EventInformation{EventName: "Default", Event: &DefaultEvent{}})
+		consoleApp := cyclecmd.NewConsoleApp("test", "0.1.0", "This is a test console application", eventRegistry, cyclecmd.NewEventHistory())
+		consoleApp.DisableBanner()
+		lineEditor := cyclecmd.NewLineEditor(consoleApp, func(line string) (error, *cyclecmd.ControlEvent) {
+			if line == "exit" {
+				return nil, &cyclecmd.ControlEvent{Terminate: true}
+			}
+			fmt.Fprintf(consoleApp.Output(), "\r\nline: [%v] %v\r\n", line, len(consoleApp.EventHistory().SubmittedLines()))
+			return nil, nil
+		})
+		if err := lineEditor.RegisterEvents(eventRegistry); err != nil {
+			return nil, err
+		}
+		consoleApp.OnStart(func() error {
+			fmt.Fprintf(consoleApp.Output(), "start\xff\r\n")
+			return nil
+		})
+		consoleApp.OnResize(func(width int, height int) {
+			fmt.Fprintf(consoleApp.Output(), "size: %dx%d\r\n", width, height)
+		})
+		consoleApp.OnExit(func(reason cyclecmd.ExitReason, err error) {
+			if exits != nil {
+				exits <- err
+			}
+		})
+		return consoleApp, nil
+	}
+}
+
+func setupServer(t *testing.T, server *netserver.Server, network string) string {
+	address := "127.0.0.1:0"
+	if network == "unix" {
+		address = filepath.Join(t.TempDir(), "app.sock")
+	}
+	listener, err := net.Listen(network, address)
+	require.NoError(t, err)
+	served := make(chan error, 1)
+	go func() { served <- server.Serve(listener) }()
+	t.Cleanup(func() {
+		assert.NoError(t, server.Close())
+		assert.ErrorIs(t, <-served, netserver.ErrServerClosed)
+	})
+	return listener.Addr().String()
+}
+
+func TestServerRaw(t *testing.T) {
+	t.Parallel()
+
+	address := setupServer(t, netserver.NewServer(netserver.PROTOCOL_RAW, newConsoleApp(nil)), "unix")
+	alice, bob := dial(t, "unix", address), dial(t, "unix", address)
+	alice.waitFor(t, "start\xff\r\n")
+
+	// Line-buffered clients like netcat send lines terminated by LF
+	alice.send(t, "hello\nworld\r\n")
+	alice.waitFor(t, "line: [world] 2")
+	bob.send(t, "other\n")
+	bob.waitFor(t, "line: [other] 1")
+
+	alice.send(t, "exit\n")
+	alice.waitClosed(t)
+	assert.NotContains(t, alice.Output(), "other")
+}
+
+func TestServerTelnet(t *testing.T) {
+	t.Parallel()
+
+	address := setupServer(t, netserver.NewServer(netserver.PROTOCOL_TELNET, newConsoleApp(nil)), "tcp")
+	c := dial(t, "tcp", address)
+	c.waitFor(t, "\xff\xfb\x01\xff\xfb\x03\xff\xfd\x1f")
+	c.waitFor(t, "start\xff\xff\r\n")
+
+	// Acknowledge ECHO, SGA and NAWS, offer TTYPE and report the window size
+	c.send(t, "\xff\xfd\x01\xff\xfd\x03\xff\xfb\x1f\xff\xfb\x18\xff\xfd\x18")
+	c.send(t, "\xff\xfa\x1f\x00\x64\x00\x1e\xff\xf0")
+	c.waitFor(t, "size: 100x30")
+	// TTYPE is refused in both directions
+	c.waitFor(t, "\xff\xfe\x18")
+	c.waitFor(t, "\xff\xfc\x18")
+
+	// Enter is sent as CR NUL or CR LF, commands within the input are consumed
+	c.send(t, "a\xff\xf1b\r\x00c\r\n")
+	c.waitFor(t, "line: [ab] 1")
+	c.waitFor(t, "line: [c] 2")
+
+	// The client refuses to echo, the server acknowledges it once
+	c.send(t, "\xff\xfe\x01\xff\xfe\x01exit\r\n")
+	c.waitClosed(t)
+	assert.Equal(t, 1, strings.Count(c.Output(), "\xff\xfc\x01"))
+}
+
+func TestServerMaxSessions(t *testing.T) {
+	t.Parallel()
+
+	server := netserver.NewServer(netserver.PROTOCOL_RAW, newConsoleApp(nil))
+	server.SetMaxSessions(1)
+	address := setupServer(t, server, "tcp")
+
+	first := dial(t, "tcp", address)
+	first.waitFor(t, "start")
+	second := dial(t, "tcp", address)
+	second.waitClosed(t)
+	assert.Equal(t, "too many sessions, try again later\r\n", second.Output())
+
+	first.send(t, "still there\n")
+	first.waitFor(t, "line: [still there] 1")
+}
+
+func TestServerIdleTimeout(t *testing.T) {
+	t.Parallel()
+
+	exits := make(chan error, 1)
+	server := netserver.NewServer(netserver.PROTOCOL_RAW, newConsoleApp(exits))
+	server.SetIdleTimeout(50 * time.Millisecond)
+	address := setupServer(t, server, "tcp")
+
+	c := dial(t, "tcp", address)
+	c.waitClosed(t)
+	assert.Contains(t, c.Output(), "session closed after being idle for 50ms")
+	assert.ErrorIs(t, <-exits, netserver.ErrIdleTimeout)
+}
+
+func TestServerRejectsSession(t *testing.T) {
+	t.Parallel()
+
+	address := setupServer(t, netserver.NewServer(netserver.PROTOCOL_RAW, func(session netserver.Session) (*cyclecmd.ConsoleApp, error) {
+		return nil, fmt.Errorf("%v is not allowed", session.RemoteAddr.Network())
+	}), "tcp")
+	c := dial(t, "tcp", address)
+	c.waitClosed(t)
+	assert.Equal(t, "session could not be started! error: tcp is not allowed\r\n", c.Output())
+}
+
+func TestServerClose(t *testing.T) {
+	t.Parallel()
+
+	exits := make(chan error, 1)
+	server := netserver.NewServer(netserver.PROTOCOL_TELNET, newConsoleApp(exits))
+	address := setupServer(t, server, "tcp")
+	c := dial(t, "tcp", address)
+	c.waitFor(t, "start")
+
+	require.NoError(t, server.Close())
+	c.waitClosed(t)
+	assert.NoError(t, <-exits)
+	assert.ErrorIs(t, server.ListenAndServe("tcp", "127.0.0.1:0"), netserver.ErrServerClosed)
+}
@@ -0,0 +1,233 @@
+package netserver
+
+import "bytes"
+
+// Telnet commands, see RFC 854.
+const (
+	telnetSE   byte = 240
+	telnetIP   byte = 244
+	telnetSB   byte = 250
+	telnetWILL byte = 251
+	telnetWONT byte = 252
+	telnetDO   byte = 253
+	telnetDONT byte = 254
+	telnetIAC  byte = 255
+)
+
+// Telnet options the server supports.
+const (
+	// telnetOptionEcho lets the server echo the input, see RFC 857
+	telnetOptionEcho byte = 1
+	// telnetOptionSGA suppresses go ahead, so that the client sends every key right away, see RFC 858
+	telnetOptionSGA byte = 3
+	// telnetOptionNAWS lets the client report its window size, see RFC 1073
+	telnetOptionNAWS byte = 31
+)
+
+// maxSubnegotiationLength limits the bytes of a subnegotiation that are kept, longer subnegotiations are truncated.
+const maxSubnegotiationLength = 64
+
+// telnetStep is the step of the telnet decoder.
+type telnetStep int
+
+const (
+	// telnetStepData reads data
+	telnetStepData telnetStep = iota
+	// telnetStepCommand reads the command after IAC
+	telnetStepCommand
+	// telnetStepOption reads the option of WILL, WONT, DO or DONT
+	telnetStepOption
+	// telnetStepSubnegotiation reads the data of a subnegotiation
+	telnetStepSubnegotiation
+	// telnetStepSubnegotiationCommand reads the command after IAC within a subnegotiation
+	telnetStepSubnegotiationCommand
+)
+
+// telnetOptionState is the state of an option on one side of the connection, see RFC 1143.
+type telnetOptionState int
+
+const (
+	// telnetOptionOff means that the option is disabled
+	telnetOptionOff telnetOptionState = iota
+	// telnetOptionRequested means that the server asked to enable the option and waits for the answer
+	telnetOptionRequested
+	// telnetOptionOn means that the option is enabled
+	telnetOptionOn
+)
+
+// telnetState is the state of the telnet protocol of a connection.
+type telnetState struct {
+	step telnetStep
+	// command is the command whose option is read
+	command byte
+	// subnegotiation contains the data of the subnegotiation that is read
+	subnegotiation []byte
+	// local contains the states of the options the server supports, i.e. WILL and WONT
+	local map[byte]telnetOptionState
+	// remote contains the states of the options the server asks the client for, i.e. DO and DONT
+	remote map[byte]telnetOptionState
+}
+
+// newTelnetState initialises the state of the telnet protocol, all supported options are disabled.
+//
+// Returns:
+//   - `telnetState` : State of the telnet protocol
+func newTelnetState() telnetState {
+	return telnetState{
+		local:  map[byte]telnetOptionState{telnetOptionEcho: telnetOptionOff, telnetOptionSGA: telnetOptionOff},
+		remote: map[byte]telnetOptionState{telnetOptionNAWS: telnetOptionOff},
+	}
+}
+
+// negotiate asks a telnet client to let the server echo the input, to send every key right away and to report its
+// window size. The answers are handled while the input is read.
+//
+// Returns:
+//   - `error` : Returns an error when the connection failed
+func (sc *sessionConn) negotiate() error {
+	if sc.protocol != PROTOCOL_TELNET {
+		return nil
+	}
+	sc.telnet.local[telnetOptionEcho] = telnetOptionRequested
+	sc.telnet.local[telnetOptionSGA] = telnetOptionRequested
+	sc.telnet.remote[telnetOptionNAWS] = telnetOptionRequested
+	return sc.writeCommand(
+		telnetIAC, telnetWILL, telnetOptionEcho,
+		telnetIAC, telnetWILL, telnetOptionSGA,
+		telnetIAC, telnetDO, telnetOptionNAWS,
+	)
+}
+
+// decodeTelnet decodes a byte of the input, telnet commands are handled and consumed.
+//
+// Parameters:
+//   - `b` : Byte that was read
+//
+// Returns:
+//   - `byte` : Decoded byte, an interrupt is decoded as Ctrl-C
+//   - `bool` : False if the byte was consumed
+func (sc *sessionConn) decodeTelnet(b byte) (byte, bool) {
+	telnet := &sc.telnet
+	switch telnet.step {
+	case telnetStepData:
+		if b == telnetIAC {
+			telnet.step = telnetStepCommand
+			return 0, false
+		}
+		return b, true
+	case telnetStepCommand:
+		telnet.step = telnetStepData
+		switch b {
+		case telnetIAC:
+			return telnetIAC, true
+		case telnetIP:
+			return '\x03', true
+		case telnetWILL, telnetWONT, telnetDO, telnetDONT:
+			telnet.command = b
+			telnet.step = telnetStepOption
+		case telnetSB:
+			telnet.subnegotiation = telnet.subnegotiation[:0]
+			telnet.step = telnetStepSubnegotiation
+		}
+	case telnetStepOption:
+		telnet.step = telnetStepData
+		sc.handleOption(telnet.command, b)
+	case telnetStepSubnegotiation:
+		if b == telnetIAC {
+			telnet.step = telnetStepSubnegotiationCommand
+		} else {
+			telnet.appendSubnegotiation(b)
+		}
+	case telnetStepSubnegotiationCommand:
+		telnet.step = telnetStepSubnegotiation
+		switch b {
+		case telnetSE:
+			telnet.step = telnetStepData
+			sc.handleSubnegotiation(telnet.subnegotiation)
+		case telnetIAC:
+			telnet.appendSubnegotiation(telnetIAC)
+		}
+	}
+	return 0, false
+}
+
+// appendSubnegotiation appends a byte to the data of the subnegotiation unless it is too long.
+//
+// Parameters:
+//   - `b` : Byte of the subnegotiation
+func (ts *telnetState) appendSubnegotiation(b byte) {
+	if len(ts.subnegotiation) < maxSubnegotiationLength {
+		ts.subnegotiation = append(ts.subnegotiation, b)
+	}
+}
+
+// handleOption answers WILL, WONT, DO and DONT of the client. Answers to requests of the server are not answered
+// again and unsupported options are refused, so that the negotiation cannot loop.
+//
+// Parameters:
+//   - `command` : WILL, WONT, DO or DONT
+//   - `option` : Option the command refers to
+func (sc *sessionConn) handleOption(command byte, option byte) {
+	telnet := &sc.telnet
+	switch command {
+	case telnetDO, telnetDONT:
+		state, supported := telnet.local[option]
+		switch {
+		case command == telnetDO && !supported:
+			sc.writeCommand(telnetIAC, telnetWONT, option)
+		case command == telnetDO && state != telnetOptionOn:
+			telnet.local[option] = telnetOptionOn
+			if state == telnetOptionOff {
+				sc.writeCommand(telnetIAC, telnetWILL, option)
+			}
+		case command == telnetDONT && supported && state != telnetOptionOff:
+			telnet.local[option] = telnetOptionOff
+			if state == telnetOptionOn {
+				sc.writeCommand(telnetIAC, telnetWONT, option)
+			}
+		}
+	case telnetWILL, telnetWONT:
+		state, supported := telnet.remote[option]
+		switch {
+		case command == telnetWILL && !supported:
+			sc.writeCommand(telnetIAC, telnetDONT, option)
+		case command == telnetWILL && state != telnetOptionOn:
+			telnet.remote[option] = telnetOptionOn
+			if state == telnetOptionOff {
+				sc.writeCommand(telnetIAC, telnetDO, option)
+			}
+		case command == telnetWONT && supported && state != telnetOptionOff:
+			telnet.remote[option] = telnetOptionOff
+			if state == telnetOptionOn {
+				sc.writeCommand(telnetIAC, telnetDONT, option)
+			}
+		}
+	}
+}
+
+// handleSubnegotiation handles the window size the client reports via NAWS, other subnegotiations are ignored.
+//
+// Parameters:
+//   - `data` : Data of the subnegotiation, starting with the option
+func (sc *sessionConn) handleSubnegotiation(data []byte) {
+	if len(data) < 5 || data[0] != telnetOptionNAWS || sc.resize == nil {
+		return
+	}
+	width := int(data[1])<<8 | int(data[2])
+	height := int(data[3])<<8 | int(data[4])
+	sc.resize(width, height)
+}
+
+// escapeTelnet escapes the byte 255 of the output as IAC IAC.
+//
+// Parameters:
+//   - `output` : Output of the console application
+//
+// Returns:
+//   - `[]byte` : The escaped output
+func escapeTelnet(output []byte) []byte {
+	if bytes.IndexByte(output, telnetIAC) < 0 {
+		return output
+	}
+	return bytes.ReplaceAll(output, []byte{telnetIAC}, []byte{telnetIAC, telnetIAC})
+}
@@ -0,0 +1,306 @@
+package cyclecmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+
+	"github.com/RaphSku/cyclecmd/style"
+	"go.uber.org/zap"
+)
+
+// pagerTabWidth is the number of spaces a tab is expanded to.
+const pagerTabWidth = 8
+
+// Pager is a full-screen view like `less` that shows long output page by page. The last row shows the position
+// within the output or the search query.
+//
+// Keys:
+//   - Arrow Down, j, Enter : Scroll down one line
+//   - Arrow Up, k : Scroll up one line
+//   - Page Down, Space, f : Scroll down one page
+//   - Page Up, b : Scroll up one page
+//   - Home, g : Jump to the first line
+//   - End, G : Jump to the last page
+//   - / : Search forward, the query is case-insensitive unless it contains an upper case letter
+//   - n, N : Jump to the next or previous match
+//   - q, Escape, Ctrl-C : Quit
+type Pager struct {
+	consoleApp     *ConsoleApp
+	eventRegistry  *EventRegistry
+	searchRegistry *EventRegistry
+
+	// lines of the output without escape sequences
+	lines []string
+	// top is the index of the first visible line
+	top int
+	// query is the search query whose matches are highlighted
+	query []rune
+	// searchInput is typed by the user while the search prompt is open
+	searchInput []rune
+	// searching is true while the search prompt is open
+	searching bool
+	// message is shown in the last row instead of the position, e.g. when no match was found
+	message string
+	// done is true once the user quit the pager
+	done bool
+}
+
+// NewPager initialises a pager that shows everything that can be read from the reader.
+//
+// Parameters:
+//   - `consoleApp` : Console application the pager runs in
+//   - `content` : Reader that provides the output
+//
+// Returns:
+//   - `*Pager` : Returns an instance of Pager
+//   - `error` : Returns an error when the content could not be read
+func NewPager(consoleApp *ConsoleApp, content io.Reader) (*Pager, error) {
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return nil, fmt.Errorf("content of the pager could not be read! error: %w", err)
+	}
+	return NewStringPager(consoleApp, string(data)), nil
+}
+
+// NewStringPager initialises a pager that shows the string.
+//
+// Parameters:
+//   - `consoleApp` : Console application the pager runs in
+//   - `content` : The output
+//
+// Returns:
+//   - `*Pager` : Returns an instance of Pager
+func NewStringPager(consoleApp *ConsoleApp, content string) *Pager {
+	content = strings.ReplaceAll(style.Strip(content), "\r\n", "\n")
+	content = strings.TrimSuffix(content, "\n")
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		line = strings.ReplaceAll(line, "\t", strings.Repeat(" ", pagerTabWidth))
+		lines[i] = strings.TrimRight(line, "\r")
+	}
+
+	p := &Pager{consoleApp: consoleApp, lines: lines}
+	p.eventRegistry = NewEventRegistry(EventInformation{
+		EventName: "PagerKey",
+		Event:     &funcEvent{handle: p.handleKey},
+	})
+	// Keys with escape sequences are not passed to the default event, so they are registered explicitly
+	for _, key := range []string{KEY_ARROW_DOWN, KEY_ARROW_UP, KEY_PAGE_DOWN, KEY_PAGE_UP, KEY_HOME, KEY_END} {
+		p.eventRegistry.registry[key] = EventInformation{EventName: "PagerKey", Event: &funcEvent{handle: p.handleKey}}
+	}
+	p.searchRegistry = NewEventRegistry(EventInformation{
+		EventName: "PagerSearchQuery",
+		Event:     &funcEvent{handle: p.extendSearch},
+	})
+	p.searchRegistry.registry[KEY_BACKSPACE] = EventInformation{EventName: "PagerSearchBackspace", Event: &funcEvent{handle: p.shortenSearch}}
+	p.searchRegistry.registry[KEY_ENTER] = EventInformation{EventName: "PagerSearchAccept", Event: &funcEvent{handle: p.acceptSearch}}
+	p.searchRegistry.registry[KEY_ESCAPE] = EventInformation{EventName: "PagerSearchCancel", Event: &funcEvent{handle: p.cancelSearch}}
+	p.searchRegistry.registry[KEY_CTRL_C] = EventInformation{EventName: "PagerSearchCancel", Event: &funcEvent{handle: p.cancelSearch}}
+	return p
+}
+
+// TopLine returns the index of the first visible line.
+//
+// Returns:
+//   - `int` : Index of the first visible line
+func (p *Pager) TopLine() int {
+	return p.top
+}
+
+// Run shows the pager in the full-screen mode until the user quits it, afterwards the terminal shows the output
+// before the pager was opened again. If another view was shown in the full-screen mode, it is shown again.
+// It can only be called from within events.
+//
+// Returns:
+//   - `error` : Returns an error when the event loop concluded while the pager was shown
+func (p *Pager) Run() error {
+	previousView := p.consoleApp.view
+	p.done = false
+	if err := p.consoleApp.EnterFullScreen(p); err != nil {
+		return err
+	}
+	p.consoleApp.logger.Debug("Pager opened", zap.Int("lines", len(p.lines)), zap.String("func", "Run"))
+	err := p.consoleApp.RunModal(p.eventRegistry, func() bool { return p.done })
+	if previousView != nil {
+		p.consoleApp.EnterFullScreen(previousView)
+	} else if p.consoleApp.IsFullScreen() {
+		p.consoleApp.ExitFullScreen()
+	}
+	return err
+}
+
+// handleKey handles all keys while the search prompt is closed.
+func (p *Pager) handleKey(token string) (error, *ControlEvent) {
+	p.message = ""
+	page := p.pageHeight()
+	switch token {
+	case KEY_ARROW_DOWN, "j", KEY_ENTER:
+		p.scroll(1)
+	case KEY_ARROW_UP, "k":
+		p.scroll(-1)
+	case KEY_PAGE_DOWN, KEY_SPACE, "f":
+		p.scroll(page)
+	case KEY_PAGE_UP, "b":
+		p.scroll(-page)
+	case KEY_HOME, "g":
+		p.top = 0
+	case KEY_END, "G":
+		p.scroll(len(p.lines))
+	case "/":
+		p.searching = true
+		p.searchInput = nil
+		p.consoleApp.PushEventRegistry(p.searchRegistry)
+	case "n":
+		p.findMatch(1)
+	case "N":
+		p.findMatch(-1)
+	case "q", KEY_ESCAPE, KEY_CTRL_C:
+		p.done = true
+	}
+	return nil, nil
+}
+
+// extendSearch appends a printable token to the search query.
+func (p *Pager) extendSearch(token string) (error, *ControlEvent) {
+	if character, ok := printableCharacter(token); ok {
+		p.searchInput = append(p.searchInput, []rune(character)...)
+	}
+	return nil, nil
+}
+
+// shortenSearch removes the last character of the search query.
+func (p *Pager) shortenSearch(token string) (error, *ControlEvent) {
+	if len(p.searchInput) > 0 {
+		p.searchInput = p.searchInput[:len(p.searchInput)-1]
+	}
+	return nil, nil
+}
+
+// acceptSearch closes the search prompt and jumps to the first match below the first visible line.
+func (p *Pager) acceptSearch(token string) (error, *ControlEvent) {
+	p.searching = false
+	if len(p.searchInput) > 0 {
+		p.query = p.searchInput
+		p.findMatch(1)
+	}
+	return p.consoleApp.PopEventRegistry(), nil
+}
+
+// cancelSearch closes the search prompt without searching.
+func (p *Pager) cancelSearch(token string) (error, *ControlEvent) {
+	p.searching = false
+	return p.consoleApp.PopEventRegistry(), nil
+}
+
+// pageHeight returns the number of visible lines, the last row is reserved for the status.
+//
+// Returns:
+//   - `int` : Number of visible lines
+func (p *Pager) pageHeight() int {
+	_, height := p.consoleApp.Size()
+	return max(1, height-1)
+}
+
+// scroll moves the visible lines, the last page is the furthest it scrolls.
+//
+// Parameters:
+//   - `lines` : Number of lines to scroll, negative to scroll up
+func (p *Pager) scroll(lines int) {
+	p.top = max(0, min(p.top+lines, len(p.lines)-p.pageHeight()))
+}
+
+// findMatch moves the first visible line to the next line that matches the query.
+//
+// Parameters:
+//   - `direction` : 1 to search forward, -1 to search backward
+func (p *Pager) findMatch(direction int) {
+	if len(p.query) == 0 {
+		return
+	}
+	for i := p.top + direction; i >= 0 && i < len(p.lines); i += direction {
+		if len(p.matchesInLine(p.lines[i])) > 0 {
+			p.top = i
+			return
+		}
+	}
+	p.message = "Pattern not found"
+}
+
+// matchesInLine finds the positions of all matches of the query in the line. The query is case-insensitive unless
+// it contains an upper case letter.
+//
+// Parameters:
+//   - `line` : Line that is searched
+//
+// Returns:
+//   - `[]int` : Positions of the matches in runes
+func (p *Pager) matchesInLine(line string) []int {
+	if len(p.query) == 0 {
+		return nil
+	}
+	ignoreCase := true
+	for _, character := range p.query {
+		if unicode.IsUpper(character) {
+			ignoreCase = false
+		}
+	}
+	runes := []rune(line)
+	var positions []int
+	for start := 0; start+len(p.query) <= len(runes); start++ {
+		matches := true
+		for offset, character := range p.query {
+			lineCharacter := runes[start+offset]
+			if ignoreCase {
+				lineCharacter = unicode.ToLower(lineCharacter)
+			}
+			if lineCharacter != character {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			positions = append(positions, start)
+		}
+	}
+	return positions
+}
+
+// Render draws the visible lines with highlighted matches and the status in the last row.
+//
+// Parameters:
+//   - `frame` : Frame the pager draws into
+func (p *Pager) Render(frame *Frame) {
+	theme := p.consoleApp.theme
+	highlight := theme.Selection.WithReverse()
+	page := p.pageHeight()
+	p.scroll(0)
+
+	for row := 0; row < page && p.top+row < len(p.lines); row++ {
+		line := p.lines[p.top+row]
+		frame.SetString(0, row, line, style.New())
+		runes := []rune(line)
+		for _, position := range p.matchesInLine(line) {
+			column := displayWidth(string(runes[:position]))
+			frame.SetString(column, row, string(runes[position:position+len(p.query)]), highlight)
+		}
+		// Lines that are wider than the screen are cut off with an ellipsis
+		if displayWidth(line) > frame.Width() && frame.Width() > 0 {
+			frame.Set(frame.Width()-1, row, Cell{Rune: '…'})
+		}
+	}
+
+	status := p.message
+	if p.searching {
+		status = "/" + string(p.searchInput)
+	} else if status == "" {
+		last := min(p.top+page, len(p.lines))
+		status = fmt.Sprintf("lines %d-%d/%d", p.top+1, last, len(p.lines))
+		if last == len(p.lines) {
+			status += " (END)"
+		}
+	}
+	frame.Fill(0, frame.Height()-1, frame.Width(), 1, Cell{Rune: ' ', Style: theme.StatusLine})
+	frame.SetString(0, frame.Height()-1, truncateText(status, frame.Width()), theme.StatusLine)
+}
@@ -0,0 +1,105 @@
+//go:build unit_test
+
+package cyclecmd_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/RaphSku/cyclecmd"
+	"github.com/stretchr/testify/assert"
+)
+
+// PagerEvent opens the pager.
+type PagerEvent struct {
+	pager *cyclecmd.Pager
+	err   error
+}
+
+func (pe *PagerEvent) Handle(token string) (error, *cyclecmd.ControlEvent) {
+	pe.err = pe.pager.Run()
+	return nil, nil
+}
+
+func frameRow(frame *cyclecmd.Frame, row int) string {
+	var line strings.Builder
+	for x := 0; x < frame.Width(); x++ {
+		line.WriteRune(frame.Get(x, row).Rune)
+	}
+	return strings.TrimRight(line.String(), " ")
+}
+
+func runPager(t *testing.T, tokens ...string) (*cyclecmd.Pager, *PagerEvent, *bytes.Buffer) {
+	eventRegistry := cyclecmd.NewEventRegistry(setupDefaultEventInformation())
+	consoleApp := cyclecmd.NewConsoleApp("test", "0.1.0", "This is a test console application", eventRegistry, cyclecmd.NewEventHistory())
+	output := &bytes.Buffer{}
+	consoleApp.SetOutput(output)
+	consoleApp.SetInput(newTokenReader(append([]string{"p"}, tokens...)...))
+	consoleApp.DisableBanner()
+	consoleApp.Resize(12, 4)
+
+	var content strings.Builder
+	for i := 0; i < 10; i++ {
+		fmt.Fprintf(&content, "line %d\n", i)
+	}
+	content.WriteString("a Very long last line\n")
+	pager, err := cyclecmd.NewPager(consoleApp, strings.NewReader(content.String()))
+	assert.NoError(t, err)
+	pagerEvent := &PagerEvent{pager: pager}
+	err = eventRegistry.RegisterEvent("p", cyclecmd.EventInformation{EventName: "Pager", Event: pagerEvent})
+	assert.NoError(t, err)
+	consoleApp.Start()
+	return pager, pagerEvent, output
+}
+
+func TestPagerScrolling(t *testing.T) {
+	t.Parallel()
+
+	pager, pagerEvent, output := runPager(t, "\x1b[B", cyclecmd.KEY_SPACE, "\x1b[A", "q")
+	assert.NoError(t, pagerEvent.err)
+	assert.Equal(t, 3, pager.TopLine())
+	assert.True(t, strings.HasPrefix(output.String(), "\x1b[?1049h"))
+	assert.True(t, strings.HasSuffix(output.String(), "\x1b[?1049l"))
+
+	frame := cyclecmd.NewFrame(12, 4)
+	pager.Render(frame)
+	assert.Equal(t, "line 3", frameRow(frame, 0))
+	assert.Equal(t, "line 5", frameRow(frame, 2))
+	assert.Equal(t, "lines 4-6/11", frameRow(frame, 3))
+
+	pager, _, _ = runPager(t, "G", "q")
+	assert.Equal(t, 8, pager.TopLine())
+	pager.Render(frame)
+	assert.Equal(t, "a Very long…", frameRow(frame, 2))
+	assert.Equal(t, "lines 9-11/…", frameRow(frame, 3))
+
+	pager, _, _ = runPager(t, "G", "g", "q")
+	assert.Equal(t, 0, pager.TopLine())
+}
+
+func TestPagerSearch(t *testing.T) {
+	t.Parallel()
+
+	pager, pagerEvent, _ := runPager(t, append(append([]string{"/"}, typeTokens("line 6")...), cyclecmd.KEY_ENTER, "q")...)
+	assert.NoError(t, pagerEvent.err)
+	assert.Equal(t, 6, pager.TopLine())
+
+	frame := cyclecmd.NewFrame(12, 4)
+	pager.Render(frame)
+	assert.Equal(t, "line 6", frameRow(frame, 0))
+	assert.True(t, frame.Get(0, 0).Style.Reverse)
+	assert.False(t, frame.Get(0, 1).Style.Reverse)
+
+	// The query is case-insensitive since it contains no upper case letter
+	pager, _, _ = runPager(t, "/", "v", "e", "r", "y", cyclecmd.KEY_ENTER, "g", "n", "q")
+	assert.Equal(t, 8, pager.TopLine())
+
+	pager, _, output := runPager(t, "/", "V", "E", cyclecmd.KEY_ENTER, "q")
+	assert.Equal(t, 0, pager.TopLine())
+	assert.Contains(t, output.String(), "Pattern")
+
+	pager, _, _ = runPager(t, "/", "l", cyclecmd.KEY_ESCAPE, "n", "q")
+	assert.Equal(t, 0, pager.TopLine())
+}
@@ -0,0 +1,468 @@
+package cyclecmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/RaphSku/cyclecmd/style"
+	"go.uber.org/zap"
+)
+
+// spinnerInterval is the interval in which the spinner advances to its next frame.
+const spinnerInterval = 100 * time.Millisecond
+
+// maximumProgressBarWidth is the maximum width of the bar of a progress bar in columns.
+const maximumProgressBarWidth = 40
+
+// minimumProgressBarWidth is the minimum width of the bar of a progress bar, a narrower bar is not drawn.
+const minimumProgressBarWidth = 5
+
+// cancellingSuffix is appended to the line of an operation that was cancelled by the user but not stopped yet.
+const cancellingSuffix = " (cancelling)"
+
+// spinnerFrames are the frames the spinner cycles through.
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// progressLine is a line of a progress component above the prompt.
+type progressLine struct {
+	// render returns the content of the line for the width of the terminal
+	render func(width int) string
+	// cancel cancels the operation the line shows the progress of
+	cancel context.CancelFunc
+	// cancelled is true once the user cancelled the operation
+	cancelled bool
+	// drawn is the content that was drawn last
+	drawn string
+}
+
+// progressArea manages the lines of the progress components. The lines are kept directly above the prompt, so
+// that the line being typed stays in place. Before events that might print are handled, the lines are removed,
+// and they are drawn above the prompt again once the events were handled.
+type progressArea struct {
+	consoleApp *ConsoleApp
+	// mutex guards lines and messages, since progress components are started and stopped from other goroutines
+	mutex sync.Mutex
+	lines []*progressLine
+	// messages are printed once above the progress lines, e.g. the final message of a stopped spinner
+	messages []string
+	// shown is the number of lines that are drawn above the prompt, it is only accessed by the event loop
+	shown int
+	// cancelKey is the token that cancels the most recently started operation
+	cancelKey string
+}
+
+// newProgressArea initialises the progress area of the console application, Ctrl-C cancels operations.
+//
+// Parameters:
+//   - `consoleApp` : Console application the progress lines are drawn for
+//
+// Returns:
+//   - `*progressArea` : Returns an instance of progressArea
+func newProgressArea(consoleApp *ConsoleApp) *progressArea {
+	return &progressArea{consoleApp: consoleApp, cancelKey: KEY_CTRL_C}
+}
+
+// SetCancelKey sets the token that cancels the context of the most recently started spinner or progress bar
+// whose operation was not cancelled yet. The token is only interpreted as cancellation while such an operation
+// is running and no event registry is pushed, otherwise it is dispatched as usual. An empty token turns the
+// cancellation off.
+//
+// Parameters:
+//   - `token` : Token that cancels operations, KEY_CTRL_C by default
+func (ca *ConsoleApp) SetCancelKey(token string) {
+	ca.progress.mutex.Lock()
+	defer ca.progress.mutex.Unlock()
+	ca.progress.cancelKey = token
+}
+
+// add adds a line below the existing progress lines and notifies the event loop.
+//
+// Parameters:
+//   - `line` : Line that is added
+func (pa *progressArea) add(line *progressLine) {
+	pa.mutex.Lock()
+	pa.lines = append(pa.lines, line)
+	pa.mutex.Unlock()
+	pa.notify()
+}
+
+// remove removes the line and notifies the event loop.
+//
+// Parameters:
+//   - `line` : Line that is removed
+//   - `message` : Message that is printed once above the progress lines, nothing is printed if it is empty
+func (pa *progressArea) remove(line *progressLine, message string) {
+	pa.mutex.Lock()
+	for i, existingLine := range pa.lines {
+		if existingLine == line {
+			pa.lines = append(pa.lines[:i], pa.lines[i+1:]...)
+			break
+		}
+	}
+	if message != "" {
+		pa.messages = append(pa.messages, message)
+	}
+	pa.mutex.Unlock()
+	pa.notify()
+}
+
+// notify lets the event loop draw the progress lines again.
+func (pa *progressArea) notify() {
+	pa.consoleApp.post(asyncEvent{refreshProgress: true})
+}
+
+// cancelOperation cancels the most recently started operation that was not cancelled yet if the token is the
+// cancel key.
+//
+// Parameters:
+//   - `token` : Token that was read
+//
+// Returns:
+//   - `bool` : True if the token cancelled an operation and must not be dispatched
+func (pa *progressArea) cancelOperation(token string) bool {
+	pa.mutex.Lock()
+	defer pa.mutex.Unlock()
+	if token == "" || token != pa.cancelKey || len(pa.consoleApp.modalEventRegistries) > 0 {
+		return false
+	}
+	for i := len(pa.lines) - 1; i >= 0; i-- {
+		line := pa.lines[i]
+		if line.cancel != nil && !line.cancelled {
+			line.cancelled = true
+			line.cancel()
+			pa.consoleApp.logger.Debug("Operation cancelled", zap.String("func", "cancelOperation"))
+			return true
+		}
+	}
+	return false
+}
+
+// cancelAll cancels all operations and drops their lines, it is called once the event loop concluded, so that the
+// next event loop does not draw them again.
+func (pa *progressArea) cancelAll() {
+	pa.mutex.Lock()
+	defer pa.mutex.Unlock()
+	for _, line := range pa.lines {
+		if line.cancel != nil {
+			line.cancel()
+		}
+	}
+	pa.lines = nil
+}
+
+// hidden checks whether the progress lines cannot be drawn, that is the case in the full-screen mode and while
+// an event registry is pushed, since prompt widgets draw below the prompt.
+//
+// Returns:
+//   - `bool` : True if the progress lines are not drawn
+func (pa *progressArea) hidden() bool {
+	return pa.consoleApp.IsFullScreen() || len(pa.consoleApp.modalEventRegistries) > 0 || pa.consoleApp.batch.active
+}
+
+// hide removes the progress lines above the prompt, the prompt moves up and the cursor keeps its column.
+func (pa *progressArea) hide() {
+	if pa.shown == 0 || pa.consoleApp.IsFullScreen() {
+		return
+	}
+	fmt.Fprintf(pa.consoleApp.Output(), "\x1b[%dA%s\x1b[%dM%s", pa.shown, saveCursor, pa.shown, restoreCursor)
+	pa.shown = 0
+}
+
+// refresh draws the progress lines above the prompt. Lines that are already shown are only redrawn if their
+// content changed.
+func (pa *progressArea) refresh() {
+	if pa.hidden() {
+		return
+	}
+	pa.mutex.Lock()
+	defer pa.mutex.Unlock()
+	if pa.shown != len(pa.lines) || len(pa.messages) > 0 {
+		pa.hide()
+		pa.show()
+		return
+	}
+
+	width, _ := pa.consoleApp.Size()
+	var output strings.Builder
+	for i, line := range pa.lines {
+		content := line.content(width)
+		if content == line.drawn {
+			continue
+		}
+		line.drawn = content
+		fmt.Fprintf(&output, "%s\x1b[%dA\r%s%s%s", saveCursor, len(pa.lines)-i, clearLine, content, restoreCursor)
+	}
+	if output.Len() > 0 {
+		fmt.Fprint(pa.consoleApp.Output(), output.String())
+	}
+}
+
+// show inserts the messages and the progress lines above the prompt, the progress lines must be hidden and the
+// mutex must be locked.
+func (pa *progressArea) show() {
+	count := len(pa.messages) + len(pa.lines)
+	if count == 0 {
+		return
+	}
+	width, _ := pa.consoleApp.Size()
+	var output strings.Builder
+	// Index moves the cursor down and scrolls if the cursor is in the last row, so that there is room below the
+	// prompt. Afterwards, lines are inserted at the row of the prompt, which moves the prompt down.
+	output.WriteString(strings.Repeat("\x1bD", count))
+	fmt.Fprintf(&output, "\x1b[%dA%s\x1b[%dL%s\x1b[%dB%s\x1b[%dA", count, saveCursor, count, restoreCursor, count, saveCursor, count)
+	for _, message := range pa.messages {
+		output.WriteString("\r" + clearLine + truncateText(message, width-1) + "\r\n")
+	}
+	for _, line := range pa.lines {
+		line.drawn = line.content(width)
+		output.WriteString("\r" + clearLine + line.drawn + "\r\n")
+	}
+	output.WriteString(restoreCursor)
+	fmt.Fprint(pa.consoleApp.Output(), output.String())
+	pa.messages = nil
+	pa.shown = len(pa.lines)
+}
+
+// content renders the line for the width of the terminal, the last column is left empty so that the terminal
+// does not wrap the line.
+//
+// Parameters:
+//   - `width` : Width of the terminal in columns
+//
+// Returns:
+//   - `string` : Content of the line
+func (pl *progressLine) content(width int) string {
+	content := ""
+	if pl.cancelled {
+		// The suffix is reserved, so that the bar of a progress bar is shrunk instead of the suffix being cut off
+		content = pl.render(width-1-len(cancellingSuffix)) + cancellingSuffix
+	} else {
+		content = pl.render(width - 1)
+	}
+	if displayWidth(content) <= width-1 {
+		return content
+	}
+	return truncateText(style.Strip(content), width-1)
+}
+
+// Spinner is an animated line above the prompt that shows that an operation is running. The user keeps typing
+// while the spinner is shown, and can cancel the operation with the cancel key, see ConsoleApp.SetCancelKey.
+// All methods are safe to call from other goroutines.
+type Spinner struct {
+	consoleApp *ConsoleApp
+	line       *progressLine
+
+	// mutex guards message and frame
+	mutex   sync.Mutex
+	message string
+	frame   int
+
+	stopOnce sync.Once
+	stopped  chan struct{}
+}
+
+// NewSpinner initialises a spinner with a message, it is shown once it is started.
+//
+// Parameters:
+//   - `consoleApp` : Console application the spinner is shown in
+//   - `message` : Message next to the spinner
+//
+// Returns:
+//   - `*Spinner` : Returns an instance of Spinner
+func NewSpinner(consoleApp *ConsoleApp, message string) *Spinner {
+	s := &Spinner{consoleApp: consoleApp, message: message, stopped: make(chan struct{})}
+	s.line = &progressLine{render: s.render}
+	return s
+}
+
+// Start shows the spinner above the prompt and animates it until it is stopped. The returned context is
+// cancelled when the user presses the cancel key, when the spinner is stopped or when the event loop concluded,
+// the operation should observe it.
+//
+// Parameters:
+//   - `ctx` : Parent context of the operation
+//
+// Returns:
+//   - `context.Context` : Context of the operation
+func (s *Spinner) Start(ctx context.Context) context.Context {
+	ctx, s.line.cancel = context.WithCancel(ctx)
+	s.consoleApp.progress.add(s.line)
+	go func() {
+		ticker := time.NewTicker(spinnerInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.mutex.Lock()
+				s.frame = (s.frame + 1) % len(spinnerFrames)
+				s.mutex.Unlock()
+				s.consoleApp.progress.notify()
+			case <-ctx.Done():
+				return
+			case <-s.stopped:
+				return
+			}
+		}
+	}()
+	return ctx
+}
+
+// SetMessage replaces the message next to the spinner.
+//
+// Parameters:
+//   - `message` : Message next to the spinner
+func (s *Spinner) SetMessage(message string) {
+	s.mutex.Lock()
+	s.message = message
+	s.mutex.Unlock()
+	s.consoleApp.progress.notify()
+}
+
+// Stop removes the spinner and cancels the context of the operation.
+//
+// Parameters:
+//   - `message` : Message that is printed in place of the spinner, nothing is printed if it is empty
+func (s *Spinner) Stop(message string) {
+	s.stopOnce.Do(func() {
+		close(s.stopped)
+		if s.line.cancel != nil {
+			s.line.cancel()
+		}
+		s.consoleApp.progress.remove(s.line, message)
+	})
+}
+
+// render renders the current frame and the message.
+//
+// Parameters:
+//   - `width` : Available width in columns
+//
+// Returns:
+//   - `string` : Content of the line
+func (s *Spinner) render(width int) string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.consoleApp.render(s.consoleApp.theme.Progress, spinnerFrames[s.frame]) + " " + s.message
+}
+
+// ProgressBar is a line above the prompt that shows how much of an operation is done. The user keeps typing
+// while the progress bar is shown, and can cancel the operation with the cancel key, see
+// ConsoleApp.SetCancelKey. All methods are safe to call from other goroutines.
+type ProgressBar struct {
+	consoleApp *ConsoleApp
+	line       *progressLine
+
+	// mutex guards message, current and total
+	mutex   sync.Mutex
+	message string
+	current int64
+	total   int64
+
+	stopOnce sync.Once
+}
+
+// NewProgressBar initialises a progress bar with a message, it is shown once it is started.
+//
+// Parameters:
+//   - `consoleApp` : Console application the progress bar is shown in
+//   - `message` : Message in front of the bar
+//   - `total` : Amount of work of the operation, only the amount of done work is shown if it is not positive
+//
+// Returns:
+//   - `*ProgressBar` : Returns an instance of ProgressBar
+func NewProgressBar(consoleApp *ConsoleApp, message string, total int64) *ProgressBar {
+	p := &ProgressBar{consoleApp: consoleApp, message: message, total: total}
+	p.line = &progressLine{render: p.render}
+	return p
+}
+
+// Start shows the progress bar above the prompt. The returned context is cancelled when the user presses the
+// cancel key, when the progress bar is stopped or when the event loop concluded, the operation should observe it.
+//
+// Parameters:
+//   - `ctx` : Parent context of the operation
+//
+// Returns:
+//   - `context.Context` : Context of the operation
+func (p *ProgressBar) Start(ctx context.Context) context.Context {
+	ctx, p.line.cancel = context.WithCancel(ctx)
+	p.consoleApp.progress.add(p.line)
+	return ctx
+}
+
+// Set sets the amount of work that is done.
+//
+// Parameters:
+//   - `current` : Amount of work that is done
+func (p *ProgressBar) Set(current int64) {
+	p.mutex.Lock()
+	p.current = current
+	p.mutex.Unlock()
+	p.consoleApp.progress.notify()
+}
+
+// Add adds to the amount of work that is done.
+//
+// Parameters:
+//   - `delta` : Amount of work that was done since the last update
+func (p *ProgressBar) Add(delta int64) {
+	p.mutex.Lock()
+	p.current += delta
+	p.mutex.Unlock()
+	p.consoleApp.progress.notify()
+}
+
+// SetMessage replaces the message in front of the bar.
+//
+// Parameters:
+//   - `message` : Message in front of the bar
+func (p *ProgressBar) SetMessage(message string) {
+	p.mutex.Lock()
+	p.message = message
+	p.mutex.Unlock()
+	p.consoleApp.progress.notify()
+}
+
+// Stop removes the progress bar and cancels the context of the operation.
+//
+// Parameters:
+//   - `message` : Message that is printed in place of the progress bar, nothing is printed if it is empty
+func (p *ProgressBar) Stop(message string) {
+	p.stopOnce.Do(func() {
+		if p.line.cancel != nil {
+			p.line.cancel()
+		}
+		p.consoleApp.progress.remove(p.line, message)
+	})
+}
+
+// render renders the message, the bar and the percentage of work that is done.
+//
+// Parameters:
+//   - `width` : Available width in columns
+//
+// Returns:
+//   - `string` : Content of the line
+func (p *ProgressBar) render(width int) string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.total <= 0 {
+		return fmt.Sprintf("%s %d", p.message, p.current)
+	}
+	current := max(0, min(p.current, p.total))
+	percentage := fmt.Sprintf("%3d%%", current*100/p.total)
+	// The message, the brackets, the percentage and the spaces in between take up the rest of the line
+	barWidth := min(maximumProgressBarWidth, width-displayWidth(p.message)-len(percentage)-4)
+	if barWidth < minimumProgressBarWidth {
+		return p.message + " " + percentage
+	}
+	filled := int(int64(barWidth) * current / p.total)
+	bar := strings.Repeat("=", filled)
+	if filled < barWidth {
+		bar += ">" + strings.Repeat(" ", barWidth-filled-1)
+	}
+	return p.message + " [" + p.consoleApp.render(p.consoleApp.theme.Progress, bar) + "] " + percentage
+}
@@ -0,0 +1,119 @@
+//go:build unit_test
+
+package cyclecmd_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/RaphSku/cyclecmd"
+	"github.com/stretchr/testify/assert"
+)
+
+// ProgressEvent calls a function with the console application, e.g. to start or stop a progress component.
+type ProgressEvent struct {
+	run func()
+}
+
+func (pe *ProgressEvent) Handle(token string) (error, *cyclecmd.ControlEvent) {
+	pe.run()
+	return nil, nil
+}
+
+func setupProgressConsoleApp(t *testing.T, tokens ...string) (*cyclecmd.ConsoleApp, *cyclecmd.EventRegistry, *cyclecmd.EventHistory, *bytes.Buffer) {
+	eventRegistry := cyclecmd.NewEventRegistry(setupDefaultEventInformation())
+	eventHistory := cyclecmd.NewEventHistory()
+	consoleApp := cyclecmd.NewConsoleApp("test", "0.1.0", "This is a test console application", eventRegistry, eventHistory)
+	output := &bytes.Buffer{}
+	consoleApp.SetOutput(output)
+	consoleApp.SetInput(newTokenReader(tokens...))
+	consoleApp.DisableBanner()
+	consoleApp.Resize(40, 10)
+	return consoleApp, eventRegistry, eventHistory, output
+}
+
+func TestProgressBar(t *testing.T) {
+	t.Parallel()
+
+	consoleApp, eventRegistry, eventHistory, output := setupProgressConsoleApp(t, "p", cyclecmd.KEY_CTRL_C, "s", cyclecmd.KEY_CTRL_C)
+	var progressBar *cyclecmd.ProgressBar
+	var ctx context.Context
+	err := eventRegistry.RegisterEvent("p", cyclecmd.EventInformation{EventName: "Progress", Event: &ProgressEvent{run: func() {
+		progressBar = cyclecmd.NewProgressBar(consoleApp, "copy", 10)
+		ctx = progressBar.Start(context.Background())
+		progressBar.Set(5)
+	}}})
+	assert.NoError(t, err)
+	err = eventRegistry.RegisterEvent("s", cyclecmd.EventInformation{EventName: "Stop", Event: &ProgressEvent{run: func() {
+		assert.ErrorIs(t, ctx.Err(), context.Canceled)
+		progressBar.Stop("copied")
+	}}})
+	assert.NoError(t, err)
+	consoleApp.Start()
+
+	// The line is inserted above the prompt and the cursor returns to the prompt
+	bar := "copy [" + strings.Repeat("=", 13) + ">" + strings.Repeat(" ", 13) + "]  50%"
+	assert.Contains(t, output.String(), "\x1bD\x1b[1A\x1b7\x1b[1L\x1b8\x1b[1B\x1b7\x1b[1A\r\x1b[2K"+bar+"\r\n\x1b8")
+	// The first Ctrl-C cancels the operation instead of being dispatched
+	cancelledBar := "copy [" + strings.Repeat("=", 7) + ">" + strings.Repeat(" ", 6) + "]  50% (cancelling)"
+	assert.Contains(t, output.String(), "\x1b7\x1b[1A\r\x1b[2K"+cancelledBar+"\x1b8")
+	// Stopping removes the line and prints the message above the prompt
+	assert.Contains(t, output.String(), "\x1b[1A\x1b7\x1b[1M\x1b8")
+	assert.Contains(t, output.String(), "\r\x1b[2Kcopied\r\n\x1b8")
+	assert.Equal(t, 3, eventHistory.Len())
+	entry, err := eventHistory.RetrieveEventEntryByIndex(2)
+	assert.NoError(t, err)
+	assert.Equal(t, cyclecmd.KEY_CTRL_C, entry.Token)
+}
+
+func TestSpinner(t *testing.T) {
+	t.Parallel()
+
+	consoleApp, eventRegistry, _, output := setupProgressConsoleApp(t, "p", "m", "s")
+	var spinner *cyclecmd.Spinner
+	var ctx context.Context
+	err := eventRegistry.RegisterEvent("p", cyclecmd.EventInformation{EventName: "Spinner", Event: &ProgressEvent{run: func() {
+		spinner = cyclecmd.NewSpinner(consoleApp, "working")
+		ctx = spinner.Start(context.Background())
+	}}})
+	assert.NoError(t, err)
+	err = eventRegistry.RegisterEvent("m", cyclecmd.EventInformation{EventName: "Message", Event: &ProgressEvent{run: func() {
+		spinner.SetMessage("still working")
+	}}})
+	assert.NoError(t, err)
+	err = eventRegistry.RegisterEvent("s", cyclecmd.EventInformation{EventName: "Stop", Event: &ProgressEvent{run: func() {
+		spinner.Stop("done")
+	}}})
+	assert.NoError(t, err)
+	consoleApp.Start()
+
+	assert.Contains(t, output.String(), "working\r\n\x1b8")
+	assert.Contains(t, output.String(), "still working")
+	assert.Contains(t, output.String(), "\r\x1b[2Kdone\r\n\x1b8")
+	assert.ErrorIs(t, ctx.Err(), context.Canceled)
+}
+
+func TestProgressCancelledOnExit(t *testing.T) {
+	t.Parallel()
+
+	consoleApp, eventRegistry, _, output := setupProgressConsoleApp(t, "p")
+	var ctx context.Context
+	err := eventRegistry.RegisterEvent("p", cyclecmd.EventInformation{EventName: "Progress", Event: &ProgressEvent{run: func() {
+		ctx = cyclecmd.NewProgressBar(consoleApp, "download", 0).Start(context.Background())
+	}}})
+	assert.NoError(t, err)
+	consoleApp.Start()
+
+	assert.Contains(t, output.String(), "download 0")
+	assert.ErrorIs(t, ctx.Err(), context.Canceled)
+	// The line is removed once the event loop concluded
+	assert.True(t, strings.HasSuffix(output.String(), "\x1b[1A\x1b7\x1b[1M\x1b8"))
+
+	// The next event loop does not draw the line of the cancelled operation again
+	output.Reset()
+	consoleApp.SetInput(newTokenReader("x"))
+	consoleApp.Start()
+	assert.NotContains(t, output.String(), "download")
+}
@@ -0,0 +1,251 @@
+package cyclecmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// RC_DISABLE_ENV is the environment variable that disables loading the rc file if it is set to any value.
+const RC_DISABLE_ENV string = "CYCLECMD_NORC"
+
+// rcFileName is the name of the rc file within the configuration directory of the console application.
+const rcFileName = "rc"
+
+// maximumSourceDepth limits how deeply files can source further files, so that a file that sources itself fails.
+const maximumSourceDepth = 16
+
+// builtinCommands returns the built-in commands besides `help`, sourced files can always use them while the
+// command line offers them only once they are enabled, see EnableBuiltins:
+//   - `source <file>` : Executes the lines of the file as commands
+//   - `alias [<name> <command>...]` : Defines an alias for a command line, lists all aliases without arguments
+//   - `bind <key> <event>` : Binds the event with the name to the key, e.g. `bind ctrl+s Submit`
+//
+// Returns:
+//   - `[]*Command` : The built-in commands
+func (cr *CommandRegistry) builtinCommands() []*Command {
+	return []*Command{
+		{
+			Name:        "source",
+			Description: "Executes the lines of a file as commands",
+			Run: func(args []string) (error, *ControlEvent) {
+				if len(args) != 1 {
+					return fmt.Errorf("source expects a single file, got %v arguments", len(args)), nil
+				}
+				return cr.Source(args[0])
+			},
+			ArgumentCompleters: []Completer{NewFilePathCompleter()},
+		},
+		{
+			Name:        "alias",
+			Description: "Defines an alias for a command line",
+			Run: func(args []string) (error, *ControlEvent) {
+				if len(args) == 0 {
+					return cr.printAliases(), nil
+				}
+				if len(args) == 1 {
+					return fmt.Errorf("alias %v has no command", args[0]), nil
+				}
+				return cr.RegisterAlias(args[0], strings.Join(args[1:], " ")), nil
+			},
+		},
+		{
+			Name:        "bind",
+			Description: "Binds an event to a key",
+			Run: func(args []string) (error, *ControlEvent) {
+				if len(args) != 2 {
+					return fmt.Errorf("bind expects a key and an event name, got %v arguments", len(args)), nil
+				}
+				token, err := ParseKey(args[0])
+				if err != nil {
+					return err, nil
+				}
+				return cr.consoleApp.eventRegistry.RebindEvent(token, args[1]), nil
+			},
+		},
+	}
+}
+
+// EnableBuiltins registers the built-in commands `source`, `alias` and `bind`, so that they can be executed from
+// the command line. `source` reads any file the process can read, thus the built-in commands must not be enabled
+// for console applications that serve remote users.
+//
+// Returns:
+//   - `error` : Returns an error when a command is already registered under the name of a built-in command
+func (cr *CommandRegistry) EnableBuiltins() error {
+	var errs []error
+	for _, command := range cr.builtinCommands() {
+		errs = append(errs, cr.RegisterCommand(*command))
+	}
+	return errors.Join(errs...)
+}
+
+// builtinCommand returns the built-in command with the name.
+//
+// Parameters:
+//   - `name` : Name of the built-in command
+//
+// Returns:
+//   - `*Command` : The built-in command
+//   - `bool` : False if there is no built-in command with the name
+func (cr *CommandRegistry) builtinCommand(name string) (*Command, bool) {
+	for _, command := range cr.builtinCommands() {
+		if command.Name == name {
+			return command, true
+		}
+	}
+	return nil, false
+}
+
+// RegisterAlias registers an alias, a line that starts with the alias is executed as if it started with the
+// command line instead, e.g. the alias `ll` for `list --long` executes `ll docs` as `list --long docs`.
+//
+// Parameters:
+//   - `name` : Name of the alias, it is the first word of the line
+//   - `commandLine` : Command line the alias stands for
+//
+// Returns:
+//   - `error` : Returns an error when the name is invalid or is the name of a command
+func (cr *CommandRegistry) RegisterAlias(name string, commandLine string) error {
+	if name == "" || strings.ContainsAny(name, " \t") {
+		return fmt.Errorf("alias name %q is invalid, it must not be empty or contain whitespace", name)
+	}
+	if _, ok := cr.commands[name]; ok {
+		return fmt.Errorf("alias %v would shadow the command %v", name, name)
+	}
+	if strings.TrimSpace(commandLine) == "" {
+		return fmt.Errorf("alias %v has no command", name)
+	}
+	cr.aliases[name] = commandLine
+	return nil
+}
+
+// printAliases prints all aliases sorted by their name as a table.
+//
+// Returns:
+//   - `error` : Returns an error when the table could not be printed
+func (cr *CommandRegistry) printAliases() error {
+	names := make([]string, 0, len(cr.aliases))
+	for name := range cr.aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	table := NewTable("Alias", "Command")
+	for _, name := range names {
+		if err := table.AddRow(name, cr.aliases[name]); err != nil {
+			return err
+		}
+	}
+	return cr.consoleApp.PrintTable(table)
+}
+
+// Source executes the lines of the file as commands through the same path as submitted lines, so that they are
+// recorded in the event history and the event hooks are called. The built-in commands can be used even if they
+// are not enabled, see EnableBuiltins. Empty lines and lines starting with # are skipped. All lines are executed
+// even if a line fails, like a shell executes its rc file.
+//
+// Parameters:
+//   - `path` : Path of the file
+//
+// Returns:
+//   - `error` : Returns an error when the file could not be read or lines failed, the error names the file and
+//     the line of every failed line but does not contain the content of unknown lines
+//   - `*ControlEvent` : Control event of the line that terminated, the remaining lines are skipped
+func (cr *CommandRegistry) Source(path string) (error, *ControlEvent) {
+	if cr.sourceDepth >= maximumSourceDepth {
+		return fmt.Errorf("source is nested too deeply, the maximum depth is %v", maximumSourceDepth), nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("file could not be sourced! error: %w", err), nil
+	}
+	defer file.Close()
+
+	cr.consoleApp.logger.Debug("Sourcing file", zap.String("path", path), zap.String("func", "Source"))
+	cr.sourceDepth++
+	defer func() { cr.sourceDepth-- }()
+	controlEvent, err := cr.consoleApp.runScript(file, path, cr.executeSourcedLine, true)
+	return err, controlEvent
+}
+
+// executeSourcedLine executes a line of a sourced file, see Source.
+//
+// Parameters:
+//   - `line` : Line that should be executed
+//
+// Returns:
+//   - `error` : Returns an error when the command is unknown or the command failed
+//   - `*ControlEvent` : Control event returned by the command
+func (cr *CommandRegistry) executeSourcedLine(line string) (error, *ControlEvent) {
+	return cr.execute(line, true)
+}
+
+// RCFilePath returns the path of the rc file of the console application, `~/.config/<Name>/rc`. The
+// configuration directory is taken from XDG_CONFIG_HOME if it is set.
+//
+// Returns:
+//   - `string` : Path of the rc file
+//   - `error` : Returns an error when the console application has no name or the home directory is unknown
+func (ca *ConsoleApp) RCFilePath() (string, error) {
+	if ca.Name == "" {
+		return "", fmt.Errorf("rc file could not be located, the console application has no name")
+	}
+	configDirectory := os.Getenv("XDG_CONFIG_HOME")
+	if configDirectory == "" {
+		homeDirectory, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("rc file could not be located! error: %w", err)
+		}
+		configDirectory = filepath.Join(homeDirectory, ".config")
+	}
+	return filepath.Join(configDirectory, ca.Name, rcFileName), nil
+}
+
+// LoadRCFile sources the rc file of the console application, see RCFilePath and Source. The rc file usually
+// contains key bindings, aliases and commands to run. A missing rc file is skipped. Loading is disabled when the
+// environment variable RC_DISABLE_ENV is set, e.g. so that the rc file of the user does not change the behaviour
+// of tests.
+//
+// Returns:
+//   - `error` : Returns an error when the rc file could not be read or lines failed
+func (cr *CommandRegistry) LoadRCFile() error {
+	if os.Getenv(RC_DISABLE_ENV) != "" {
+		cr.consoleApp.logger.Debug("Loading the rc file is disabled", zap.String("func", "LoadRCFile"))
+		return nil
+	}
+	path, err := cr.consoleApp.RCFilePath()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		cr.consoleApp.logger.Debug("No rc file found", zap.String("path", path), zap.String("func", "LoadRCFile"))
+		return nil
+	}
+	err, _ = cr.Source(path)
+	return err
+}
+
+// EnableRCFile loads the rc file once the console application starts, see LoadRCFile. Errors of the rc file are
+// printed to the error output and passed to the OnError hooks, but do not prevent the start.
+func (cr *CommandRegistry) EnableRCFile() {
+	cr.consoleApp.OnStart(func() error {
+		err := cr.LoadRCFile()
+		if err == nil {
+			return nil
+		}
+		cr.consoleApp.runErrorHooks(err)
+		lineEnding := "\r\n"
+		if cr.consoleApp.batchEnabled() {
+			lineEnding = "\n"
+		}
+		for _, line := range strings.Split(err.Error(), "\n") {
+			fmt.Fprintf(cr.consoleApp.ErrorOutput(), "error: %v%v", line, lineEnding)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,140 @@
+//go:build unit_test
+
+package cyclecmd_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/RaphSku/cyclecmd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupRCCommandRegistry(t *testing.T) (*cyclecmd.CommandRegistry, *cyclecmd.ConsoleApp, *cyclecmd.EventRegistry, *bytes.Buffer) {
+	eventRegistry := cyclecmd.NewEventRegistry(setupDefaultEventInformation())
+	require.NoError(t, eventRegistry.RegisterEvent("t", cyclecmd.EventInformation{EventName: "Test", Event: &TestEvent{}}))
+	consoleApp := cyclecmd.NewConsoleApp("test", "0.1.0", "This is a test console application", eventRegistry, cyclecmd.NewEventHistory())
+	output := &bytes.Buffer{}
+	consoleApp.SetOutput(output)
+	commandRegistry := cyclecmd.NewCommandRegistry(consoleApp)
+	err := commandRegistry.RegisterCommand(cyclecmd.Command{
+		Name:        "greet",
+		Description: "Greets the user",
+		Run: func(args []string) (error, *cyclecmd.ControlEvent) {
+			fmt.Fprintf(consoleApp.Output(), "Hello %v\n", strings.Join(args, " "))
+			return nil, nil
+		},
+	})
+	require.NoError(t, err)
+	return commandRegistry, consoleApp, eventRegistry, output
+}
+
+func writeScript(t *testing.T, directory string, name string, lines ...string) string {
+	path := filepath.Join(directory, name)
+	require.NoError(t, os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o644))
+	return path
+}
+
+func TestSource(t *testing.T) {
+	t.Parallel()
+
+	commandRegistry, consoleApp, eventRegistry, output := setupRCCommandRegistry(t)
+	directory := t.TempDir()
+	nestedPath := writeScript(t, directory, "nested", "greet from nested", "unknown")
+	rcPath := writeScript(t, directory, "rc",
+		"# key bindings",
+		"bind ctrl+t Test",
+		"bind ctrl+t",
+		"alias hi greet Jane",
+		"",
+		"hi Doe",
+		"source "+nestedPath,
+		"greet John",
+	)
+
+	// The built-in commands are not registered unless they are enabled
+	err, _ := commandRegistry.Execute("source " + rcPath)
+	assert.EqualError(t, err, "command source is not registered")
+
+	err, controlEvent := commandRegistry.Source(rcPath)
+	assert.Nil(t, controlEvent)
+	expErr := rcPath + ":3: bind expects a key and an event name, got 1 arguments\n" +
+		rcPath + ":7: " + nestedPath + ":2: line does not start with a registered command"
+	if assert.Error(t, err) {
+		assert.Equal(t, expErr, err.Error())
+	}
+	assert.Equal(t, "Hello Jane Doe\nHello from nested\nHello John\n", output.String())
+	assert.Equal(t, []string{"bind ctrl+t Test", "bind ctrl+t", "alias hi greet Jane", "hi Doe", "source " + nestedPath, "greet from nested", "unknown", "greet John"}, consoleApp.EventHistory().SubmittedLines())
+	eventInformation, err := eventRegistry.GetMatchingEventInformation("\x14")
+	assert.NoError(t, err)
+	assert.Equal(t, "Test", eventInformation.EventName)
+
+	assert.NoError(t, commandRegistry.EnableBuiltins())
+	assert.Error(t, commandRegistry.EnableBuiltins())
+	output.Reset()
+	err, _ = commandRegistry.Execute("alias")
+	assert.NoError(t, err)
+	assert.Equal(t, "Alias\tCommand\nhi\tgreet Jane\n", output.String())
+	assert.EqualError(t, commandRegistry.RegisterAlias("greet", "help"), "alias greet would shadow the command greet")
+
+	loopPath := filepath.Join(directory, "loop")
+	writeScript(t, directory, "loop", "source "+loopPath)
+	err, _ = commandRegistry.Source(loopPath)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "source is nested too deeply, the maximum depth is 16")
+	}
+	err, _ = commandRegistry.Execute("source")
+	assert.EqualError(t, err, "source expects a single file, got 0 arguments")
+}
+
+func TestRCFile(t *testing.T) {
+	commandRegistry, consoleApp, _, output := setupRCCommandRegistry(t)
+	directory := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", directory)
+	t.Setenv(cyclecmd.RC_DISABLE_ENV, "")
+	rcPath, err := consoleApp.RCFilePath()
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(directory, "test", "rc"), rcPath)
+	assert.NoError(t, commandRegistry.LoadRCFile())
+	require.NoError(t, os.MkdirAll(filepath.Dir(rcPath), 0o755))
+	writeScript(t, filepath.Dir(rcPath), "rc", "greet from rc")
+
+	commandRegistry.EnableRCFile()
+	consoleApp.SetInput(newTokenReader())
+	consoleApp.DisableBanner()
+	consoleApp.Start()
+	assert.Equal(t, "Hello from rc\n", output.String())
+
+	output.Reset()
+	t.Setenv(cyclecmd.RC_DISABLE_ENV, "1")
+	assert.NoError(t, commandRegistry.LoadRCFile())
+	assert.Empty(t, output.String())
+}
+
+func TestRCFileErrorsOfRemoteTerminal(t *testing.T) {
+	commandRegistry, consoleApp, _, _ := setupRCCommandRegistry(t)
+	directory := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", directory)
+	t.Setenv(cyclecmd.RC_DISABLE_ENV, "")
+	require.NoError(t, os.MkdirAll(filepath.Join(directory, "test"), 0o755))
+	writeScript(t, filepath.Join(directory, "test"), "rc", "unknown")
+
+	output, errorOutput := &bytes.Buffer{}, &bytes.Buffer{}
+	terminal := struct {
+		io.Reader
+		io.Writer
+	}{newTokenReader(), output}
+	consoleApp.AttachRemoteTerminal(terminal, errorOutput)
+	consoleApp.DisableBanner()
+	commandRegistry.EnableRCFile()
+	consoleApp.Start()
+	// The errors are printed to the remote terminal instead of Stderr of the server
+	assert.Contains(t, errorOutput.String(), "error: "+filepath.Join(directory, "test", "rc")+":1: line does not start with a registered command\r\n")
+	assert.NotContains(t, output.String(), "error")
+}
@@ -0,0 +1,243 @@
+package cyclecmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// defaultConfigPollInterval is the interval in which the watched configuration files are checked for changes.
+const defaultConfigPollInterval = time.Second
+
+// watchedFile is a configuration file that is reloaded whenever it changes while the event loop is running.
+type watchedFile struct {
+	path string
+	// stamp identifies the version of the file that was loaded last
+	stamp fileStamp
+	// load reads and validates the file, the returned function applies it and is called between events
+	load func() (func(), error)
+}
+
+// fileStamp identifies a version of a file by its modification time and size.
+type fileStamp struct {
+	exists  bool
+	modTime time.Time
+	size    int64
+}
+
+// configWatcher polls the watched files in a separate goroutine.
+type configWatcher struct {
+	// stop is closed to stop polling
+	stop chan struct{}
+	// done is closed once polling stopped
+	done chan struct{}
+}
+
+// SetConfigPollInterval sets the interval in which the watched configuration files are checked for changes, one
+// second by default. It can be called before the event loop starts or from within events.
+//
+// Parameters:
+//   - `interval` : Interval between two checks, a non-positive interval turns watching off
+func (ca *ConsoleApp) SetConfigPollInterval(interval time.Duration) {
+	ca.configPollInterval = interval
+	if ca.running {
+		ca.stopConfigWatcher()
+		ca.startConfigWatcher()
+	}
+}
+
+// WatchKeyBindings installs the defaults merged with the keybinding file, see InstallKeyBindings, and installs them
+// again whenever the file changes while the event loop is running, so that the user sees changes without a
+// restart. The file is read and validated in the background, the key bindings are only replaced between events.
+// Bindings the file no longer contains fall back to the defaults, if the file is removed only the defaults stay
+// installed. An invalid file keeps the previous key bindings. Every reload is announced in the status line and
+// passed to the OnConfigReload hooks.
+//
+// Parameters:
+//   - `path` : Path of the keybinding file, it does not need to exist yet
+//   - `defaults` : Default key bindings of the console application
+//   - `catalog` : Catalog of the events that can be bound
+//
+// Returns:
+//   - `error` : Returns an error when the defaults or the keybinding file are invalid, the file is watched
+//     nonetheless unless the defaults are invalid
+func (ca *ConsoleApp) WatchKeyBindings(path string, defaults KeyBindings, catalog *EventCatalog) error {
+	if err := defaults.Validate(catalog); err != nil {
+		return fmt.Errorf("default key bindings are invalid! error: %w", err)
+	}
+	snapshot := ca.installKeyBindings(defaults, catalog)
+
+	return ca.watchFile(path, func() (func(), error) {
+		userKeyBindings, err := LoadKeyBindings(path)
+		if errors.Is(err, os.ErrNotExist) {
+			userKeyBindings, err = KeyBindings{}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		keyBindings := defaults.Merge(userKeyBindings)
+		if err := keyBindings.Validate(catalog); err != nil {
+			return nil, fmt.Errorf("keybinding file %v is invalid! error: %w", path, err)
+		}
+		return func() {
+			ca.revertKeyBindings(snapshot)
+			snapshot = ca.installKeyBindings(keyBindings, catalog)
+		}, nil
+	})
+}
+
+// WatchTheme sets the theme of the theme file, see LoadTheme, and sets it again whenever the file changes while
+// the event loop is running. The file is read and validated in the background, the theme is only replaced
+// between events. If the file is removed, the theme that was set before watching is restored. An invalid file
+// keeps the previous theme. Every reload is announced in the status line and passed to the OnConfigReload hooks.
+//
+// Parameters:
+//   - `path` : Path of the theme file, it does not need to exist yet
+//
+// Returns:
+//   - `error` : Returns an error when the theme file is invalid, the file is watched nonetheless
+func (ca *ConsoleApp) WatchTheme(path string) error {
+	previousTheme := ca.Theme()
+
+	return ca.watchFile(path, func() (func(), error) {
+		theme, err := LoadTheme(path)
+		if errors.Is(err, os.ErrNotExist) {
+			theme, err = previousTheme, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return func() {
+			ca.SetTheme(theme)
+			ca.screen.Redraw()
+		}, nil
+	})
+}
+
+// watchFile loads the configuration file and adds it to the watched files.
+//
+// Parameters:
+//   - `path` : Path of the configuration file
+//   - `load` : Reads and validates the file, the returned function applies it
+//
+// Returns:
+//   - `error` : Returns an error when the file could not be loaded
+func (ca *ConsoleApp) watchFile(path string, load func() (func(), error)) error {
+	file := &watchedFile{path: path, stamp: statFile(path), load: load}
+	apply, err := load()
+	if err == nil {
+		apply()
+	}
+
+	ca.stopConfigWatcher()
+	ca.watchedFiles = append(ca.watchedFiles, file)
+	if ca.running {
+		ca.startConfigWatcher()
+	}
+	return err
+}
+
+// startConfigWatcher starts polling the watched files, it is called once the event loop starts.
+func (ca *ConsoleApp) startConfigWatcher() {
+	if len(ca.watchedFiles) == 0 || ca.configPollInterval <= 0 {
+		return
+	}
+	ca.configWatcher = &configWatcher{stop: make(chan struct{}), done: make(chan struct{})}
+	files := append([]*watchedFile(nil), ca.watchedFiles...)
+	go ca.pollConfigFiles(files, ca.configPollInterval, ca.configWatcher)
+}
+
+// stopConfigWatcher stops polling and waits until the polling goroutine returned, it is called once the event
+// loop concluded.
+func (ca *ConsoleApp) stopConfigWatcher() {
+	if ca.configWatcher == nil {
+		return
+	}
+	close(ca.configWatcher.stop)
+	<-ca.configWatcher.done
+	ca.configWatcher = nil
+}
+
+// pollConfigFiles checks the files for changes in the interval until it is stopped. Changed files are loaded right
+// away and applied by the event loop.
+//
+// Parameters:
+//   - `files` : Files that are checked
+//   - `interval` : Interval between two checks
+//   - `watcher` : Stops the polling
+func (ca *ConsoleApp) pollConfigFiles(files []*watchedFile, interval time.Duration, watcher *configWatcher) {
+	defer close(watcher.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-watcher.stop:
+			return
+		case <-ticker.C:
+		}
+		for _, file := range files {
+			stamp := statFile(file.path)
+			if stamp.equal(file.stamp) {
+				continue
+			}
+			file.stamp = stamp
+			ca.logger.Debug("Configuration file changed", zap.String("path", file.path), zap.String("func", "pollConfigFiles"))
+			apply, err := file.load()
+			path := file.path
+			ca.Post(func() (error, *ControlEvent) {
+				ca.applyConfig(path, apply, err)
+				return nil, nil
+			})
+		}
+	}
+}
+
+// applyConfig applies a reloaded configuration file and announces the reload in the status line.
+//
+// Parameters:
+//   - `path` : Path of the configuration file
+//   - `apply` : Applies the file, it is nil if the file is invalid
+//   - `err` : The error if the file is invalid
+func (ca *ConsoleApp) applyConfig(path string, apply func(), err error) {
+	if err != nil {
+		ca.logger.Debug("Configuration file is invalid", zap.String("path", path), zap.Error(err), zap.String("func", "applyConfig"))
+		firstLine, _, _ := strings.Cut(err.Error(), "\n")
+		ca.screen.SetStatus(fmt.Sprintf("%v is invalid, the previous configuration is kept: %v", filepath.Base(path), firstLine))
+	} else {
+		ca.logger.Debug("Configuration file reloaded", zap.String("path", path), zap.String("func", "applyConfig"))
+		apply()
+		ca.screen.SetStatus(fmt.Sprintf("Reloaded %v", filepath.Base(path)))
+	}
+	ca.runConfigReloadHooks(path, err)
+}
+
+// equal returns whether both stamps identify the same version of a file.
+//
+// Parameters:
+//   - `other` : Stamp that is compared
+//
+// Returns:
+//   - `bool` : True if the file did not change
+func (fs fileStamp) equal(other fileStamp) bool {
+	return fs.exists == other.exists && fs.modTime.Equal(other.modTime) && fs.size == other.size
+}
+
+// statFile returns the stamp of the current version of the file.
+//
+// Parameters:
+//   - `path` : Path of the file
+//
+// Returns:
+//   - `fileStamp` : Stamp of the file, the zero value if it does not exist
+func statFile(path string) fileStamp {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}
+	}
+	return fileStamp{exists: true, modTime: info.ModTime(), size: info.Size()}
+}
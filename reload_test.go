@@ -0,0 +1,162 @@
+//go:build unit_test
+
+package cyclecmd_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/RaphSku/cyclecmd"
+	"github.com/RaphSku/cyclecmd/style"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeConfigFile replaces the file atomically like an editor does and sets the modification time, so that the
+// change is detected once even if the file system has a coarse time resolution.
+func writeConfigFile(t *testing.T, path string, content string, modTime time.Time) {
+	temporaryPath := path + ".tmp"
+	require.NoError(t, os.WriteFile(temporaryPath, []byte(content), 0o644))
+	require.NoError(t, os.Chtimes(temporaryPath, modTime, modTime))
+	require.NoError(t, os.Rename(temporaryPath, path))
+}
+
+func waitForReload(t *testing.T, reloads <-chan error) error {
+	select {
+	case err := <-reloads:
+		return err
+	case <-time.After(5 * time.Second):
+		t.Fatal("configuration file was not reloaded")
+		return nil
+	}
+}
+
+func TestWatchKeyBindings(t *testing.T) {
+	t.Parallel()
+
+	var handled []string
+	eventRegistry := cyclecmd.NewEventRegistry(setupDefaultEventInformation())
+	consoleApp := cyclecmd.NewConsoleApp("test", "0.1.0", "This is a test console application", eventRegistry, cyclecmd.NewEventHistory())
+	output := &bytes.Buffer{}
+	consoleApp.SetOutput(output)
+	consoleApp.SetConfigPollInterval(5 * time.Millisecond)
+	reloads := make(chan error, 1)
+	consoleApp.OnConfigReload(func(path string, err error) {
+		reloads <- err
+	})
+
+	eventCatalog := setupEventCatalog(&handled, "Save", "Delete")
+	defaults := cyclecmd.KeyBindings{"default": {"ctrl+s": "Save"}}
+	path := filepath.Join(t.TempDir(), "keys.yaml")
+	modTime := time.Now()
+	writeConfigFile(t, path, "default:\n  ctrl+x: Delete\n", modTime)
+
+	assert.Error(t, consoleApp.WatchKeyBindings(path, cyclecmd.KeyBindings{"default": {"ctrl+s": "Unknown"}}, eventCatalog))
+	require.NoError(t, consoleApp.WatchKeyBindings(path, defaults, eventCatalog))
+
+	inputReader, inputWriter := io.Pipe()
+	consoleApp.SetInput(inputReader)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		consoleApp.Start()
+	}()
+
+	writeConfigFile(t, path, "default:\n  ctrl+y: Delete\n", modTime.Add(time.Second))
+	assert.NoError(t, waitForReload(t, reloads))
+	writeConfigFile(t, path, "default:\n  ctrl+y: Unknown\n", modTime.Add(2*time.Second))
+	assert.Error(t, waitForReload(t, reloads))
+
+	for _, token := range []string{"\x18", "\x19", "\x13"} {
+		_, err := inputWriter.Write([]byte(token))
+		require.NoError(t, err)
+	}
+	inputWriter.Close()
+	<-done
+
+	assert.Equal(t, []string{"Delete", "Save"}, handled)
+	assert.Contains(t, output.String(), "Reloaded keys.yaml")
+	assert.Contains(t, output.String(), "keys.yaml is invalid, the previous configuration is kept")
+}
+
+func TestWatchTheme(t *testing.T) {
+	t.Parallel()
+
+	consoleApp := cyclecmd.NewConsoleApp("test", "0.1.0", "This is a test console application", cyclecmd.NewEventRegistry(setupDefaultEventInformation()), cyclecmd.NewEventHistory())
+	consoleApp.SetOutput(&bytes.Buffer{})
+	consoleApp.SetTheme(style.PlainTheme())
+	consoleApp.SetConfigPollInterval(5 * time.Millisecond)
+	reloads := make(chan error, 1)
+	consoleApp.OnConfigReload(func(path string, err error) {
+		reloads <- err
+	})
+
+	path := filepath.Join(t.TempDir(), "theme.toml")
+	require.NoError(t, consoleApp.WatchTheme(path))
+	assert.Equal(t, "plain", consoleApp.Theme().Name)
+
+	var themes []style.Theme
+	inputReader, inputWriter := io.Pipe()
+	consoleApp.SetInput(inputReader)
+	consoleApp.AfterEvent(func(eventHistoryEntry cyclecmd.EventHistoryEntry) *cyclecmd.ControlEvent {
+		themes = append(themes, consoleApp.Theme())
+		return nil
+	})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		consoleApp.Start()
+	}()
+
+	modTime := time.Now()
+	writeConfigFile(t, path, "name = \"custom\"\nbase = \"colorful\"\n\n[styles.prompt]\nfg = \"#00ff00\"\n", modTime)
+	assert.NoError(t, waitForReload(t, reloads))
+	_, err := inputWriter.Write([]byte("a"))
+	require.NoError(t, err)
+
+	writeConfigFile(t, path, "[styles.prompt]\nfg = \"orange\"\n", modTime.Add(time.Second))
+	assert.Error(t, waitForReload(t, reloads))
+	_, err = inputWriter.Write([]byte("b"))
+	require.NoError(t, err)
+
+	require.NoError(t, os.Remove(path))
+	assert.NoError(t, waitForReload(t, reloads))
+	_, err = inputWriter.Write([]byte("c"))
+	require.NoError(t, err)
+	inputWriter.Close()
+	<-done
+
+	require.Len(t, themes, 3)
+	assert.Equal(t, "custom", themes[0].Name)
+	assert.Equal(t, style.New().Fg(style.RGBColor(0, 255, 0)), themes[0].Prompt)
+	assert.Equal(t, style.ColorfulTheme().Error, themes[0].Error)
+	assert.Equal(t, "custom", themes[1].Name)
+	assert.Equal(t, "plain", themes[2].Name)
+}
+
+func TestParseTheme(t *testing.T) {
+	t.Parallel()
+
+	theme, err := cyclecmd.ParseTheme([]byte("styles:\n  status_line:\n    fg: black\n    bg: bright-cyan\n    bold: true\n"), cyclecmd.CONFIG_FORMAT_YAML)
+	require.NoError(t, err)
+	assert.Equal(t, "default", theme.Name)
+	assert.Equal(t, style.New().Fg(style.Black).Bg(style.BrightCyan).WithBold(), theme.StatusLine)
+	assert.Equal(t, style.DefaultTheme().Prompt, theme.Prompt)
+
+	theme, err = cyclecmd.ParseTheme([]byte(`{"base": "plain", "styles": {"error": {"fg": "196"}}}`), cyclecmd.CONFIG_FORMAT_JSON)
+	require.NoError(t, err)
+	assert.Equal(t, style.New().Fg(style.ANSI256Color(196)), theme.Error)
+
+	_, err = cyclecmd.ParseTheme([]byte("base: unknown\n"), cyclecmd.CONFIG_FORMAT_YAML)
+	assert.Error(t, err)
+
+	_, err = cyclecmd.ParseTheme([]byte("styles:\n  banner:\n    fg: red\n  prompt:\n    fg: orange\n"), cyclecmd.CONFIG_FORMAT_YAML)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "style banner is unknown")
+		assert.Contains(t, err.Error(), "style prompt is invalid")
+	}
+}
@@ -0,0 +1,17 @@
+//go:build !windows
+
+package cyclecmd
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// notifyResize relays the SIGWINCH signal that is sent when the terminal is resized to the channel.
+//
+// Parameters:
+//   - `c` : Channel that receives the resize signals
+func notifyResize(c chan<- os.Signal) {
+	signal.Notify(c, syscall.SIGWINCH)
+}
@@ -0,0 +1,13 @@
+//go:build windows
+
+package cyclecmd
+
+import (
+	"os"
+)
+
+// notifyResize does nothing on Windows since there is no signal that is sent when the console is resized.
+//
+// Parameters:
+//   - `c` : Channel that receives the resize signals
+func notifyResize(c chan<- os.Signal) {}
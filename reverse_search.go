@@ -0,0 +1,198 @@
+package cyclecmd
+
+import (
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// reverseSearch is the reverse incremental history search of the line editor (Ctrl-R). While the search is
+// active, it takes over the event dispatch by pushing its own event registry onto the console application.
+type reverseSearch struct {
+	lineEditor    *LineEditor
+	eventRegistry *EventRegistry
+
+	// query is the search query typed by the user
+	query []rune
+	// match is the submitted line that matches the query, empty if no line matches
+	match string
+	// matchIndex is the index of the match within the submitted lines
+	matchIndex int
+	// failed is true if no submitted line matches the query
+	failed bool
+
+	// savedLine and savedCursor restore the line editor when the search is cancelled
+	savedLine   string
+	savedCursor int
+}
+
+// newReverseSearch initialises the reverse history search for the line editor.
+//
+// Parameters:
+//   - `lineEditor` : Line editor whose submitted lines are searched
+//
+// Returns:
+//   - `*reverseSearch` : Returns an instance of reverseSearch
+func newReverseSearch(lineEditor *LineEditor) *reverseSearch {
+	rs := &reverseSearch{lineEditor: lineEditor}
+	rs.eventRegistry = NewEventRegistry(EventInformation{
+		EventName: "ReverseSearchQuery",
+		Event:     &funcEvent{handle: rs.extendQuery},
+	})
+	rs.eventRegistry.registry[KEY_CTRL_R] = EventInformation{
+		EventName: "ReverseSearchOlder",
+		Event:     &funcEvent{handle: rs.older},
+	}
+	rs.eventRegistry.registry[KEY_BACKSPACE] = EventInformation{
+		EventName: "ReverseSearchBackspace",
+		Event:     &funcEvent{handle: rs.shortenQuery},
+	}
+	rs.eventRegistry.registry[KEY_ENTER] = EventInformation{
+		EventName: "ReverseSearchAccept",
+		Event:     &funcEvent{handle: rs.accept},
+	}
+	rs.eventRegistry.registry[KEY_ESCAPE] = EventInformation{
+		EventName: "ReverseSearchCancel",
+		Event:     &funcEvent{handle: rs.cancel},
+	}
+	return rs
+}
+
+// start enters the search mode.
+func (rs *reverseSearch) start(token string) (error, *ControlEvent) {
+	rs.query = nil
+	rs.match = ""
+	rs.failed = false
+	rs.matchIndex = len(rs.lineEditor.consoleApp.eventHistory.SubmittedLines())
+	rs.savedLine = rs.lineEditor.Line()
+	rs.savedCursor = rs.lineEditor.Cursor()
+	rs.lineEditor.consoleApp.PushEventRegistry(rs.eventRegistry)
+	rs.lineEditor.consoleApp.logger.Debug("Reverse search started", zap.String("func", "start"))
+	rs.render()
+	return nil, nil
+}
+
+// extendQuery appends a printable token to the query and searches again starting with the newest line.
+func (rs *reverseSearch) extendQuery(token string) (error, *ControlEvent) {
+	character, ok := printableCharacter(token)
+	if !ok {
+		return nil, nil
+	}
+	rs.query = append(rs.query, []rune(character)...)
+	rs.search(len(rs.lineEditor.consoleApp.eventHistory.SubmittedLines()), "")
+	rs.render()
+	return nil, nil
+}
+
+// shortenQuery removes the last character of the query and searches again starting with the newest line.
+func (rs *reverseSearch) shortenQuery(token string) (error, *ControlEvent) {
+	if len(rs.query) > 0 {
+		rs.query = rs.query[:len(rs.query)-1]
+	}
+	rs.search(len(rs.lineEditor.consoleApp.eventHistory.SubmittedLines()), "")
+	rs.render()
+	return nil, nil
+}
+
+// older cycles to the next older line that matches the query.
+func (rs *reverseSearch) older(token string) (error, *ControlEvent) {
+	rs.search(rs.matchIndex, rs.match)
+	rs.render()
+	return nil, nil
+}
+
+// accept leaves the search mode and places the match into the line editor.
+func (rs *reverseSearch) accept(token string) (error, *ControlEvent) {
+	line := rs.savedLine
+	if rs.match != "" {
+		line = rs.match
+	}
+	return rs.leave(line, len([]rune(line))), nil
+}
+
+// cancel leaves the search mode and restores the line editor.
+func (rs *reverseSearch) cancel(token string) (error, *ControlEvent) {
+	return rs.leave(rs.savedLine, rs.savedCursor), nil
+}
+
+// leave leaves the search mode and restores the line editor with the line.
+//
+// Parameters:
+//   - `line` : Line that is placed into the line editor
+//   - `cursor` : Position of the cursor within the line
+//
+// Returns:
+//   - `error` : Returns an error when the search mode was not active
+func (rs *reverseSearch) leave(line string, cursor int) error {
+	if err := rs.lineEditor.consoleApp.PopEventRegistry(); err != nil {
+		return err
+	}
+	rs.lineEditor.buffer = []rune(line)
+	rs.lineEditor.cursor = cursor
+	rs.lineEditor.render(line, cursor)
+	rs.lineEditor.consoleApp.logger.Debug("Reverse search left", zap.String("line", line), zap.String("func", "leave"))
+	return nil
+}
+
+// search looks for the newest submitted line older than `before` that matches the query. If a match is found,
+// it becomes the current match, otherwise the search is marked as failed and the previous match is kept.
+//
+// Parameters:
+//   - `before` : Only lines with an index smaller than before are considered
+//   - `skip` : Lines equal to skip are skipped, so that cycling does not return the same line again
+func (rs *reverseSearch) search(before int, skip string) {
+	lines := rs.lineEditor.consoleApp.eventHistory.SubmittedLines()
+	query := string(rs.query)
+	if query == "" {
+		rs.match = ""
+		rs.matchIndex = len(lines)
+		rs.failed = false
+		return
+	}
+	for i := before - 1; i >= 0; i-- {
+		if lines[i] == skip {
+			continue
+		}
+		if rs.matches(lines[i], query) {
+			rs.match = lines[i]
+			rs.matchIndex = i
+			rs.failed = false
+			return
+		}
+	}
+	rs.failed = true
+}
+
+// matches checks whether the line matches the query, either as substring or fuzzy.
+//
+// Parameters:
+//   - `line` : Submitted line
+//   - `query` : Search query
+//
+// Returns:
+//   - `bool` : True if the line matches the query
+func (rs *reverseSearch) matches(line string, query string) bool {
+	if !rs.lineEditor.FuzzyHistorySearch {
+		return strings.Contains(line, query)
+	}
+	// Fuzzy matching requires that all characters of the query appear in the same order in the line
+	queryRunes := []rune(query)
+	position := 0
+	for _, lineRune := range line {
+		if position < len(queryRunes) && lineRune == queryRunes[position] {
+			position += 1
+		}
+	}
+	return position == len(queryRunes)
+}
+
+// render displays the search prompt in place of the line editor.
+func (rs *reverseSearch) render() {
+	prompt := "(reverse-i-search)"
+	if rs.failed {
+		prompt = "(failed reverse-i-search)"
+	}
+	content := fmt.Sprintf("%s`%s': %s", prompt, string(rs.query), rs.match)
+	rs.lineEditor.render(content, len([]rune(content)))
+}
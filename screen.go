@@ -0,0 +1,379 @@
+package cyclecmd
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/RaphSku/cyclecmd/style"
+	"go.uber.org/zap"
+)
+
+// STATUS_REGION is the name of the region at the bottom of the screen that is managed by Screen.SetStatus.
+const STATUS_REGION string = "status"
+
+// Escape sequences the screen uses to manage its regions.
+const (
+	saveCursor        = "\x1b7"
+	restoreCursor     = "\x1b8"
+	resetScrollRegion = "\x1b[r"
+	clearLine         = "\x1b[2K"
+)
+
+// RegionPosition defines at which edge of the screen a region is fixed.
+type RegionPosition int
+
+const (
+	// REGION_TOP fixes a region at the top edge, regions are stacked downwards in the order they were added.
+	REGION_TOP RegionPosition = iota
+	// REGION_BOTTOM fixes a region at the bottom edge, regions are stacked upwards in the order they were added.
+	REGION_BOTTOM
+)
+
+// region is a fixed area of the screen that does not scroll.
+type region struct {
+	name     string
+	height   int
+	position RegionPosition
+	lines    []string
+}
+
+// Screen divides the terminal into fixed regions at the top and bottom edge and a scroll region in between.
+// The regular output and the prompt scroll within the scroll region, while the fixed regions stay in place.
+// The scroll region is set with DECSTBM and the regions are drawn with the cursor saved and restored, so
+// that drawing a region does not disturb the line editor. Every console application owns a screen, see
+// ConsoleApp.Screen. Regions are only drawn while the event loop is running, they are redrawn whenever the
+// terminal is resized and cleared once the event loop concluded.
+type Screen struct {
+	consoleApp *ConsoleApp
+
+	// mutex guards the regions, so that regions can be updated from another goroutine
+	mutex   sync.Mutex
+	regions []*region
+	// active is true while the event loop is running
+	active bool
+}
+
+// newScreen initialises the screen of the console application.
+//
+// Parameters:
+//   - `consoleApp` : Console application the screen draws to
+//
+// Returns:
+//   - `*Screen` : Returns an instance of Screen
+func newScreen(consoleApp *ConsoleApp) *Screen {
+	return &Screen{consoleApp: consoleApp}
+}
+
+// Screen returns the screen of the console application that manages the fixed regions, like the status line.
+//
+// Returns:
+//   - `*Screen` : Screen of the console application
+func (ca *ConsoleApp) Screen() *Screen {
+	return ca.screen
+}
+
+// AddRegion adds a fixed region at the edge of the screen, the scroll region shrinks accordingly.
+//
+// Parameters:
+//   - `name` : Name of the region, it is used to update the region
+//   - `height` : Height of the region in rows
+//   - `position` : Edge of the screen the region is fixed at
+//
+// Returns:
+//   - `error` : Returns an error when the name is empty or already taken, or the height is not positive
+func (s *Screen) AddRegion(name string, height int, position RegionPosition) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.addRegion(name, height, position, nil)
+}
+
+// addRegion adds a fixed region with its content, the caller has to hold the mutex.
+//
+// Parameters:
+//   - `name` : Name of the region, it is used to update the region
+//   - `height` : Height of the region in rows
+//   - `position` : Edge of the screen the region is fixed at
+//   - `lines` : Content of the region, one string per row
+//
+// Returns:
+//   - `error` : Returns an error when the name is empty or already taken, or the height is not positive
+func (s *Screen) addRegion(name string, height int, position RegionPosition, lines []string) error {
+	if name == "" {
+		return fmt.Errorf("region name must not be empty")
+	}
+	if height < 1 {
+		return fmt.Errorf("height %v of region %v is invalid, it must be positive", height, name)
+	}
+	if s.findRegion(name) != nil {
+		return fmt.Errorf("region is already added under name %v", name)
+	}
+	s.regions = append(s.regions, &region{name: name, height: height, position: position, lines: lines})
+	s.consoleApp.logger.Debug("Region added", zap.String("name", name), zap.Int("height", height), zap.String("func", "addRegion"))
+	if s.active {
+		var output strings.Builder
+		if position == REGION_BOTTOM {
+			makeRoom(&output, height)
+		}
+		s.draw(&output)
+		s.write(output.String())
+	}
+	return nil
+}
+
+// RemoveRegion removes a fixed region, the scroll region grows accordingly.
+//
+// Parameters:
+//   - `name` : Name of the region
+//
+// Returns:
+//   - `error` : Returns an error when no region is added under the name
+func (s *Screen) RemoveRegion(name string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.findRegion(name) == nil {
+		return fmt.Errorf("region %v is not added", name)
+	}
+	var output strings.Builder
+	if s.active {
+		s.clear(&output)
+	}
+	for i, region := range s.regions {
+		if region.name == name {
+			s.regions = append(s.regions[:i], s.regions[i+1:]...)
+			break
+		}
+	}
+	if s.active {
+		s.draw(&output)
+		s.write(output.String())
+	}
+	return nil
+}
+
+// UpdateRegion replaces the content of a region and redraws it. Lines that exceed the height of the region
+// are dropped and lines that are wider than the screen are truncated.
+//
+// Parameters:
+//   - `name` : Name of the region
+//   - `lines` : Content of the region, one string per row
+//
+// Returns:
+//   - `error` : Returns an error when no region is added under the name
+func (s *Screen) UpdateRegion(name string, lines ...string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	region := s.findRegion(name)
+	if region == nil {
+		return fmt.Errorf("region %v is not added", name)
+	}
+	region.lines = lines
+	if s.active {
+		var output strings.Builder
+		s.draw(&output)
+		s.write(output.String())
+	}
+	return nil
+}
+
+// SetStatus sets the text of the status line, a region with a height of one row at the bottom of the screen.
+// The status line is added on first use and styled with the status line style of the theme. While the event loop
+// runs, the status line is written to the output right away, thus it must be set from the event loop, e.g. by an
+// event. Other goroutines pass the text through ConsoleApp.Post, like the reload of a configuration file does.
+//
+// Parameters:
+//   - `text` : Text of the status line, e.g. the current mode or the connection state
+func (s *Screen) SetStatus(text string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	region := s.findRegion(STATUS_REGION)
+	if region == nil {
+		_ = s.addRegion(STATUS_REGION, 1, REGION_BOTTOM, []string{text})
+		return
+	}
+	region.lines = []string{text}
+	if s.active {
+		var output strings.Builder
+		s.draw(&output)
+		s.write(output.String())
+	}
+}
+
+// Redraw sets the scroll region and draws all regions again, e.g. after the screen was cleared.
+func (s *Screen) Redraw() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if !s.active || len(s.regions) == 0 {
+		return
+	}
+	var output strings.Builder
+	s.draw(&output)
+	s.write(output.String())
+}
+
+// activate starts drawing the regions, it is called once the event loop starts.
+func (s *Screen) activate() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.active = true
+	if len(s.regions) == 0 {
+		return
+	}
+	_, bottomHeight := s.heights()
+	var output strings.Builder
+	makeRoom(&output, bottomHeight)
+	s.draw(&output)
+	s.write(output.String())
+}
+
+// deactivate clears the regions and resets the scroll region, it is called once the event loop concluded.
+func (s *Screen) deactivate() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.active && len(s.regions) > 0 {
+		var output strings.Builder
+		s.clear(&output)
+		s.write(output.String())
+	}
+	s.active = false
+}
+
+// findRegion returns the region with the name.
+//
+// Parameters:
+//   - `name` : Name of the region
+//
+// Returns:
+//   - `*region` : The region, nil if no region is added under the name
+func (s *Screen) findRegion(name string) *region {
+	for _, region := range s.regions {
+		if region.name == name {
+			return region
+		}
+	}
+	return nil
+}
+
+// heights sums up the heights of the regions at the top and at the bottom edge.
+//
+// Returns:
+//   - `int` : Height of all regions at the top edge
+//   - `int` : Height of all regions at the bottom edge
+func (s *Screen) heights() (int, int) {
+	topHeight, bottomHeight := 0, 0
+	for _, region := range s.regions {
+		if region.position == REGION_TOP {
+			topHeight += region.height
+		} else {
+			bottomHeight += region.height
+		}
+	}
+	return topHeight, bottomHeight
+}
+
+// rows returns the first row of every region in the order of the regions, counted from 1 like the terminal does.
+//
+// Parameters:
+//   - `height` : Height of the screen in rows
+//
+// Returns:
+//   - `[]int` : First row of every region
+func (s *Screen) rows(height int) []int {
+	rows := make([]int, len(s.regions))
+	top, bottom := 1, height+1
+	for i, region := range s.regions {
+		if region.position == REGION_TOP {
+			rows[i] = top
+			top += region.height
+		} else {
+			bottom -= region.height
+			rows[i] = bottom
+		}
+	}
+	return rows
+}
+
+// draw writes the escape sequences that set the scroll region and draw all regions.
+//
+// Parameters:
+//   - `output` : Receives the escape sequences
+func (s *Screen) draw(output *strings.Builder) {
+	width, height := s.consoleApp.Size()
+	topHeight, bottomHeight := s.heights()
+	if topHeight+bottomHeight >= height {
+		s.consoleApp.logger.Debug("Screen is too small for its regions", zap.Int("height", height), zap.String("func", "draw"))
+		return
+	}
+	output.WriteString(saveCursor)
+	fmt.Fprintf(output, "\x1b[%d;%dr", topHeight+1, height-bottomHeight)
+	rows := s.rows(height)
+	for index, region := range s.regions {
+		for i := 0; i < region.height; i++ {
+			line := ""
+			if i < len(region.lines) {
+				line = s.format(region, region.lines[i], width)
+			}
+			fmt.Fprintf(output, "\x1b[%d;1H%s%s", rows[index]+i, clearLine, line)
+		}
+	}
+	output.WriteString(restoreCursor)
+}
+
+// clear writes the escape sequences that clear all regions and reset the scroll region.
+//
+// Parameters:
+//   - `output` : Receives the escape sequences
+func (s *Screen) clear(output *strings.Builder) {
+	_, height := s.consoleApp.Size()
+	output.WriteString(saveCursor)
+	output.WriteString(resetScrollRegion)
+	rows := s.rows(height)
+	for index, region := range s.regions {
+		for i := 0; i < region.height; i++ {
+			fmt.Fprintf(output, "\x1b[%d;1H%s", rows[index]+i, clearLine)
+		}
+	}
+	output.WriteString(restoreCursor)
+}
+
+// format truncates a line of a region to the width, the status line is padded and styled.
+//
+// Parameters:
+//   - `region` : Region the line belongs to
+//   - `line` : Line of the region
+//   - `width` : Width of the screen in columns
+//
+// Returns:
+//   - `string` : Formatted line
+func (s *Screen) format(region *region, line string, width int) string {
+	if displayWidth(style.Strip(line)) > width {
+		line = truncateText(style.Strip(line), width)
+	}
+	if region.name != STATUS_REGION {
+		return line
+	}
+	line += strings.Repeat(" ", max(0, width-displayWidth(style.Strip(line))))
+	return s.consoleApp.render(s.consoleApp.theme.StatusLine, line)
+}
+
+// write writes the escape sequences to the output of the console application.
+//
+// Parameters:
+//   - `output` : Escape sequences
+func (s *Screen) write(output string) {
+	fmt.Fprint(s.consoleApp.Output(), output)
+}
+
+// makeRoom scrolls the content up by the height, so that the rows at the bottom of the screen are free for
+// a region and the cursor is not covered by it.
+//
+// Parameters:
+//   - `output` : Receives the escape sequences
+//   - `height` : Number of rows
+func makeRoom(output *strings.Builder, height int) {
+	if height < 1 {
+		return
+	}
+	output.WriteString(strings.Repeat("\n", height))
+	fmt.Fprintf(output, "\x1b[%dA", height)
+}
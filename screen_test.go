@@ -0,0 +1,134 @@
+//go:build unit_test
+
+package cyclecmd_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/RaphSku/cyclecmd"
+	"github.com/stretchr/testify/assert"
+)
+
+// StatusEvent sets the status line to the token.
+type StatusEvent struct {
+	consoleApp *cyclecmd.ConsoleApp
+}
+
+func (se *StatusEvent) Handle(token string) (error, *cyclecmd.ControlEvent) {
+	se.consoleApp.Screen().SetStatus("status " + token)
+	return nil, nil
+}
+
+func setupScreenConsoleApp(input io.Reader) (*cyclecmd.ConsoleApp, *cyclecmd.EventRegistry, *bytes.Buffer) {
+	eventRegistry := cyclecmd.NewEventRegistry(setupDefaultEventInformation())
+	consoleApp := cyclecmd.NewConsoleApp("test", "0.1.0", "This is a test console application", eventRegistry, cyclecmd.NewEventHistory())
+	output := &bytes.Buffer{}
+	consoleApp.SetOutput(output)
+	consoleApp.SetInput(input)
+	consoleApp.DisableBanner()
+	consoleApp.Resize(20, 10)
+	return consoleApp, eventRegistry, output
+}
+
+func TestScreenRegionErrors(t *testing.T) {
+	t.Parallel()
+
+	consoleApp, _, _ := setupScreenConsoleApp(strings.NewReader(""))
+	screen := consoleApp.Screen()
+
+	assert.NoError(t, screen.AddRegion("header", 1, cyclecmd.REGION_TOP))
+	err := screen.AddRegion("header", 1, cyclecmd.REGION_TOP)
+	if assert.Error(t, err) {
+		assert.Equal(t, fmt.Errorf("region is already added under name %v", "header"), err)
+	}
+	err = screen.AddRegion("footer", 0, cyclecmd.REGION_BOTTOM)
+	if assert.Error(t, err) {
+		assert.Equal(t, fmt.Errorf("height %v of region %v is invalid, it must be positive", 0, "footer"), err)
+	}
+	err = screen.UpdateRegion("footer", "text")
+	if assert.Error(t, err) {
+		assert.Equal(t, fmt.Errorf("region %v is not added", "footer"), err)
+	}
+	assert.NoError(t, screen.RemoveRegion("header"))
+	assert.Error(t, screen.RemoveRegion("header"))
+}
+
+func TestScreenStatusLine(t *testing.T) {
+	t.Parallel()
+
+	consoleApp, eventRegistry, output := setupScreenConsoleApp(newTokenReader("a"))
+	err := eventRegistry.RegisterEvent("a", cyclecmd.EventInformation{EventName: "Status", Event: &StatusEvent{consoleApp: consoleApp}})
+	assert.NoError(t, err)
+	consoleApp.Screen().SetStatus("ready")
+	assert.Empty(t, output.String())
+
+	consoleApp.Start()
+	padding := func(text string) string {
+		return text + strings.Repeat(" ", 20-len(text))
+	}
+	expOutput := "\n\x1b[1A" +
+		"\x1b7\x1b[1;9r\x1b[10;1H\x1b[2K" + padding("ready") + "\x1b8" +
+		"\x1b7\x1b[1;9r\x1b[10;1H\x1b[2K" + padding("status a") + "\x1b8" +
+		"\x1b7\x1b[r\x1b[10;1H\x1b[2K\x1b8"
+	assert.Equal(t, expOutput, output.String())
+}
+
+func TestScreenStatusLineFromOtherGoroutines(t *testing.T) {
+	t.Parallel()
+
+	// The pipe blocks, so that the event loop only concludes through the posted functions
+	input, inputWriter := io.Pipe()
+	defer inputWriter.Close()
+	consoleApp, _, output := setupScreenConsoleApp(input)
+	screen := consoleApp.Screen()
+	var wg sync.WaitGroup
+	for index := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			consoleApp.Post(func() (error, *cyclecmd.ControlEvent) {
+				screen.SetStatus(fmt.Sprintf("status %d", index))
+				return nil, nil
+			})
+		}()
+	}
+	wg.Wait()
+	consoleApp.Post(func() (error, *cyclecmd.ControlEvent) {
+		return nil, &cyclecmd.ControlEvent{Terminate: true}
+	})
+	consoleApp.Start()
+
+	// The status line is added only once and every update is drawn as a whole
+	assert.Equal(t, 8, strings.Count(output.String(), "\x1b7\x1b[1;9r\x1b[10;1H\x1b[2Kstatus "))
+	assert.NoError(t, screen.RemoveRegion(cyclecmd.STATUS_REGION))
+	assert.Error(t, screen.RemoveRegion(cyclecmd.STATUS_REGION))
+}
+
+func TestScreenRedrawOnResize(t *testing.T) {
+	t.Parallel()
+
+	// The pipe blocks after the first token, so that the event loop can only conclude through the resize event
+	input, inputWriter := io.Pipe()
+	defer inputWriter.Close()
+	go inputWriter.Write([]byte("r"))
+
+	consoleApp, eventRegistry, output := setupScreenConsoleApp(input)
+	err := eventRegistry.RegisterEvent("r", cyclecmd.EventInformation{EventName: "Resizing", Event: &ResizingEvent{consoleApp: consoleApp}})
+	assert.NoError(t, err)
+	err = eventRegistry.RegisterEvent(cyclecmd.KEY_RESIZE, cyclecmd.EventInformation{EventName: "Resize", Event: &ResizeEvent{consoleApp: consoleApp}})
+	assert.NoError(t, err)
+	screen := consoleApp.Screen()
+	assert.NoError(t, screen.AddRegion("header", 2, cyclecmd.REGION_TOP))
+	assert.NoError(t, screen.UpdateRegion("header", "first", "a header line that is too long", "dropped"))
+
+	consoleApp.Start()
+	expOutput := "\x1b7\x1b[3;10r\x1b[1;1H\x1b[2Kfirst\x1b[2;1H\x1b[2Ka header line that …\x1b8" +
+		"\x1b7\x1b[3;30r\x1b[1;1H\x1b[2Kfirst\x1b[2;1H\x1b[2Ka header line that is too long\x1b8" +
+		"\x1b7\x1b[r\x1b[1;1H\x1b[2K\x1b[2;1H\x1b[2K\x1b8"
+	assert.Equal(t, expOutput, output.String())
+}
@@ -0,0 +1,382 @@
+package cyclecmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// RECORDED_INPUT is the event type of recorded input in an asciicast recording.
+	RECORDED_INPUT string = "i"
+	// RECORDED_OUTPUT is the event type of recorded output in an asciicast recording.
+	RECORDED_OUTPUT string = "o"
+)
+
+// AsciicastHeader is the header of a recording in the asciicast v2 format.
+type AsciicastHeader struct {
+	// Version of the asciicast format, always 2
+	Version int `json:"version"`
+	// Width of the terminal in columns
+	Width int `json:"width"`
+	// Height of the terminal in rows
+	Height int `json:"height"`
+	// Timestamp is the unix timestamp of the start of the recording
+	Timestamp int64 `json:"timestamp,omitempty"`
+	// Title of the recording
+	Title string `json:"title,omitempty"`
+	// Env captures environment variables like TERM and SHELL
+	Env map[string]string `json:"env,omitempty"`
+}
+
+// RecordedEvent is a single input or output event of a recording.
+type RecordedEvent struct {
+	// Time in seconds relative to the start of the recording
+	Time float64
+	// Type is either RECORDED_INPUT or RECORDED_OUTPUT
+	Type string
+	// Data contains the raw bytes that were read or written
+	Data string
+}
+
+// Recording is a session recording in the asciicast v2 format.
+type Recording struct {
+	Header AsciicastHeader
+	Events []RecordedEvent
+}
+
+// LoadRecording reads a recording in the asciicast v2 format.
+//
+// Parameters:
+//   - `r` : Reader that provides the recording
+//
+// Returns:
+//   - `*Recording` : The decoded recording
+//   - `error` : Returns an error when the recording is not a valid asciicast v2 recording
+func LoadRecording(r io.Reader) (*Recording, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	if !scanner.Scan() {
+		return nil, fmt.Errorf("recording is empty, the asciicast header is missing")
+	}
+	recording := &Recording{}
+	if err := json.Unmarshal(scanner.Bytes(), &recording.Header); err != nil {
+		return nil, fmt.Errorf("asciicast header could not be decoded! error: %w", err)
+	}
+	if recording.Header.Version != 2 {
+		return nil, fmt.Errorf("asciicast version %v is not supported, only version 2 is supported", recording.Header.Version)
+	}
+	line := 1
+	for scanner.Scan() {
+		line += 1
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		var rawEvent []any
+		if err := json.Unmarshal(scanner.Bytes(), &rawEvent); err != nil {
+			return nil, fmt.Errorf("asciicast event on line %v could not be decoded! error: %w", line, err)
+		}
+		if len(rawEvent) != 3 {
+			return nil, fmt.Errorf("asciicast event on line %v needs exactly 3 elements", line)
+		}
+		eventTime, okTime := rawEvent[0].(float64)
+		eventType, okType := rawEvent[1].(string)
+		eventData, okData := rawEvent[2].(string)
+		if !okTime || !okType || !okData {
+			return nil, fmt.Errorf("asciicast event on line %v is malformed", line)
+		}
+		recording.Events = append(recording.Events, RecordedEvent{Time: eventTime, Type: eventType, Data: eventData})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("recording could not be read! error: %w", err)
+	}
+	return recording, nil
+}
+
+// LoadRecordingFromFile reads a recording in the asciicast v2 format from the file at path.
+//
+// Parameters:
+//   - `path` : Path of the recording
+//
+// Returns:
+//   - `*Recording` : The decoded recording
+//   - `error` : Returns an error when the file could not be read or is not a valid recording
+func LoadRecordingFromFile(path string) (*Recording, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("recording could not be opened! error: %w", err)
+	}
+	defer file.Close()
+	return LoadRecording(file)
+}
+
+// Input returns all recorded input events.
+//
+// Returns:
+//   - `[]RecordedEvent` : Recorded input events in the order they occurred
+func (r *Recording) Input() []RecordedEvent {
+	return r.eventsOfType(RECORDED_INPUT)
+}
+
+// Output returns the complete recorded output.
+//
+// Returns:
+//   - `string` : All recorded output concatenated
+func (r *Recording) Output() string {
+	var output strings.Builder
+	for _, recordedEvent := range r.eventsOfType(RECORDED_OUTPUT) {
+		output.WriteString(recordedEvent.Data)
+	}
+	return output.String()
+}
+
+// eventsOfType filters the recorded events by their type.
+//
+// Parameters:
+//   - `eventType` : Either RECORDED_INPUT or RECORDED_OUTPUT
+//
+// Returns:
+//   - `[]RecordedEvent` : Recorded events of the given type
+func (r *Recording) eventsOfType(eventType string) []RecordedEvent {
+	var recordedEvents []RecordedEvent
+	for _, recordedEvent := range r.Events {
+		if recordedEvent.Type == eventType {
+			recordedEvents = append(recordedEvents, recordedEvent)
+		}
+	}
+	return recordedEvents
+}
+
+// SessionRecorder records the raw input and the output of a console application session together with
+// relative timings in the asciicast v2 format, the recording can be viewed with existing asciicast players.
+//
+// Only output that is written to ConsoleApp.Output is recorded. Characters that are typed while the tokens are
+// masked, e.g. into a password prompt, are recorded as MASKED_TOKEN, see NewPassword.
+type SessionRecorder struct {
+	mu     sync.Mutex
+	writer io.Writer
+	header AsciicastHeader
+	now    func() time.Time
+
+	startTime time.Time
+	started   bool
+	// err is the first error that occurred while writing the recording
+	err error
+}
+
+// NewSessionRecorder initialises a session recorder that writes the recording to w.
+//
+// Parameters:
+//   - `w` : Writer the recording is written to
+//   - `width` : Width of the terminal in columns
+//   - `height` : Height of the terminal in rows
+//
+// Returns:
+//   - `*SessionRecorder` : Returns an instance of SessionRecorder
+func NewSessionRecorder(w io.Writer, width int, height int) *SessionRecorder {
+	return &SessionRecorder{
+		writer: w,
+		header: AsciicastHeader{
+			Version: 2,
+			Width:   width,
+			Height:  height,
+			Env: map[string]string{
+				"TERM":  os.Getenv("TERM"),
+				"SHELL": os.Getenv("SHELL"),
+			},
+		},
+		now: time.Now,
+	}
+}
+
+// SetTitle sets the title that is stored in the header of the recording.
+//
+// Parameters:
+//   - `title` : Title of the recording
+func (sr *SessionRecorder) SetTitle(title string) {
+	sr.header.Title = title
+}
+
+// Err returns the first error that occurred while writing the recording.
+//
+// Returns:
+//   - `error` : First write error, nil if the recording was written successfully
+func (sr *SessionRecorder) Err() error {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	return sr.err
+}
+
+// start writes the asciicast header, all timings are relative to the start.
+func (sr *SessionRecorder) start() {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	if sr.started {
+		return
+	}
+	sr.started = true
+	sr.startTime = sr.now()
+	sr.header.Timestamp = sr.startTime.Unix()
+	header, err := json.Marshal(sr.header)
+	if err != nil {
+		sr.err = err
+		return
+	}
+	sr.write(header)
+}
+
+// record writes a single event of the given type to the recording.
+//
+// Parameters:
+//   - `eventType` : Either RECORDED_INPUT or RECORDED_OUTPUT
+//   - `data` : Raw bytes that were read or written
+func (sr *SessionRecorder) record(eventType string, data []byte) {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	if !sr.started || len(data) == 0 {
+		return
+	}
+	elapsed := sr.now().Sub(sr.startTime).Seconds()
+	// asciicast events are [time, type, data] where time is given with microsecond precision
+	event, err := json.Marshal([]any{json.Number(fmt.Sprintf("%.6f", elapsed)), eventType, string(data)})
+	if err != nil {
+		sr.err = err
+		return
+	}
+	sr.write(event)
+}
+
+// write writes a single line to the recording, the first error is kept.
+//
+// Parameters:
+//   - `line` : Line without a trailing newline
+func (sr *SessionRecorder) write(line []byte) {
+	if sr.err != nil {
+		return
+	}
+	if _, err := sr.writer.Write(append(line, '\n')); err != nil {
+		sr.err = err
+	}
+}
+
+// recordKey records a key that the event loop read from the input.
+//
+// Parameters:
+//   - `data` : Raw bytes of the key
+//   - `masked` : True if the key is a character that must not be recorded, it is recorded as MASKED_TOKEN
+func (sr *SessionRecorder) recordKey(data []byte, masked bool) {
+	if masked {
+		data = []byte(MASKED_TOKEN)
+	}
+	sr.record(RECORDED_INPUT, data)
+}
+
+// recordInput wraps the reader so that all read bytes are recorded, it records the input of the batch mode.
+//
+// Parameters:
+//   - `r` : Reader that provides the input
+//
+// Returns:
+//   - `io.Reader` : Reader that records the input
+func (sr *SessionRecorder) recordInput(r io.Reader) io.Reader {
+	return &recordingReader{reader: r, recorder: sr}
+}
+
+// recordOutput wraps the writer so that all written bytes are recorded.
+//
+// Parameters:
+//   - `w` : Writer that receives the output
+//
+// Returns:
+//   - `io.Writer` : Writer that records the output
+func (sr *SessionRecorder) recordOutput(w io.Writer) io.Writer {
+	return &recordingWriter{writer: w, recorder: sr}
+}
+
+// recordingReader records all bytes that are read from the underlying reader.
+type recordingReader struct {
+	reader   io.Reader
+	recorder *SessionRecorder
+}
+
+// Read reads from the underlying reader and records the read bytes.
+func (rr *recordingReader) Read(p []byte) (int, error) {
+	n, err := rr.reader.Read(p)
+	rr.recorder.record(RECORDED_INPUT, p[:n])
+	return n, err
+}
+
+// recordingWriter records all bytes that are written to the underlying writer.
+type recordingWriter struct {
+	writer   io.Writer
+	recorder *SessionRecorder
+}
+
+// Write writes to the underlying writer and records the written bytes.
+func (rw *recordingWriter) Write(p []byte) (int, error) {
+	rw.recorder.record(RECORDED_OUTPUT, p)
+	return rw.writer.Write(p)
+}
+
+// RecordSession enables the session recording, the session is recorded once Start is called.
+//
+// Parameters:
+//   - `sessionRecorder` : Recorder that records the input and output of the session
+func (ca *ConsoleApp) RecordSession(sessionRecorder *SessionRecorder) {
+	ca.sessionRecorder = sessionRecorder
+}
+
+// Replay feeds the recorded input back through the event loop, either with the recorded timings or instantly.
+// The output is written to the output of the console application, thus it can be compared with Recording.Output
+// to reproduce a recorded session deterministically.
+//
+// Parameters:
+//   - `recording` : Recording whose input is replayed
+//   - `instant` : Whether the input should be replayed without waiting for the recorded timings
+func (ca *ConsoleApp) Replay(recording *Recording, instant bool) {
+	input, keyInput := ca.input, ca.keyInput
+	defer func() { ca.input, ca.keyInput = input, keyInput }()
+	ca.SetInput(&replayReader{
+		events:  recording.Input(),
+		instant: instant,
+		sleep:   time.Sleep,
+	})
+	ca.Start()
+}
+
+// replayReader provides the recorded input events one by one, so that every read returns the same bytes
+// that were read during the recording.
+type replayReader struct {
+	events  []RecordedEvent
+	instant bool
+	sleep   func(time.Duration)
+
+	// pending contains data of the current event that did not fit into the last read
+	pending []byte
+	// lastTime is the time of the last replayed event
+	lastTime float64
+}
+
+// Read returns the data of the next recorded input event, io.EOF is returned once all events are replayed.
+func (rr *replayReader) Read(p []byte) (int, error) {
+	if len(rr.pending) == 0 {
+		if len(rr.events) == 0 {
+			return 0, io.EOF
+		}
+		event := rr.events[0]
+		rr.events = rr.events[1:]
+		if !rr.instant && event.Time > rr.lastTime {
+			rr.sleep(time.Duration((event.Time - rr.lastTime) * float64(time.Second)))
+		}
+		rr.lastTime = event.Time
+		rr.pending = []byte(event.Data)
+	}
+	n := copy(p, rr.pending)
+	rr.pending = rr.pending[n:]
+	return n, nil
+}
@@ -0,0 +1,131 @@
+//go:build unit_test
+
+package cyclecmd_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/RaphSku/cyclecmd"
+	"github.com/stretchr/testify/assert"
+)
+
+type OutputEvent struct {
+	consoleApp *cyclecmd.ConsoleApp
+}
+
+func (oe *OutputEvent) Handle(token string) (error, *cyclecmd.ControlEvent) {
+	fmt.Fprint(oe.consoleApp.Output(), token)
+	return nil, nil
+}
+
+func setupOutputConsoleApp(output io.Writer) *cyclecmd.ConsoleApp {
+	outputEvent := &OutputEvent{}
+	eventRegistry := cyclecmd.NewEventRegistry(cyclecmd.EventInformation{
+		EventName: "Default",
+		Event:     outputEvent,
+	})
+	consoleApp := cyclecmd.NewConsoleApp(
+		"test",
+		"0.1.0",
+		"This is a test console application",
+		eventRegistry,
+		cyclecmd.NewEventHistory(),
+	)
+	outputEvent.consoleApp = consoleApp
+	consoleApp.SetOutput(output)
+	return consoleApp
+}
+
+func TestSessionRecording(t *testing.T) {
+	t.Parallel()
+
+	var output, recordingBuffer bytes.Buffer
+	consoleApp := setupOutputConsoleApp(&output)
+	consoleApp.SetInput(iotest.OneByteReader(strings.NewReader("hi")))
+	sessionRecorder := cyclecmd.NewSessionRecorder(&recordingBuffer, 80, 24)
+	sessionRecorder.SetTitle("test session")
+	consoleApp.RecordSession(sessionRecorder)
+
+	consoleApp.Start()
+	assert.NoError(t, sessionRecorder.Err())
+
+	recording, err := cyclecmd.LoadRecording(&recordingBuffer)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, recording.Header.Version)
+	assert.Equal(t, 80, recording.Header.Width)
+	assert.Equal(t, 24, recording.Header.Height)
+	assert.Equal(t, "test session", recording.Header.Title)
+
+	actInput := []string{}
+	for _, recordedEvent := range recording.Input() {
+		actInput = append(actInput, recordedEvent.Data)
+	}
+	assert.Equal(t, []string{"h", "i"}, actInput)
+	expOutput := "Welcome to test! Version: 0.1.0\r\nThis is a test console application\rhi"
+	assert.Equal(t, expOutput, recording.Output())
+	assert.Equal(t, expOutput, output.String())
+}
+
+func TestSessionRecordingMasksPassword(t *testing.T) {
+	t.Parallel()
+
+	var recordingBuffer bytes.Buffer
+	eventRegistry := cyclecmd.NewEventRegistry(setupDefaultEventInformation())
+	consoleApp := cyclecmd.NewConsoleApp("test", "0.1.0", "This is a test console application", eventRegistry, cyclecmd.NewEventHistory())
+	consoleApp.SetOutput(io.Discard)
+	consoleApp.SetInput(newTokenReader("p", "s", "3", "é", cyclecmd.KEY_BACKSPACE, "c", cyclecmd.KEY_ENTER, "x"))
+	consoleApp.RecordSession(cyclecmd.NewSessionRecorder(&recordingBuffer, 80, 24))
+	passwordEvent := &WidgetEvent{run: func() (any, error) {
+		return cyclecmd.NewPassword(consoleApp, "Password:").Run()
+	}}
+	assert.NoError(t, eventRegistry.RegisterEvent("p", cyclecmd.EventInformation{EventName: "Password", Event: passwordEvent}))
+	consoleApp.Start()
+	assert.Equal(t, "s3c", passwordEvent.result)
+
+	assert.NotContains(t, recordingBuffer.String(), "s3")
+	recording, err := cyclecmd.LoadRecording(&recordingBuffer)
+	assert.NoError(t, err)
+	actInput := []string{}
+	for _, recordedEvent := range recording.Input() {
+		actInput = append(actInput, recordedEvent.Data)
+	}
+	expInput := []string{"p", cyclecmd.MASKED_TOKEN, cyclecmd.MASKED_TOKEN, cyclecmd.MASKED_TOKEN, cyclecmd.KEY_BACKSPACE, cyclecmd.MASKED_TOKEN, cyclecmd.KEY_ENTER, "x"}
+	assert.Equal(t, expInput, actInput)
+}
+
+func TestSessionReplay(t *testing.T) {
+	t.Parallel()
+
+	var recordingBuffer bytes.Buffer
+	consoleApp := setupOutputConsoleApp(io.Discard)
+	consoleApp.SetInput(iotest.OneByteReader(strings.NewReader("replay")))
+	consoleApp.RecordSession(cyclecmd.NewSessionRecorder(&recordingBuffer, 80, 24))
+	consoleApp.Start()
+
+	recording, err := cyclecmd.LoadRecording(&recordingBuffer)
+	assert.NoError(t, err)
+
+	var replayOutput bytes.Buffer
+	replayConsoleApp := setupOutputConsoleApp(&replayOutput)
+	replayConsoleApp.Replay(recording, true)
+	assert.Equal(t, recording.Output(), replayOutput.String())
+}
+
+func TestLoadInvalidRecording(t *testing.T) {
+	t.Parallel()
+
+	_, err := cyclecmd.LoadRecording(strings.NewReader(""))
+	if assert.Error(t, err) {
+		assert.Equal(t, fmt.Errorf("recording is empty, the asciicast header is missing"), err)
+	}
+
+	_, err = cyclecmd.LoadRecording(strings.NewReader("{\"version\": 1}\n"))
+	if assert.Error(t, err) {
+		assert.Equal(t, fmt.Errorf("asciicast version %v is not supported, only version 2 is supported", 1), err)
+	}
+}
@@ -60,3 +60,26 @@ func captureStdOutput(f func()) (string, error) {
 
 	return out, nil
 }
+
+// tokenReader returns one token per read, just like a terminal in raw mode returns one key press per read.
+type tokenReader struct {
+	tokens []string
+}
+
+func newTokenReader(tokens ...string) *tokenReader {
+	return &tokenReader{tokens: tokens}
+}
+
+func (tr *tokenReader) Read(p []byte) (int, error) {
+	if len(tr.tokens) == 0 {
+		return 0, io.EOF
+	}
+	// Like a terminal, the rest of a token that does not fit is returned by the next read
+	n := copy(p, tr.tokens[0])
+	if n < len(tr.tokens[0]) {
+		tr.tokens[0] = tr.tokens[0][n:]
+	} else {
+		tr.tokens = tr.tokens[1:]
+	}
+	return n, nil
+}
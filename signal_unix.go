@@ -0,0 +1,30 @@
+//go:build !windows
+
+package cyclecmd
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// notifyTermination relays the signals that would terminate the process to the channel, so that the terminal
+// state can be restored before the event loop concludes.
+//
+// Parameters:
+//   - `c` : Channel that receives the terminating signals
+func notifyTermination(c chan<- os.Signal) {
+	signal.Notify(c, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+}
+
+// raiseTermination restores the default handling of the signal and sends it to the process again, so that the
+// process terminates as if the signal was never relayed.
+//
+// Parameters:
+//   - `terminationSignal` : Signal that terminates the process
+func raiseTermination(terminationSignal os.Signal) {
+	signal.Reset(terminationSignal)
+	if number, ok := terminationSignal.(syscall.Signal); ok {
+		syscall.Kill(os.Getpid(), number)
+	}
+}
@@ -0,0 +1,26 @@
+//go:build windows
+
+package cyclecmd
+
+import (
+	"os"
+	"os/signal"
+)
+
+// notifyTermination relays the interrupt signal to the channel, so that the console state can be restored
+// before the event loop concludes.
+//
+// Parameters:
+//   - `c` : Channel that receives the terminating signals
+func notifyTermination(c chan<- os.Signal) {
+	signal.Notify(c, os.Interrupt)
+}
+
+// raiseTermination terminates the process, signals cannot be sent to the own process on Windows.
+//
+// Parameters:
+//   - `terminationSignal` : Signal that terminates the process
+func raiseTermination(terminationSignal os.Signal) {
+	signal.Reset(terminationSignal)
+	os.Exit(1)
+}
@@ -0,0 +1,198 @@
+package cyclecmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/RaphSku/cyclecmd/style"
+	"go.uber.org/zap"
+	"golang.org/x/term"
+)
+
+// defaultTerminalHeight is the height in rows that is assumed when the size of the terminal is unknown.
+const defaultTerminalHeight = 24
+
+// Size returns the current size of the terminal. The size is determined with the descriptor of the output or,
+// if the output is not a terminal, with the descriptor of the input. It is updated whenever the terminal is
+// resized. If neither is a terminal, the size set via Resize is used, otherwise 80x24 is assumed.
+//
+// Returns:
+//   - `int` : Width in columns
+//   - `int` : Height in rows
+func (ca *ConsoleApp) Size() (int, int) {
+	ca.sizeMutex.Lock()
+	defer ca.sizeMutex.Unlock()
+	if ca.width > 0 && ca.height > 0 {
+		return ca.width, ca.height
+	}
+	width, height, err := ca.terminalSize()
+	if err != nil {
+		return defaultTerminalWidth, defaultTerminalHeight
+	}
+	return width, height
+}
+
+// Resize sets the size of the terminal and notifies the event loop, which handles the resize like a SIGWINCH.
+// It is meant for console applications that are not attached to a local terminal, e.g. behind a network
+// connection that reports the size of the remote terminal. It is safe to call Resize from another goroutine.
+//
+// Parameters:
+//   - `width` : Width in columns
+//   - `height` : Height in rows
+func (ca *ConsoleApp) Resize(width int, height int) {
+	if width <= 0 || height <= 0 {
+		ca.logger.Debug("Ignoring invalid terminal size", zap.Int("width", width), zap.Int("height", height), zap.String("func", "Resize"))
+		return
+	}
+	ca.sizeMutex.Lock()
+	ca.width, ca.height = width, height
+	ca.sizeMutex.Unlock()
+	// A pending notification is sufficient, since the event loop always reads the latest size
+	select {
+	case ca.resizes <- struct{}{}:
+	default:
+	}
+}
+
+// terminalSize queries the size of the terminal the output or the input is attached to.
+//
+// Returns:
+//   - `int` : Width in columns
+//   - `int` : Height in rows
+//   - `error` : Returns an error when neither the output nor the input is a terminal
+func (ca *ConsoleApp) terminalSize() (int, int, error) {
+	var fds []int
+	if file, ok := ca.Output().(*os.File); ok {
+		fds = append(fds, int(file.Fd()))
+	}
+	if fd, ok := ca.inputFd(); ok {
+		fds = append(fds, fd)
+	}
+	for _, fd := range fds {
+		if !term.IsTerminal(fd) {
+			continue
+		}
+		width, height, err := term.GetSize(fd)
+		if err == nil && width > 0 && height > 0 {
+			return width, height, nil
+		}
+	}
+	return 0, 0, fmt.Errorf("terminal size could not be determined, neither the output nor the input is a terminal")
+}
+
+// refreshSize queries the size of the terminal after a SIGWINCH and caches it.
+func (ca *ConsoleApp) refreshSize() {
+	width, height, err := ca.terminalSize()
+	if err != nil {
+		ca.logger.Debug("Terminal size could not be determined", zap.Error(err), zap.String("func", "refreshSize"))
+		return
+	}
+	ca.sizeMutex.Lock()
+	ca.width, ca.height = width, height
+	ca.sizeMutex.Unlock()
+}
+
+// handleResize redraws the fixed regions of the screen, calls the resize hooks and dispatches the resize token
+// if an event is registered for it in the active event registry, in that case the resize is recorded in the
+// event history.
+//
+// Returns:
+//   - `*ControlEvent` : The control event returned by the resize event, might be nil
+//   - `error` : Returns an error when the resize event failed
+func (ca *ConsoleApp) handleResize() (*ControlEvent, error) {
+	width, height := ca.Size()
+	ca.logger.Debug("Terminal was resized", zap.Int("width", width), zap.Int("height", height), zap.String("func", "handleResize"))
+	ca.progress.hide()
+	ca.screen.Redraw()
+	ca.runResizeHooks(width, height)
+	if _, ok := ca.activeEventRegistry().registry[KEY_RESIZE]; !ok {
+		return nil, nil
+	}
+	return ca.dispatchToken(KEY_RESIZE)
+}
+
+// displayWidth returns the number of columns the text occupies in the terminal.
+//
+// Parameters:
+//   - `text` : Text, escape sequences are ignored
+//
+// Returns:
+//   - `int` : Width in columns
+func displayWidth(text string) int {
+	return style.Width(text)
+}
+
+// truncateText shortens the text to the width, an ellipsis marks that the text was truncated.
+//
+// Parameters:
+//   - `text` : Text without escape sequences
+//   - `width` : Available width in columns
+//
+// Returns:
+//   - `string` : The text if it fits, otherwise the truncated text
+func truncateText(text string, width int) string {
+	if displayWidth(text) <= width {
+		return text
+	}
+	if width <= 0 {
+		return ""
+	}
+	var truncated strings.Builder
+	truncatedWidth := 0
+	for _, character := range text {
+		characterWidth := style.RuneWidth(character)
+		// One column is left for the ellipsis
+		if truncatedWidth+characterWidth > width-1 {
+			break
+		}
+		truncated.WriteRune(character)
+		truncatedWidth += characterWidth
+	}
+	return truncated.String() + "…"
+}
+
+// wrapText breaks the text into lines that fit into the width, lines are broken at whitespace if possible.
+// Words that are wider than the width are broken within the word.
+//
+// Parameters:
+//   - `text` : Text without escape sequences
+//   - `width` : Available width in columns
+//
+// Returns:
+//   - `[]string` : Wrapped lines, at least one line
+func wrapText(text string, width int) []string {
+	if width <= 0 || displayWidth(text) <= width {
+		return []string{text}
+	}
+	var lines []string
+	var line strings.Builder
+	lineWidth := 0
+	for _, word := range strings.FieldsFunc(text, unicode.IsSpace) {
+		wordWidth := displayWidth(word)
+		if lineWidth > 0 && lineWidth+1+wordWidth > width {
+			lines = append(lines, line.String())
+			line.Reset()
+			lineWidth = 0
+		}
+		if lineWidth > 0 {
+			line.WriteRune(' ')
+			lineWidth += 1
+		}
+		for _, character := range word {
+			characterWidth := style.RuneWidth(character)
+			if lineWidth+characterWidth > width && lineWidth > 0 {
+				lines = append(lines, line.String())
+				line.Reset()
+				lineWidth = 0
+			}
+			line.WriteRune(character)
+			lineWidth += characterWidth
+		}
+	}
+	if lineWidth > 0 || len(lines) == 0 {
+		lines = append(lines, line.String())
+	}
+	return lines
+}
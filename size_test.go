@@ -0,0 +1,131 @@
+//go:build unit_test
+
+package cyclecmd_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/RaphSku/cyclecmd"
+	"github.com/stretchr/testify/assert"
+)
+
+// ResizingEvent resizes the console application when it is handled.
+type ResizingEvent struct {
+	consoleApp *cyclecmd.ConsoleApp
+}
+
+func (re *ResizingEvent) Handle(token string) (error, *cyclecmd.ControlEvent) {
+	re.consoleApp.Resize(100, 30)
+	return nil, nil
+}
+
+// ResizeEvent records the size of the console application and terminates the event loop.
+type ResizeEvent struct {
+	consoleApp *cyclecmd.ConsoleApp
+	sizes      [][2]int
+}
+
+func (re *ResizeEvent) Handle(token string) (error, *cyclecmd.ControlEvent) {
+	width, height := re.consoleApp.Size()
+	re.sizes = append(re.sizes, [2]int{width, height})
+	return nil, cyclecmd.NewControlEvent(cyclecmd.CYCLE_TERMINATE)
+}
+
+func TestSize(t *testing.T) {
+	t.Parallel()
+
+	consoleApp := cyclecmd.NewConsoleApp("test", "0.1.0", "This is a test console application", cyclecmd.NewEventRegistry(setupDefaultEventInformation()), cyclecmd.NewEventHistory())
+	consoleApp.SetOutput(&bytes.Buffer{})
+	consoleApp.SetInput(strings.NewReader(""))
+
+	width, height := consoleApp.Size()
+	assert.Equal(t, 80, width)
+	assert.Equal(t, 24, height)
+
+	consoleApp.Resize(120, 40)
+	width, height = consoleApp.Size()
+	assert.Equal(t, 120, width)
+	assert.Equal(t, 40, height)
+
+	consoleApp.Resize(0, 10)
+	width, height = consoleApp.Size()
+	assert.Equal(t, 120, width)
+	assert.Equal(t, 40, height)
+}
+
+func TestResizeEvent(t *testing.T) {
+	t.Parallel()
+
+	eventRegistry := cyclecmd.NewEventRegistry(setupDefaultEventInformation())
+	eventHistory := cyclecmd.NewEventHistory()
+	consoleApp := cyclecmd.NewConsoleApp("test", "0.1.0", "This is a test console application", eventRegistry, eventHistory)
+	consoleApp.SetOutput(&bytes.Buffer{})
+	// The pipe blocks after the first token, so that the event loop can only conclude through the resize event
+	input, inputWriter := io.Pipe()
+	defer inputWriter.Close()
+	consoleApp.SetInput(input)
+	go inputWriter.Write([]byte("r"))
+
+	resizeEvent := &ResizeEvent{consoleApp: consoleApp}
+	err := eventRegistry.RegisterEvent("r", cyclecmd.EventInformation{EventName: "Resizing", Event: &ResizingEvent{consoleApp: consoleApp}})
+	assert.NoError(t, err)
+	err = eventRegistry.RegisterEvent(cyclecmd.KEY_RESIZE, cyclecmd.EventInformation{EventName: "Resize", Event: resizeEvent})
+	assert.NoError(t, err)
+	var hookSizes [][2]int
+	consoleApp.OnResize(func(width int, height int) {
+		hookSizes = append(hookSizes, [2]int{width, height})
+	})
+	var actReason cyclecmd.ExitReason
+	consoleApp.OnExit(func(reason cyclecmd.ExitReason, err error) {
+		actReason = reason
+	})
+
+	consoleApp.Start()
+	assert.Equal(t, cyclecmd.EXIT_TERMINATED, actReason)
+	assert.Equal(t, [][2]int{{100, 30}}, hookSizes)
+	assert.Equal(t, [][2]int{{100, 30}}, resizeEvent.sizes)
+	assert.Equal(t, 2, eventHistory.Len())
+	entry, err := eventHistory.RetrieveEventEntryByIndex(1)
+	assert.NoError(t, err)
+	assert.Equal(t, cyclecmd.KEY_RESIZE, entry.Token)
+	assert.Equal(t, "Resize", entry.EventName)
+}
+
+func TestWidthAwareRendering(t *testing.T) {
+	t.Parallel()
+
+	eventRegistry := cyclecmd.NewEventRegistry(setupDefaultEventInformation())
+	consoleApp := cyclecmd.NewConsoleApp("test", "0.1.0", "This is a test console application", eventRegistry, cyclecmd.NewEventHistory())
+	output := &bytes.Buffer{}
+	consoleApp.SetOutput(output)
+	consoleApp.Resize(40, 10)
+
+	commandRegistry := cyclecmd.NewCommandRegistry(consoleApp)
+	err := commandRegistry.RegisterCommand(cyclecmd.Command{
+		Name:        "greet",
+		Description: "Greets the user with a long greeting that does not fit into a single line",
+		Run: func(args []string) (error, *cyclecmd.ControlEvent) {
+			return nil, nil
+		},
+	})
+	assert.NoError(t, err)
+	commandRegistry.PrintHelp()
+	expOutput := "greet  Greets the user with a long\r\n" +
+		"       greeting that does not fit into a\r\n" +
+		"       single line\r\n" +
+		"help   Lists all available commands\r\n"
+	assert.Equal(t, expOutput, output.String())
+
+	output.Reset()
+	consoleApp.SetInput(newTokenReader(cyclecmd.KEY_TAB))
+	consoleApp.DisableBanner()
+	lineEditor := cyclecmd.NewLineEditor(consoleApp, commandRegistry.Submit)
+	lineEditor.RegisterEvents(eventRegistry)
+	lineEditor.SetCompleter(commandRegistry.Completer())
+	consoleApp.Start()
+	assert.Contains(t, output.String(), "greet  Greets the user with a long gree…\r\n")
+	assert.Contains(t, output.String(), "help   Lists all available commands\r\n")
+}
@@ -0,0 +1,231 @@
+// Package sshserver serves console applications built with cyclecmd over SSH, so that they can be used without
+// shell access to the host. Every session runs its own console application, which reads the keys from the session
+// channel and writes its output to it. The size of the terminal is taken from the pty request of the client and
+// kept up to date on window changes:
+//
+//	server := sshserver.NewServer(hostKey, func(session sshserver.Session) (*cyclecmd.ConsoleApp, error) {
+//		return newConsoleApp(), nil
+//	})
+//	server.SetPublicKeyCallback(sshserver.AuthorizedKeys(teamKeys...))
+//	server.ListenAndServe(":2222")
+package sshserver
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/RaphSku/cyclecmd"
+	"github.com/RaphSku/cyclecmd/internal/serve"
+	"golang.org/x/crypto/ssh"
+)
+
+// FINGERPRINT_EXTENSION is the permission extension AuthorizedKeys stores the SHA256 fingerprint of the
+// authenticated key in, see Session.Permissions.
+const FINGERPRINT_EXTENSION = "pubkey-fp"
+
+// defaultHandshakeTimeout is the time a client has to complete the SSH handshake, see SetHandshakeTimeout.
+const defaultHandshakeTimeout = 10 * time.Second
+
+// ErrServerClosed is returned by Serve and ListenAndServe once the server was closed.
+var ErrServerClosed = errors.New("ssh server was closed")
+
+// AppFactory creates a new console application for every session, see cyclecmd.ConsoleApp.AttachRemoteTerminal.
+// The input, the output, the error output, the size and the colour profile of the console application are set by
+// the server.
+//
+// Parameters:
+//   - `session` : Session the console application is created for
+//
+// Returns:
+//   - `*cyclecmd.ConsoleApp` : Console application of the session
+//   - `error` : Rejects the session, the error is reported to the client
+type AppFactory func(session Session) (*cyclecmd.ConsoleApp, error)
+
+// PublicKeyCallback decides whether a client may authenticate with the public key.
+//
+// Parameters:
+//   - `conn` : Metadata of the connection, e.g. the user
+//   - `key` : Public key the client offered
+//
+// Returns:
+//   - `*ssh.Permissions` : Permissions of the connection, they are passed to the sessions
+//   - `error` : Rejects the key
+type PublicKeyCallback func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error)
+
+// Server accepts SSH connections and runs a console application for every session.
+type Server struct {
+	config        *ssh.ServerConfig
+	newConsoleApp AppFactory
+	acceptor      *serve.Acceptor
+	// handshakeTimeout closes connections that did not complete the handshake in time, no timeout if it is not
+	// positive
+	handshakeTimeout time.Duration
+}
+
+// NewServer initialises a server that identifies itself with the host key. Clients can only authenticate once a
+// public key callback is set, see SetPublicKeyCallback.
+//
+// Parameters:
+//   - `hostKey` : Private host key of the server
+//   - `newConsoleApp` : Creates the console application of every session
+//
+// Returns:
+//   - `*Server` : Returns an instance of Server
+func NewServer(hostKey ssh.Signer, newConsoleApp AppFactory) *Server {
+	config := &ssh.ServerConfig{}
+	config.AddHostKey(hostKey)
+	server := &Server{
+		config:           config,
+		newConsoleApp:    newConsoleApp,
+		handshakeTimeout: defaultHandshakeTimeout,
+	}
+	server.acceptor = serve.NewAcceptor("ssh server", ErrServerClosed, server.handleConnection)
+	return server
+}
+
+// SetPublicKeyCallback sets the callback that authenticates clients by their public key, see AuthorizedKeys. It
+// has to be set before the server is started.
+//
+// Parameters:
+//   - `callback` : Decides whether a client may authenticate with a public key
+func (s *Server) SetPublicKeyCallback(callback PublicKeyCallback) {
+	s.config.PublicKeyCallback = callback
+}
+
+// SetHandshakeTimeout sets the time a client has to complete the SSH handshake including the authentication, ten
+// seconds by default. Connections that exceed it are closed, so that clients which never complete the handshake
+// do not hold on to their connection. It has to be set before the server is started.
+//
+// Parameters:
+//   - `handshakeTimeout` : Time for the handshake, the handshake never times out if it is not positive
+func (s *Server) SetHandshakeTimeout(handshakeTimeout time.Duration) {
+	s.handshakeTimeout = handshakeTimeout
+}
+
+// Config returns the configuration of the SSH server, e.g. to add further host keys or authentication methods.
+// It has to be changed before the server is started.
+//
+// Returns:
+//   - `*ssh.ServerConfig` : Configuration of the SSH server
+func (s *Server) Config() *ssh.ServerConfig {
+	return s.config
+}
+
+// ListenAndServe listens on the TCP address and serves the connections, see Serve.
+//
+// Parameters:
+//   - `address` : TCP address to listen on, e.g. ":2222"
+//
+// Returns:
+//   - `error` : Returns an error when the server could not listen, ErrServerClosed once it was closed
+func (s *Server) ListenAndServe(address string) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("ssh server could not listen on %v! error: %w", address, err)
+	}
+	return s.Serve(listener)
+}
+
+// Serve accepts connections on the listener until the server is closed, every connection is handled in a
+// separate goroutine. The listener is closed once Serve returns.
+//
+// Parameters:
+//   - `listener` : Listener that accepts the connections
+//
+// Returns:
+//   - `error` : Returns an error when a connection could not be accepted, ErrServerClosed once it was closed
+func (s *Server) Serve(listener net.Listener) error {
+	return s.acceptor.Serve(listener)
+}
+
+// Close closes all listeners and connections and waits until the console applications of all sessions concluded.
+//
+// Returns:
+//   - `error` : Returns an error when a listener could not be closed
+func (s *Server) Close() error {
+	return s.acceptor.Close()
+}
+
+// handleConnection performs the SSH handshake and serves the session channels of the connection until it is
+// closed, the acceptor closes the connection once it returns. The deadline of the handshake is cleared once it
+// completed, so that idle sessions are not closed.
+//
+// Parameters:
+//   - `conn` : Accepted connection
+func (s *Server) handleConnection(conn net.Conn) {
+	if s.handshakeTimeout > 0 {
+		conn.SetDeadline(time.Now().Add(s.handshakeTimeout))
+	}
+	serverConn, newChannels, requests, err := ssh.NewServerConn(conn, s.config)
+	if err != nil {
+		return
+	}
+	conn.SetDeadline(time.Time{})
+	go ssh.DiscardRequests(requests)
+
+	var sessions sync.WaitGroup
+	for newChannel := range newChannels {
+		if newChannel.ChannelType() != "session" {
+			newChannel.Reject(ssh.UnknownChannelType, "only session channels are supported")
+			continue
+		}
+		sessions.Add(1)
+		go func() {
+			defer sessions.Done()
+			s.handleSession(serverConn, newChannel)
+		}()
+	}
+	sessions.Wait()
+}
+
+// AuthorizedKeys returns a public key callback that accepts the keys, the SHA256 fingerprint of the key is stored
+// in the FINGERPRINT_EXTENSION of the permissions.
+//
+// Parameters:
+//   - `keys` : Public keys that are authorized
+//
+// Returns:
+//   - `PublicKeyCallback` : Callback that accepts the keys, see Server.SetPublicKeyCallback
+func AuthorizedKeys(keys ...ssh.PublicKey) PublicKeyCallback {
+	return func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+		for _, authorizedKey := range keys {
+			if bytes.Equal(authorizedKey.Marshal(), key.Marshal()) {
+				return &ssh.Permissions{Extensions: map[string]string{FINGERPRINT_EXTENSION: ssh.FingerprintSHA256(key)}}, nil
+			}
+		}
+		return nil, fmt.Errorf("public key %v of user %v is not authorized", ssh.FingerprintSHA256(key), conn.User())
+	}
+}
+
+// LoadAuthorizedKeys reads the public keys of an authorized_keys file, see AuthorizedKeys.
+//
+// Parameters:
+//   - `path` : Path of the authorized_keys file
+//
+// Returns:
+//   - `[]ssh.PublicKey` : Public keys of the file
+//   - `error` : Returns an error when the file could not be read or contains an invalid key
+func LoadAuthorizedKeys(path string) ([]ssh.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("authorized keys could not be read! error: %w", err)
+	}
+	var keys []ssh.PublicKey
+	for i, line := range bytes.Split(data, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+		key, _, _, _, err := ssh.ParseAuthorizedKey(line)
+		if err != nil {
+			return nil, fmt.Errorf("key in line %d of %v is invalid! error: %w", i+1, path, err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
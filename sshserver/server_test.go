@@ -0,0 +1,263 @@
+//go:build unit_test
+
+package sshserver_test
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/RaphSku/cyclecmd"
+	"github.com/RaphSku/cyclecmd/sshserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+type DefaultEvent struct{}
+
+func (de *DefaultEvent) Handle(token string) (error, *cyclecmd.ControlEvent) {
+	return nil, nil
+}
+
+// sessionOutput collects the output of a session, so that the test can wait for text.
+type sessionOutput struct {
+	mutex  sync.Mutex
+	output strings.Builder
+}
+
+func (so *sessionOutput) Write(p []byte) (int, error) {
+	so.mutex.Lock()
+	defer so.mutex.Unlock()
+	return so.output.Write(p)
+}
+
+func (so *sessionOutput) waitFor(t *testing.T, text string) {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		so.mutex.Lock()
+		output := so.output.String()
+		so.mutex.Unlock()
+		if strings.Contains(output, text) {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("output does not contain %q", text)
+}
+
+func newSigner(t *testing.T) ssh.Signer {
+	_, privateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	signer, err := ssh.NewSignerFromKey(privateKey)
+	require.NoError(t, err)
+	return signer
+}
+
+// newConsoleApp creates a console application that prints every submitted line together with the user and
+// concludes on "exit", the size of the terminal is printed on every resize.
+func newConsoleApp(session sshserver.Session) (*cyclecmd.ConsoleApp, error) {
+	eventRegistry := cyclecmd.NewEventRegistry(cyclecmd.EventInformation{EventName: "Default", Event: &DefaultEvent{}})
+	consoleApp := cyclecmd.NewConsoleApp("test", "0.1.0", "This is a test console application", eventRegistry, cyclecmd.NewEventHistory())
+	consoleApp.DisableBanner()
+	lineEditor := cyclecmd.NewLineEditor(consoleApp, func(line string) (error, *cyclecmd.ControlEvent) {
+		switch line {
+		case "exit":
+			return nil, &cyclecmd.ControlEvent{Terminate: true}
+		case "fail":
+			return fmt.Errorf("line failed"), nil
+		}
+		fmt.Fprintf(consoleApp.Output(), "%v: [%v] %v\r\n", session.User, line, len(consoleApp.EventHistory().SubmittedLines()))
+		return nil, nil
+	})
+	if err := lineEditor.RegisterEvents(eventRegistry); err != nil {
+		return nil, err
+	}
+	consoleApp.OnResize(func(width int, height int) {
+		fmt.Fprintf(consoleApp.Output(), "size: %dx%d\r\n", width, height)
+	})
+	consoleApp.OnStart(func() error {
+		width, height := consoleApp.Size()
+		fmt.Fprintf(consoleApp.Output(), "term: %v %dx%d %v\r\n", session.Term, width, height, session.Getenv("LANG"))
+		return nil
+	})
+	return consoleApp, nil
+}
+
+func setupServer(t *testing.T, newConsoleApp sshserver.AppFactory, configure ...func(server *sshserver.Server)) (string, ssh.PublicKey, ssh.Signer) {
+	hostKey, clientKey := newSigner(t), newSigner(t)
+	server := sshserver.NewServer(hostKey, newConsoleApp)
+	server.SetPublicKeyCallback(sshserver.AuthorizedKeys(clientKey.PublicKey()))
+	for _, configure := range configure {
+		configure(server)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	served := make(chan error, 1)
+	go func() { served <- server.Serve(listener) }()
+	t.Cleanup(func() {
+		assert.NoError(t, server.Close())
+		assert.ErrorIs(t, <-served, sshserver.ErrServerClosed)
+	})
+	return listener.Addr().String(), hostKey.PublicKey(), clientKey
+}
+
+func dial(t *testing.T, address string, hostKey ssh.PublicKey, user string, clientKey ssh.Signer) (*ssh.Client, error) {
+	client, err := ssh.Dial("tcp", address, &ssh.ClientConfig{
+		User:            user,
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(clientKey)},
+		HostKeyCallback: ssh.FixedHostKey(hostKey),
+		Timeout:         5 * time.Second,
+	})
+	if err == nil {
+		t.Cleanup(func() { client.Close() })
+	}
+	return client, err
+}
+
+// startShell opens a session with a pty and starts the console application.
+func startShell(t *testing.T, client *ssh.Client) (*ssh.Session, io.Writer, *sessionOutput) {
+	session, err := client.NewSession()
+	require.NoError(t, err)
+	require.NoError(t, session.Setenv("LANG", "C.UTF-8"))
+	require.NoError(t, session.RequestPty("xterm-256color", 24, 80, ssh.TerminalModes{}))
+	input, err := session.StdinPipe()
+	require.NoError(t, err)
+	output := &sessionOutput{}
+	session.Stdout = output
+	require.NoError(t, session.Shell())
+	return session, input, output
+}
+
+func TestServer(t *testing.T) {
+	t.Parallel()
+
+	address, hostKey, clientKey := setupServer(t, newConsoleApp)
+	client, err := dial(t, address, hostKey, "alice", clientKey)
+	require.NoError(t, err)
+	session, input, output := startShell(t, client)
+	output.waitFor(t, "term: xterm-256color 80x24 C.UTF-8")
+
+	_, err = io.WriteString(input, "hello\r")
+	require.NoError(t, err)
+	output.waitFor(t, "alice: [hello] 1")
+
+	require.NoError(t, session.WindowChange(30, 100))
+	output.waitFor(t, "size: 100x30")
+
+	_, err = io.WriteString(input, "exit\r")
+	require.NoError(t, err)
+	assert.NoError(t, session.Wait())
+}
+
+func TestServerExitStatus(t *testing.T) {
+	t.Parallel()
+
+	address, hostKey, clientKey := setupServer(t, newConsoleApp)
+	client, err := dial(t, address, hostKey, "alice", clientKey)
+	require.NoError(t, err)
+	session, input, _ := startShell(t, client)
+
+	_, err = io.WriteString(input, "fail\r")
+	require.NoError(t, err)
+	var exitError *ssh.ExitError
+	require.ErrorAs(t, session.Wait(), &exitError)
+	assert.Equal(t, 1, exitError.ExitStatus())
+}
+
+func TestServerIsolatesSessions(t *testing.T) {
+	t.Parallel()
+
+	address, hostKey, clientKey := setupServer(t, newConsoleApp)
+	alice, err := dial(t, address, hostKey, "alice", clientKey)
+	require.NoError(t, err)
+	bob, err := dial(t, address, hostKey, "bob", clientKey)
+	require.NoError(t, err)
+	_, aliceInput, aliceOutput := startShell(t, alice)
+	_, bobInput, bobOutput := startShell(t, bob)
+
+	// Pasted input arrives at once and is split into keys
+	_, err = io.WriteString(aliceInput, "one\rtwo\r")
+	require.NoError(t, err)
+	aliceOutput.waitFor(t, "alice: [two] 2")
+	_, err = io.WriteString(bobInput, "three\r")
+	require.NoError(t, err)
+	bobOutput.waitFor(t, "bob: [three] 1")
+}
+
+func TestServerAuthentication(t *testing.T) {
+	t.Parallel()
+
+	address, hostKey, _ := setupServer(t, newConsoleApp)
+	_, err := dial(t, address, hostKey, "mallory", newSigner(t))
+	assert.ErrorContains(t, err, "unable to authenticate")
+}
+
+func TestServerHandshakeTimeout(t *testing.T) {
+	t.Parallel()
+
+	address, hostKey, clientKey := setupServer(t, newConsoleApp, func(server *sshserver.Server) {
+		server.SetHandshakeTimeout(200 * time.Millisecond)
+	})
+
+	// A client that never completes the handshake is disconnected
+	conn, err := net.Dial("tcp", address)
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	_, err = io.Copy(io.Discard, conn)
+	assert.NoError(t, err)
+
+	// The deadline does not apply to sessions once the handshake completed
+	client, err := dial(t, address, hostKey, "alice", clientKey)
+	require.NoError(t, err)
+	_, input, output := startShell(t, client)
+	output.waitFor(t, "term: xterm-256color")
+	time.Sleep(400 * time.Millisecond)
+	_, err = io.WriteString(input, "hello\r")
+	require.NoError(t, err)
+	output.waitFor(t, "alice: [hello] 1")
+}
+
+func TestServerRejectsSession(t *testing.T) {
+	t.Parallel()
+
+	address, hostKey, clientKey := setupServer(t, func(session sshserver.Session) (*cyclecmd.ConsoleApp, error) {
+		assert.NotEmpty(t, session.Permissions.Extensions[sshserver.FINGERPRINT_EXTENSION])
+		return nil, fmt.Errorf("user %v is not allowed", session.User)
+	})
+	client, err := dial(t, address, hostKey, "alice", clientKey)
+	require.NoError(t, err)
+	session, err := client.NewSession()
+	require.NoError(t, err)
+	assert.Error(t, session.Shell())
+
+	// Commands are not supported
+	session, err = client.NewSession()
+	require.NoError(t, err)
+	assert.Error(t, session.Run("ls"))
+}
+
+func TestLoadAuthorizedKeys(t *testing.T) {
+	t.Parallel()
+
+	key := newSigner(t).PublicKey()
+	path := filepath.Join(t.TempDir(), "authorized_keys")
+	require.NoError(t, os.WriteFile(path, []byte("# team\n\n"+string(ssh.MarshalAuthorizedKey(key))), 0o600))
+	keys, err := sshserver.LoadAuthorizedKeys(path)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, key.Marshal(), keys[0].Marshal())
+
+	require.NoError(t, os.WriteFile(path, []byte("ssh-ed25519 invalid\n"), 0o600))
+	_, err = sshserver.LoadAuthorizedKeys(path)
+	assert.ErrorContains(t, err, "key in line 1")
+}
@@ -0,0 +1,177 @@
+package sshserver
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/RaphSku/cyclecmd"
+	"github.com/RaphSku/cyclecmd/style"
+	"golang.org/x/crypto/ssh"
+)
+
+// Session describes the SSH session a console application is created for.
+type Session struct {
+	// User the client authenticated as
+	User string
+	// RemoteAddr is the address of the client
+	RemoteAddr net.Addr
+	// Permissions returned by the authentication callback, might be nil
+	Permissions *ssh.Permissions
+	// Term is the terminal type of the pty the client requested, e.g. "xterm-256color". It is empty if the client
+	// did not request a pty, e.g. when input is piped into ssh
+	Term string
+	// Width and Height are the size of the terminal of the client, both are 0 if the client did not request a pty
+	Width  int
+	Height int
+	// Environment contains the environment variables the client sent
+	Environment map[string]string
+}
+
+// Getenv returns the value of an environment variable the client sent, TERM is the terminal type of the pty.
+//
+// Parameters:
+//   - `key` : Name of the environment variable
+//
+// Returns:
+//   - `string` : Value of the environment variable, empty if it was not sent
+func (s Session) Getenv(key string) string {
+	if key == "TERM" && s.Term != "" {
+		return s.Term
+	}
+	return s.Environment[key]
+}
+
+// ptyRequest is the payload of a pty-req request, see RFC 4254 section 6.2.
+type ptyRequest struct {
+	Term          string
+	Columns       uint32
+	Rows          uint32
+	WidthPixels   uint32
+	HeightPixels  uint32
+	TerminalModes string
+}
+
+// windowChange is the payload of a window-change request, see RFC 4254 section 6.7.
+type windowChange struct {
+	Columns      uint32
+	Rows         uint32
+	WidthPixels  uint32
+	HeightPixels uint32
+}
+
+// envRequest is the payload of an env request, see RFC 4254 section 6.4.
+type envRequest struct {
+	Name  string
+	Value string
+}
+
+// exitStatus is the payload of an exit-status request, see RFC 4254 section 6.10.
+type exitStatus struct {
+	Status uint32
+}
+
+// handleSession handles the requests of a session channel. Once the client requests a shell, the console
+// application of the session is started, window changes resize it while it runs. The channel is closed once the
+// console application concluded.
+//
+// Parameters:
+//   - `serverConn` : Connection the session belongs to
+//   - `newChannel` : Session channel the client opened
+func (s *Server) handleSession(serverConn *ssh.ServerConn, newChannel ssh.NewChannel) {
+	channel, requests, err := newChannel.Accept()
+	if err != nil {
+		return
+	}
+	defer channel.Close()
+
+	session := Session{
+		User:        serverConn.User(),
+		RemoteAddr:  serverConn.RemoteAddr(),
+		Permissions: serverConn.Permissions,
+		Environment: make(map[string]string),
+	}
+	var consoleApp *cyclecmd.ConsoleApp
+	concluded := make(chan struct{})
+	for request := range requests {
+		switch request.Type {
+		case "pty-req":
+			var payload ptyRequest
+			if consoleApp != nil || ssh.Unmarshal(request.Payload, &payload) != nil {
+				request.Reply(false, nil)
+				continue
+			}
+			session.Term, session.Width, session.Height = payload.Term, int(payload.Columns), int(payload.Rows)
+			request.Reply(true, nil)
+		case "env":
+			var payload envRequest
+			if consoleApp != nil || ssh.Unmarshal(request.Payload, &payload) != nil {
+				request.Reply(false, nil)
+				continue
+			}
+			session.Environment[payload.Name] = payload.Value
+			request.Reply(true, nil)
+		case "window-change":
+			var payload windowChange
+			if ssh.Unmarshal(request.Payload, &payload) != nil {
+				continue
+			}
+			session.Width, session.Height = int(payload.Columns), int(payload.Rows)
+			if consoleApp != nil {
+				consoleApp.Resize(session.Width, session.Height)
+			}
+		case "shell":
+			if consoleApp != nil {
+				request.Reply(false, nil)
+				continue
+			}
+			consoleApp, err = s.newConsoleApp(session)
+			if err != nil {
+				request.Reply(false, nil)
+				fmt.Fprintf(channel.Stderr(), "session could not be started! error: %v\r\n", err)
+				return
+			}
+			attachConsoleApp(consoleApp, session, channel)
+			request.Reply(true, nil)
+			go func(consoleApp *cyclecmd.ConsoleApp) {
+				defer close(concluded)
+				runConsoleApp(consoleApp, channel)
+			}(consoleApp)
+		default:
+			// Commands and subsystems are not supported, the console application is the only program of a session
+			request.Reply(false, nil)
+		}
+	}
+	// The client closed the channel or the connection, the console application concludes with the end of the input
+	if consoleApp != nil {
+		<-concluded
+	}
+}
+
+// attachConsoleApp attaches the console application to the session channel, i.e. its input, output, error output,
+// size and colour profile are set.
+//
+// Parameters:
+//   - `consoleApp` : Console application of the session
+//   - `session` : Session the console application was created for
+//   - `channel` : Session channel
+func attachConsoleApp(consoleApp *cyclecmd.ConsoleApp, session Session, channel ssh.Channel) {
+	consoleApp.AttachRemoteTerminal(channel, channel.Stderr())
+	hasTerminal := session.Term != ""
+	if hasTerminal {
+		consoleApp.Resize(session.Width, session.Height)
+	}
+	consoleApp.SetColorProfile(style.DetectColorProfileFromEnv(session.Getenv, hasTerminal))
+}
+
+// runConsoleApp runs the event loop of the console application. The exit status of the console application is sent
+// to the client and the channel is closed afterwards.
+//
+// Parameters:
+//   - `consoleApp` : Console application of the session
+//   - `channel` : Session channel
+func runConsoleApp(consoleApp *cyclecmd.ConsoleApp, channel ssh.Channel) {
+	consoleApp.Start()
+
+	channel.SendRequest("exit-status", false, ssh.Marshal(exitStatus{Status: uint32(consoleApp.ExitStatus())}))
+	channel.Close()
+}
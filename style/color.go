@@ -0,0 +1,285 @@
+// Package style renders text with ANSI colours and text attributes and degrades the colours to the
+// colour depth that the terminal supports.
+package style
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// colorKind distinguishes how the value of a colour is interpreted.
+type colorKind int
+
+const (
+	// kindNone is the zero value of a colour, no colour is rendered
+	kindNone colorKind = iota
+	// kindANSI16 is one of the 16 basic colours
+	kindANSI16
+	// kindANSI256 is one of the 256 colours of the xterm palette
+	kindANSI256
+	// kindTrueColor is a 24 bit RGB colour
+	kindTrueColor
+)
+
+// Color is a foreground or background colour, the zero value represents no colour.
+type Color struct {
+	kind    colorKind
+	index   uint8
+	r, g, b uint8
+}
+
+// The 16 basic colours that every colour terminal supports.
+var (
+	Black         = ANSIColor(0)
+	Red           = ANSIColor(1)
+	Green         = ANSIColor(2)
+	Yellow        = ANSIColor(3)
+	Blue          = ANSIColor(4)
+	Magenta       = ANSIColor(5)
+	Cyan          = ANSIColor(6)
+	White         = ANSIColor(7)
+	BrightBlack   = ANSIColor(8)
+	BrightRed     = ANSIColor(9)
+	BrightGreen   = ANSIColor(10)
+	BrightYellow  = ANSIColor(11)
+	BrightBlue    = ANSIColor(12)
+	BrightMagenta = ANSIColor(13)
+	BrightCyan    = ANSIColor(14)
+	BrightWhite   = ANSIColor(15)
+)
+
+// ansi16RGB contains the RGB values of the 16 basic colours as used by xterm, they are used to degrade colours.
+var ansi16RGB = [16][3]uint8{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0}, {0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0}, {92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+// ANSIColor returns one of the 16 basic colours.
+//
+// Parameters:
+//   - `index` : Index of the colour between 0 and 15, other indices are wrapped, e.g. -1 is 15
+//
+// Returns:
+//   - `Color` : The basic colour
+func ANSIColor(index int) Color {
+	return Color{kind: kindANSI16, index: uint8((index%16 + 16) % 16)}
+}
+
+// ANSI256Color returns one of the 256 colours of the xterm palette.
+//
+// Parameters:
+//   - `index` : Index of the colour between 0 and 255
+//
+// Returns:
+//   - `Color` : The palette colour
+func ANSI256Color(index uint8) Color {
+	return Color{kind: kindANSI256, index: index}
+}
+
+// RGBColor returns a 24 bit colour.
+//
+// Parameters:
+//   - `r`, `g`, `b` : Red, green and blue component of the colour
+//
+// Returns:
+//   - `Color` : The truecolor colour
+func RGBColor(r uint8, g uint8, b uint8) Color {
+	return Color{kind: kindTrueColor, r: r, g: g, b: b}
+}
+
+// HexColor parses a colour in the form #rrggbb.
+//
+// Parameters:
+//   - `hex` : Colour in hexadecimal notation, the leading # is optional
+//
+// Returns:
+//   - `Color` : The truecolor colour
+//   - `error` : Returns an error when the colour is not in the form #rrggbb
+func HexColor(hex string) (Color, error) {
+	hex = strings.TrimPrefix(hex, "#")
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if len(hex) != 6 || err != nil {
+		return Color{}, fmt.Errorf("colour %q is invalid, expected the form #rrggbb", hex)
+	}
+	return RGBColor(uint8(value>>16), uint8(value>>8), uint8(value)), nil
+}
+
+// colorNames maps the names of the 16 basic colours to the colours, see ParseColor.
+var colorNames = map[string]Color{
+	"black": Black, "red": Red, "green": Green, "yellow": Yellow,
+	"blue": Blue, "magenta": Magenta, "cyan": Cyan, "white": White,
+	"brightblack": BrightBlack, "brightred": BrightRed, "brightgreen": BrightGreen, "brightyellow": BrightYellow,
+	"brightblue": BrightBlue, "brightmagenta": BrightMagenta, "brightcyan": BrightCyan, "brightwhite": BrightWhite,
+}
+
+// ParseColor parses a colour as it is written in configuration files: the name of a basic colour like `red` or
+// `bright-red`, an index of the xterm palette between 0 and 255 or a truecolor colour in the form #rrggbb. An
+// empty string is no colour.
+//
+// Parameters:
+//   - `name` : Colour in one of the notations, names are case insensitive
+//
+// Returns:
+//   - `Color` : The colour
+//   - `error` : Returns an error when the colour is in none of the notations
+func ParseColor(name string) (Color, error) {
+	if name == "" {
+		return Color{}, nil
+	}
+	if strings.HasPrefix(name, "#") {
+		return HexColor(name)
+	}
+	if index, err := strconv.ParseUint(name, 10, 8); err == nil {
+		return ANSI256Color(uint8(index)), nil
+	}
+	normalizedName := strings.NewReplacer("-", "", "_", "", " ", "").Replace(strings.ToLower(name))
+	if color, ok := colorNames[normalizedName]; ok {
+		return color, nil
+	}
+	return Color{}, fmt.Errorf("colour %q is unknown, expected a name like red, a palette index or the form #rrggbb", name)
+}
+
+// IsZero returns whether the colour is the zero value, i.e. no colour.
+//
+// Returns:
+//   - `bool` : True if no colour is set
+func (c Color) IsZero() bool {
+	return c.kind == kindNone
+}
+
+// sequence returns the SGR parameters of the colour degraded to the colour profile.
+//
+// Parameters:
+//   - `profile` : Colour profile of the terminal
+//   - `background` : Whether the colour is used as background colour
+//
+// Returns:
+//   - `string` : SGR parameters, empty if no colour is rendered
+func (c Color) sequence(profile ColorProfile, background bool) string {
+	c = c.degrade(profile)
+	offset := 0
+	if background {
+		offset = 10
+	}
+	switch c.kind {
+	case kindANSI16:
+		if c.index < 8 {
+			return strconv.Itoa(30 + offset + int(c.index))
+		}
+		return strconv.Itoa(90 + offset + int(c.index) - 8)
+	case kindANSI256:
+		return fmt.Sprintf("%d;5;%d", 38+offset, c.index)
+	case kindTrueColor:
+		return fmt.Sprintf("%d;2;%d;%d;%d", 38+offset, c.r, c.g, c.b)
+	}
+	return ""
+}
+
+// degrade converts the colour to the best colour that the colour profile supports.
+//
+// Parameters:
+//   - `profile` : Colour profile of the terminal
+//
+// Returns:
+//   - `Color` : Colour that is supported by the colour profile
+func (c Color) degrade(profile ColorProfile) Color {
+	switch profile {
+	case PROFILE_NO_COLOR:
+		return Color{}
+	case PROFILE_ANSI16:
+		switch c.kind {
+		case kindANSI256:
+			r, g, b := c.rgb()
+			return nearestANSI16(r, g, b)
+		case kindTrueColor:
+			return nearestANSI16(c.r, c.g, c.b)
+		}
+	case PROFILE_ANSI256:
+		if c.kind == kindTrueColor {
+			return ANSI256Color(nearestANSI256(c.r, c.g, c.b))
+		}
+	}
+	return c
+}
+
+// rgb returns the RGB values of a palette colour.
+//
+// Returns:
+//   - `uint8` : Red, green and blue component of the colour
+func (c Color) rgb() (uint8, uint8, uint8) {
+	switch c.kind {
+	case kindANSI16:
+		return ansi16RGB[c.index][0], ansi16RGB[c.index][1], ansi16RGB[c.index][2]
+	case kindANSI256:
+		if c.index < 16 {
+			return ansi16RGB[c.index][0], ansi16RGB[c.index][1], ansi16RGB[c.index][2]
+		}
+		if c.index >= 232 {
+			gray := uint8(8 + 10*(int(c.index)-232))
+			return gray, gray, gray
+		}
+		cubeLevels := [6]uint8{0, 95, 135, 175, 215, 255}
+		index := int(c.index) - 16
+		return cubeLevels[index/36], cubeLevels[(index/6)%6], cubeLevels[index%6]
+	}
+	return c.r, c.g, c.b
+}
+
+// nearestANSI256 returns the index of the palette colour of the 6x6x6 colour cube or the gray ramp that is
+// nearest to the RGB colour.
+//
+// Parameters:
+//   - `r`, `g`, `b` : Red, green and blue component of the colour
+//
+// Returns:
+//   - `uint8` : Index of the nearest palette colour
+func nearestANSI256(r uint8, g uint8, b uint8) uint8 {
+	toCubeLevel := func(v uint8) int {
+		if v < 48 {
+			return 0
+		}
+		if v < 115 {
+			return 1
+		}
+		return (int(v) - 35) / 40
+	}
+	cubeIndex := uint8(16 + 36*toCubeLevel(r) + 6*toCubeLevel(g) + toCubeLevel(b))
+	average := (int(r) + int(g) + int(b)) / 3
+	grayIndex := uint8(232 + min(23, max(0, (average-3)/10)))
+
+	cubeR, cubeG, cubeB := ANSI256Color(cubeIndex).rgb()
+	grayR, grayG, grayB := ANSI256Color(grayIndex).rgb()
+	if distance(r, g, b, grayR, grayG, grayB) < distance(r, g, b, cubeR, cubeG, cubeB) {
+		return grayIndex
+	}
+	return cubeIndex
+}
+
+// nearestANSI16 returns the basic colour that is nearest to the RGB colour.
+//
+// Parameters:
+//   - `r`, `g`, `b` : Red, green and blue component of the colour
+//
+// Returns:
+//   - `Color` : The nearest basic colour
+func nearestANSI16(r uint8, g uint8, b uint8) Color {
+	nearest := 0
+	nearestDistance := -1
+	for index, rgb := range ansi16RGB {
+		d := distance(r, g, b, rgb[0], rgb[1], rgb[2])
+		if nearestDistance < 0 || d < nearestDistance {
+			nearest = index
+			nearestDistance = d
+		}
+	}
+	return ANSIColor(nearest)
+}
+
+// distance returns the squared euclidean distance between two RGB colours.
+func distance(r1 uint8, g1 uint8, b1 uint8, r2 uint8, g2 uint8, b2 uint8) int {
+	dr := int(r1) - int(r2)
+	dg := int(g1) - int(g2)
+	db := int(b1) - int(b2)
+	return dr*dr + dg*dg + db*db
+}
@@ -0,0 +1,100 @@
+package style
+
+import (
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// ColorProfile is the colour depth that a terminal supports.
+type ColorProfile int
+
+const (
+	// PROFILE_NO_COLOR renders no colours and no text attributes.
+	PROFILE_NO_COLOR ColorProfile = iota
+	// PROFILE_ANSI16 renders the 16 basic colours.
+	PROFILE_ANSI16
+	// PROFILE_ANSI256 renders the 256 colours of the xterm palette.
+	PROFILE_ANSI256
+	// PROFILE_TRUECOLOR renders 24 bit colours.
+	PROFILE_TRUECOLOR
+)
+
+// DetectColorProfile detects the colour profile of the writer. Styles are stripped when the writer is not a
+// terminal, see DetectColorProfileFromEnv for the environment variables that are respected.
+//
+// Parameters:
+//   - `w` : Writer the styled text is written to
+//
+// Returns:
+//   - `ColorProfile` : Colour profile of the writer
+func DetectColorProfile(w io.Writer) ColorProfile {
+	isTerminal := false
+	if file, ok := w.(*os.File); ok {
+		isTerminal = term.IsTerminal(int(file.Fd()))
+	}
+	return DetectColorProfileFromEnv(os.Getenv, isTerminal)
+}
+
+// DetectColorProfileFromEnv detects the colour profile from the environment:
+//   - `NO_COLOR` : If set to a non-empty value, no colours are rendered
+//   - `FORCE_COLOR` : If set, colours are rendered even if the output is no terminal. The levels 0 to 3 select
+//     no colour, 16 colours, 256 colours or truecolor
+//   - `COLORTERM` : truecolor or 24bit select truecolor
+//   - `TERM` : dumb disables colours, a value that contains 256color selects 256 colours
+//
+// Parameters:
+//   - `getenv` : Looks up an environment variable, e.g. os.Getenv
+//   - `isTerminal` : Whether the output is a terminal
+//
+// Returns:
+//   - `ColorProfile` : Detected colour profile
+func DetectColorProfileFromEnv(getenv func(key string) string, isTerminal bool) ColorProfile {
+	if getenv("NO_COLOR") != "" {
+		return PROFILE_NO_COLOR
+	}
+
+	forceColor := strings.ToLower(getenv("FORCE_COLOR"))
+	if forceColor != "" {
+		if level, err := strconv.Atoi(forceColor); err == nil {
+			return ColorProfile(min(max(level, 0), int(PROFILE_TRUECOLOR)))
+		}
+		if forceColor == "false" {
+			return PROFILE_NO_COLOR
+		}
+		return max(PROFILE_ANSI16, profileFromTerm(getenv))
+	}
+
+	if !isTerminal {
+		return PROFILE_NO_COLOR
+	}
+	return profileFromTerm(getenv)
+}
+
+// profileFromTerm detects the colour profile from COLORTERM and TERM.
+//
+// Parameters:
+//   - `getenv` : Looks up an environment variable
+//
+// Returns:
+//   - `ColorProfile` : Detected colour profile
+func profileFromTerm(getenv func(key string) string) ColorProfile {
+	colorTerm := strings.ToLower(getenv("COLORTERM"))
+	if colorTerm == "truecolor" || colorTerm == "24bit" {
+		return PROFILE_TRUECOLOR
+	}
+	terminal := strings.ToLower(getenv("TERM"))
+	if terminal == "dumb" {
+		return PROFILE_NO_COLOR
+	}
+	if strings.Contains(terminal, "256color") {
+		return PROFILE_ANSI256
+	}
+	if strings.Contains(terminal, "truecolor") || strings.Contains(terminal, "direct") {
+		return PROFILE_TRUECOLOR
+	}
+	return PROFILE_ANSI16
+}
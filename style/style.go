@@ -0,0 +1,165 @@
+package style
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ansiSequence matches CSI and OSC escape sequences.
+var ansiSequence = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(\x07|\x1b\\)`)
+
+// Style describes the colours and text attributes of text, the zero value renders plain text.
+type Style struct {
+	// Foreground colour of the text
+	Foreground Color
+	// Background colour of the text
+	Background Color
+	Bold       bool
+	Faint      bool
+	Italic     bool
+	Underline  bool
+	Reverse    bool
+}
+
+// New returns a style without colours and text attributes.
+//
+// Returns:
+//   - `Style` : Plain style
+func New() Style {
+	return Style{}
+}
+
+// Fg returns a copy of the style with the foreground colour.
+//
+// Parameters:
+//   - `color` : Foreground colour
+//
+// Returns:
+//   - `Style` : Copy of the style
+func (s Style) Fg(color Color) Style {
+	s.Foreground = color
+	return s
+}
+
+// Bg returns a copy of the style with the background colour.
+//
+// Parameters:
+//   - `color` : Background colour
+//
+// Returns:
+//   - `Style` : Copy of the style
+func (s Style) Bg(color Color) Style {
+	s.Background = color
+	return s
+}
+
+// WithBold returns a copy of the style with bold text.
+//
+// Returns:
+//   - `Style` : Copy of the style
+func (s Style) WithBold() Style {
+	s.Bold = true
+	return s
+}
+
+// WithFaint returns a copy of the style with faint text.
+//
+// Returns:
+//   - `Style` : Copy of the style
+func (s Style) WithFaint() Style {
+	s.Faint = true
+	return s
+}
+
+// WithItalic returns a copy of the style with italic text.
+//
+// Returns:
+//   - `Style` : Copy of the style
+func (s Style) WithItalic() Style {
+	s.Italic = true
+	return s
+}
+
+// WithUnderline returns a copy of the style with underlined text.
+//
+// Returns:
+//   - `Style` : Copy of the style
+func (s Style) WithUnderline() Style {
+	s.Underline = true
+	return s
+}
+
+// WithReverse returns a copy of the style with swapped foreground and background colours.
+//
+// Returns:
+//   - `Style` : Copy of the style
+func (s Style) WithReverse() Style {
+	s.Reverse = true
+	return s
+}
+
+// Sequence returns the escape sequence that switches to the style, the colours are degraded to the colour profile.
+//
+// Parameters:
+//   - `profile` : Colour profile of the terminal
+//
+// Returns:
+//   - `string` : Escape sequence, empty if nothing is rendered
+func (s Style) Sequence(profile ColorProfile) string {
+	if profile == PROFILE_NO_COLOR {
+		return ""
+	}
+	var parameters []string
+	if s.Bold {
+		parameters = append(parameters, "1")
+	}
+	if s.Faint {
+		parameters = append(parameters, "2")
+	}
+	if s.Italic {
+		parameters = append(parameters, "3")
+	}
+	if s.Underline {
+		parameters = append(parameters, "4")
+	}
+	if s.Reverse {
+		parameters = append(parameters, "7")
+	}
+	if foreground := s.Foreground.sequence(profile, false); foreground != "" {
+		parameters = append(parameters, foreground)
+	}
+	if background := s.Background.sequence(profile, true); background != "" {
+		parameters = append(parameters, background)
+	}
+	if len(parameters) == 0 {
+		return ""
+	}
+	return "\x1b[" + strings.Join(parameters, ";") + "m"
+}
+
+// Render renders the text with the style, the style is reset at the end of the text.
+//
+// Parameters:
+//   - `profile` : Colour profile of the terminal
+//   - `text` : Text that is styled
+//
+// Returns:
+//   - `string` : Styled text, plain text if nothing is rendered
+func (s Style) Render(profile ColorProfile, text string) string {
+	sequence := s.Sequence(profile)
+	if sequence == "" || text == "" {
+		return text
+	}
+	return sequence + text + "\x1b[0m"
+}
+
+// Strip removes all ANSI escape sequences from the text.
+//
+// Parameters:
+//   - `text` : Text that might contain escape sequences
+//
+// Returns:
+//   - `string` : Text without escape sequences
+func Strip(text string) string {
+	return ansiSequence.ReplaceAllString(text, "")
+}
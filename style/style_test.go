@@ -0,0 +1,126 @@
+//go:build unit_test
+
+package style_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/RaphSku/cyclecmd/style"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStyleRendering(t *testing.T) {
+	t.Parallel()
+
+	textStyle := style.New().Fg(style.Red).Bg(style.BrightBlue).WithBold().WithUnderline()
+	assert.Equal(t, "\x1b[1;4;31;104mtext\x1b[0m", textStyle.Render(style.PROFILE_ANSI16, "text"))
+	assert.Equal(t, "text", textStyle.Render(style.PROFILE_NO_COLOR, "text"))
+	assert.Equal(t, "text", style.New().Render(style.PROFILE_TRUECOLOR, "text"))
+
+	trueColorStyle := style.New().Fg(style.RGBColor(255, 135, 0))
+	assert.Equal(t, "\x1b[38;2;255;135;0mtext\x1b[0m", trueColorStyle.Render(style.PROFILE_TRUECOLOR, "text"))
+	assert.Equal(t, "\x1b[38;5;208mtext\x1b[0m", trueColorStyle.Render(style.PROFILE_ANSI256, "text"))
+	assert.Equal(t, "\x1b[33mtext\x1b[0m", trueColorStyle.Render(style.PROFILE_ANSI16, "text"))
+
+	paletteStyle := style.New().Bg(style.ANSI256Color(196))
+	assert.Equal(t, "\x1b[48;5;196mtext\x1b[0m", paletteStyle.Render(style.PROFILE_TRUECOLOR, "text"))
+	assert.Equal(t, "\x1b[101mtext\x1b[0m", paletteStyle.Render(style.PROFILE_ANSI16, "text"))
+
+	// Indices outside of the 16 basic colours are wrapped
+	assert.Equal(t, style.BrightWhite, style.ANSIColor(-1))
+	assert.Equal(t, style.Black, style.ANSIColor(16))
+	assert.Equal(t, "\x1b[97mtext\x1b[0m", style.New().Fg(style.ANSIColor(-1)).Render(style.PROFILE_ANSI16, "text"))
+}
+
+func TestHexColor(t *testing.T) {
+	t.Parallel()
+
+	color, err := style.HexColor("#ff8700")
+	assert.NoError(t, err)
+	assert.Equal(t, style.RGBColor(255, 135, 0), color)
+
+	_, err = style.HexColor("#ff87")
+	if assert.Error(t, err) {
+		assert.Equal(t, fmt.Errorf("colour %q is invalid, expected the form #rrggbb", "ff87"), err)
+	}
+}
+
+func TestParseColor(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		expColor style.Color
+	}{
+		{name: "", expColor: style.Color{}},
+		{name: "red", expColor: style.Red},
+		{name: "Bright-Cyan", expColor: style.BrightCyan},
+		{name: "bright_black", expColor: style.BrightBlack},
+		{name: "208", expColor: style.ANSI256Color(208)},
+		{name: "#ff8700", expColor: style.RGBColor(255, 135, 0)},
+	}
+	for _, test := range tests {
+		actColor, err := style.ParseColor(test.name)
+		assert.NoError(t, err, test.name)
+		assert.Equal(t, test.expColor, actColor, test.name)
+	}
+
+	for _, name := range []string{"orange", "256", "#ff87"} {
+		_, err := style.ParseColor(name)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestStrip(t *testing.T) {
+	t.Parallel()
+
+	styled := style.New().Fg(style.Green).WithBold().Render(style.PROFILE_ANSI16, "ok")
+	assert.Equal(t, "ok", style.Strip(styled+"\x1b[2K"))
+}
+
+func TestDetectColorProfileFromEnv(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		env        map[string]string
+		isTerminal bool
+		expProfile style.ColorProfile
+	}{
+		{map[string]string{"TERM": "xterm"}, true, style.PROFILE_ANSI16},
+		{map[string]string{"TERM": "xterm-256color"}, true, style.PROFILE_ANSI256},
+		{map[string]string{"TERM": "xterm-256color", "COLORTERM": "truecolor"}, true, style.PROFILE_TRUECOLOR},
+		{map[string]string{"TERM": "dumb"}, true, style.PROFILE_NO_COLOR},
+		{map[string]string{"TERM": "xterm-256color"}, false, style.PROFILE_NO_COLOR},
+		{map[string]string{"TERM": "xterm-256color", "NO_COLOR": "1"}, true, style.PROFILE_NO_COLOR},
+		{map[string]string{"TERM": "xterm-256color", "FORCE_COLOR": "true"}, false, style.PROFILE_ANSI256},
+		{map[string]string{"TERM": "xterm-256color", "FORCE_COLOR": "1"}, false, style.PROFILE_ANSI16},
+		{map[string]string{"FORCE_COLOR": "3"}, false, style.PROFILE_TRUECOLOR},
+		{map[string]string{"FORCE_COLOR": "0"}, true, style.PROFILE_NO_COLOR},
+		{map[string]string{"FORCE_COLOR": "1", "NO_COLOR": "1"}, true, style.PROFILE_NO_COLOR},
+	}
+	for _, testCase := range testCases {
+		getenv := func(key string) string {
+			return testCase.env[key]
+		}
+		assert.Equal(t, testCase.expProfile, style.DetectColorProfileFromEnv(getenv, testCase.isTerminal), testCase.env)
+	}
+}
+
+func TestWidth(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, 1, style.RuneWidth('a'))
+	assert.Equal(t, 2, style.RuneWidth('日'))
+	assert.Equal(t, 2, style.RuneWidth('한'))
+	assert.Equal(t, 2, style.RuneWidth('Ａ'))
+	assert.Equal(t, 2, style.RuneWidth('🚀'))
+	assert.Equal(t, 0, style.RuneWidth('\u0301'))
+	assert.Equal(t, 0, style.RuneWidth('\ufe0f'))
+	assert.Equal(t, 0, style.RuneWidth('\x1b'))
+
+	assert.Equal(t, 5, style.Width("hello"))
+	assert.Equal(t, 6, style.Width("日本語"))
+	assert.Equal(t, 4, style.Width("é🚀a"))
+	assert.Equal(t, 4, style.Width(style.New().Fg(style.Red).Render(style.PROFILE_ANSI16, "text")))
+}
@@ -0,0 +1,85 @@
+package style
+
+// Theme is a named set of styles that a console application uses for its own output.
+type Theme struct {
+	// Name of the theme
+	Name string
+	// Prompt is the style of the prompt that is printed after the delimiter
+	Prompt Style
+	// Error is the style of error messages
+	Error Style
+	// HelpKey is the style of keys and command names in help output
+	HelpKey Style
+	// HelpDescription is the style of descriptions in help output
+	HelpDescription Style
+	// StatusLine is the style of the status line at the bottom of the screen
+	StatusLine Style
+	// Selection is the style of the highlighted option of prompt widgets
+	Selection Style
+	// TableHeader is the style of the header row of tables
+	TableHeader Style
+	// Progress is the style of spinners and of the bar of progress bars
+	Progress Style
+}
+
+// DefaultTheme returns the theme that is used by default. The prompt is not styled, so that the delimiter is
+// printed as it was defined.
+//
+// Returns:
+//   - `Theme` : The default theme
+func DefaultTheme() Theme {
+	return Theme{
+		Name:            "default",
+		Prompt:          New(),
+		Error:           New().Fg(Red),
+		HelpKey:         New().WithBold(),
+		HelpDescription: New(),
+		StatusLine:      New().WithReverse(),
+		Selection:       New().WithBold(),
+		TableHeader:     New().WithBold(),
+		Progress:        New().Fg(Cyan),
+	}
+}
+
+// PlainTheme returns a theme without any styles.
+//
+// Returns:
+//   - `Theme` : The plain theme
+func PlainTheme() Theme {
+	return Theme{Name: "plain"}
+}
+
+// ColorfulTheme returns a theme that styles all output of the console application.
+//
+// Returns:
+//   - `Theme` : The colourful theme
+func ColorfulTheme() Theme {
+	return Theme{
+		Name:            "colorful",
+		Prompt:          New().Fg(BrightGreen).WithBold(),
+		Error:           New().Fg(BrightRed).WithBold(),
+		HelpKey:         New().Fg(Cyan).WithBold(),
+		HelpDescription: New().WithFaint(),
+		StatusLine:      New().Fg(Black).Bg(Cyan),
+		Selection:       New().Fg(BrightCyan).WithBold(),
+		TableHeader:     New().Fg(Cyan).WithBold().WithUnderline(),
+		Progress:        New().Fg(BrightGreen).WithBold(),
+	}
+}
+
+// ThemeByName returns one of the predefined themes by its name, i.e. `default`, `plain` or `colorful`.
+//
+// Parameters:
+//   - `name` : Name of the theme
+//
+// Returns:
+//   - `Theme` : The theme
+//   - `bool` : False if no predefined theme has the name
+func ThemeByName(name string) (Theme, bool) {
+	for _, theme := range []Theme{DefaultTheme(), PlainTheme(), ColorfulTheme()} {
+		if theme.Name == name {
+			return theme, true
+		}
+	}
+	return Theme{}, false
+}
@@ -0,0 +1,149 @@
+package style
+
+import (
+	"unicode"
+)
+
+// wideRanges contains the ranges of characters that occupy two columns in a terminal, these are the East Asian
+// wide and fullwidth characters as well as emoji that are presented as pictographs by default.
+var wideRanges = [][2]rune{
+	{0x1100, 0x115F},   // Hangul Jamo initial consonants
+	{0x231A, 0x231B},   // Watch, hourglass
+	{0x23E9, 0x23EC},   // Media control symbols
+	{0x23F0, 0x23F0},   // Alarm clock
+	{0x23F3, 0x23F3},   // Hourglass with flowing sand
+	{0x25FD, 0x25FE},   // Medium small squares
+	{0x2614, 0x2615},   // Umbrella with rain drops, hot beverage
+	{0x2648, 0x2653},   // Zodiac signs
+	{0x267F, 0x267F},   // Wheelchair symbol
+	{0x2693, 0x2693},   // Anchor
+	{0x26A1, 0x26A1},   // High voltage
+	{0x26AA, 0x26AB},   // Medium circles
+	{0x26BD, 0x26BE},   // Soccer ball, baseball
+	{0x26C4, 0x26C5},   // Snowman, sun behind cloud
+	{0x26CE, 0x26CE},   // Ophiuchus
+	{0x26D4, 0x26D4},   // No entry
+	{0x26EA, 0x26EA},   // Church
+	{0x26F2, 0x26F3},   // Fountain, flag in hole
+	{0x26F5, 0x26F5},   // Sailboat
+	{0x26FA, 0x26FA},   // Tent
+	{0x26FD, 0x26FD},   // Fuel pump
+	{0x2705, 0x2705},   // Check mark button
+	{0x270A, 0x270B},   // Raised fists
+	{0x2728, 0x2728},   // Sparkles
+	{0x274C, 0x274C},   // Cross mark
+	{0x274E, 0x274E},   // Cross mark button
+	{0x2753, 0x2755},   // Question and exclamation marks
+	{0x2757, 0x2757},   // Exclamation mark
+	{0x2795, 0x2797},   // Plus, minus, division
+	{0x27B0, 0x27B0},   // Curly loop
+	{0x27BF, 0x27BF},   // Double curly loop
+	{0x2B1B, 0x2B1C},   // Large squares
+	{0x2B50, 0x2B50},   // Star
+	{0x2B55, 0x2B55},   // Hollow red circle
+	{0x2E80, 0x303E},   // CJK radicals, Kangxi radicals, CJK symbols and punctuation
+	{0x3041, 0x33FF},   // Hiragana, Katakana, Bopomofo, Hangul compatibility Jamo, CJK compatibility
+	{0x3400, 0x4DBF},   // CJK unified ideographs extension A
+	{0x4E00, 0x9FFF},   // CJK unified ideographs
+	{0xA000, 0xA4CF},   // Yi syllables and radicals
+	{0xA960, 0xA97F},   // Hangul Jamo extended A
+	{0xAC00, 0xD7A3},   // Hangul syllables
+	{0xF900, 0xFAFF},   // CJK compatibility ideographs
+	{0xFE10, 0xFE19},   // Vertical forms
+	{0xFE30, 0xFE6F},   // CJK compatibility forms, small form variants
+	{0xFF00, 0xFF60},   // Fullwidth forms
+	{0xFFE0, 0xFFE6},   // Fullwidth signs
+	{0x16FE0, 0x16FE4}, // Ideographic symbols and punctuation
+	{0x17000, 0x18AFF}, // Tangut
+	{0x1B000, 0x1B2FF}, // Kana supplement and extensions, Nushu
+	{0x1F004, 0x1F004}, // Mahjong tile red dragon
+	{0x1F0CF, 0x1F0CF}, // Playing card black joker
+	{0x1F18E, 0x1F18E}, // Negative squared AB
+	{0x1F191, 0x1F19A}, // Squared words
+	{0x1F200, 0x1F2FF}, // Enclosed ideographic supplement
+	{0x1F300, 0x1F320}, // Weather and landscape pictographs
+	{0x1F32D, 0x1F335}, // Food pictographs
+	{0x1F337, 0x1F37C}, // Plant and food pictographs
+	{0x1F37E, 0x1F393}, // Celebration pictographs
+	{0x1F3A0, 0x1F3CA}, // Activity pictographs
+	{0x1F3CF, 0x1F3D3}, // Sport pictographs
+	{0x1F3E0, 0x1F3F0}, // Building pictographs
+	{0x1F3F4, 0x1F3F4}, // Black flag
+	{0x1F3F8, 0x1F43E}, // Sport and animal pictographs
+	{0x1F440, 0x1F440}, // Eyes
+	{0x1F442, 0x1F4FC}, // Body, people and object pictographs
+	{0x1F4FF, 0x1F53D}, // Object pictographs
+	{0x1F54B, 0x1F54E}, // Religious pictographs
+	{0x1F550, 0x1F567}, // Clock faces
+	{0x1F57A, 0x1F57A}, // Man dancing
+	{0x1F595, 0x1F596}, // Hand gestures
+	{0x1F5A4, 0x1F5A4}, // Black heart
+	{0x1F5FB, 0x1F64F}, // Landmarks and emoticons
+	{0x1F680, 0x1F6C5}, // Transport pictographs
+	{0x1F6CC, 0x1F6CC}, // Sleeping accommodation
+	{0x1F6D0, 0x1F6D2}, // Place of worship, shopping trolley
+	{0x1F6D5, 0x1F6D7}, // Hindu temple, hut, elevator
+	{0x1F6DC, 0x1F6DF}, // Wireless, playground slide, wheel, ring buoy
+	{0x1F6EB, 0x1F6EC}, // Airplane departure and arrival
+	{0x1F6F4, 0x1F6FC}, // Scooters and vehicles
+	{0x1F7E0, 0x1F7EB}, // Large coloured circles and squares
+	{0x1F7F0, 0x1F7F0}, // Heavy equals sign
+	{0x1F90C, 0x1F93A}, // Supplemental pictographs
+	{0x1F93C, 0x1F945}, // Supplemental pictographs
+	{0x1F947, 0x1F9FF}, // Supplemental pictographs
+	{0x1FA70, 0x1FAFF}, // Symbols and pictographs extended A
+	{0x20000, 0x2FFFD}, // CJK unified ideographs extensions B to F
+	{0x30000, 0x3FFFD}, // CJK unified ideographs extensions G and H
+}
+
+// RuneWidth returns the number of columns the character occupies in a terminal. Control characters, combining
+// marks and zero width characters occupy no column, East Asian wide characters and emoji occupy two columns.
+//
+// Parameters:
+//   - `character` : Character that is measured
+//
+// Returns:
+//   - `int` : Width in columns, 0, 1 or 2
+func RuneWidth(character rune) int {
+	if character < 0x20 || (character >= 0x7F && character < 0xA0) {
+		return 0
+	}
+	if character < 0x1100 && !unicode.In(character, unicode.Mn, unicode.Me) {
+		return 1
+	}
+	if unicode.In(character, unicode.Mn, unicode.Me, unicode.Cf) || character == 0x200B {
+		return 0
+	}
+	// Variation selectors only change the presentation of the preceding character
+	if (character >= 0xFE00 && character <= 0xFE0F) || (character >= 0xE0100 && character <= 0xE01EF) {
+		return 0
+	}
+	low, high := 0, len(wideRanges)-1
+	for low <= high {
+		middle := (low + high) / 2
+		switch {
+		case character < wideRanges[middle][0]:
+			high = middle - 1
+		case character > wideRanges[middle][1]:
+			low = middle + 1
+		default:
+			return 2
+		}
+	}
+	return 1
+}
+
+// Width returns the number of columns the text occupies in a terminal, escape sequences are ignored.
+//
+// Parameters:
+//   - `text` : Text that is measured, it might contain escape sequences
+//
+// Returns:
+//   - `int` : Width in columns
+func Width(text string) int {
+	width := 0
+	for _, character := range Strip(text) {
+		width += RuneWidth(character)
+	}
+	return width
+}
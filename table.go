@@ -0,0 +1,298 @@
+package cyclecmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/RaphSku/cyclecmd/style"
+	"golang.org/x/term"
+)
+
+// columnSeparator separates the columns of a table that is rendered for a terminal.
+const columnSeparator = "  "
+
+// Alignment defines how the cells of a column are aligned.
+type Alignment int
+
+const (
+	// ALIGN_LEFT aligns the cells at the left edge of the column
+	ALIGN_LEFT Alignment = iota
+	// ALIGN_RIGHT aligns the cells at the right edge of the column, e.g. for numbers
+	ALIGN_RIGHT
+	// ALIGN_CENTER centers the cells within the column
+	ALIGN_CENTER
+)
+
+// Table is a table with a header row whose columns are aligned to the widest cell. When the table is wider than
+// the terminal, the widest columns are shrunk and their cells are truncated with an ellipsis or wrapped. Widths
+// are measured in terminal columns, so East Asian wide characters and emoji are aligned correctly. When the
+// output is not a terminal, the table is written as tab-separated values instead.
+type Table struct {
+	headers    []string
+	alignments []Alignment
+	rows       [][]string
+	wrap       bool
+}
+
+// NewTable initialises a table with the headers, the number of headers defines the number of columns. All
+// columns are aligned to the left.
+//
+// Parameters:
+//   - `headers` : Headers of the columns
+//
+// Returns:
+//   - `*Table` : Returns an instance of Table
+func NewTable(headers ...string) *Table {
+	return &Table{
+		headers:    headers,
+		alignments: make([]Alignment, len(headers)),
+	}
+}
+
+// SetAlignment sets how the cells of a column, including its header, are aligned.
+//
+// Parameters:
+//   - `column` : Index of the column
+//   - `alignment` : Alignment of the column
+//
+// Returns:
+//   - `error` : Returns an error when the table has no column with the index
+func (t *Table) SetAlignment(column int, alignment Alignment) error {
+	if column < 0 || column >= len(t.headers) {
+		return fmt.Errorf("column %v does not exist, the table has %v columns", column, len(t.headers))
+	}
+	t.alignments[column] = alignment
+	return nil
+}
+
+// SetWrap sets whether cells that do not fit into their column are wrapped onto further lines instead of being
+// truncated with an ellipsis.
+//
+// Parameters:
+//   - `wrap` : True to wrap cells, false to truncate them
+func (t *Table) SetWrap(wrap bool) {
+	t.wrap = wrap
+}
+
+// AddRow appends a row to the table, missing cells at the end of the row are left empty.
+//
+// Parameters:
+//   - `cells` : Cells of the row
+//
+// Returns:
+//   - `error` : Returns an error when the row has more cells than the table has columns
+func (t *Table) AddRow(cells ...string) error {
+	if len(cells) > len(t.headers) {
+		return fmt.Errorf("row has %v cells, but the table has only %v columns", len(cells), len(t.headers))
+	}
+	row := make([]string, len(t.headers))
+	copy(row, cells)
+	t.rows = append(t.rows, row)
+	return nil
+}
+
+// Render renders the table for a terminal, every line ends with a carriage return and a newline.
+//
+// Parameters:
+//   - `width` : Width of the terminal in columns, the table is not shrunk if it is not positive
+//   - `theme` : Theme whose TableHeader style is used for the header row
+//   - `profile` : Colour profile that is used to render the styles
+//
+// Returns:
+//   - `string` : The rendered table
+func (t *Table) Render(width int, theme style.Theme, profile style.ColorProfile) string {
+	if len(t.headers) == 0 {
+		return ""
+	}
+	columnWidths := t.columnWidths(width)
+
+	var output strings.Builder
+	t.renderRow(&output, t.headers, columnWidths, func(text string) string {
+		return theme.TableHeader.Render(profile, text)
+	})
+	for _, row := range t.rows {
+		t.renderRow(&output, row, columnWidths, func(text string) string { return text })
+	}
+	return output.String()
+}
+
+// RenderTSV renders the table as tab-separated values, tabs and line breaks within cells are replaced by spaces
+// and every line ends with a newline.
+//
+// Returns:
+//   - `string` : The table as tab-separated values
+func (t *Table) RenderTSV() string {
+	if len(t.headers) == 0 {
+		return ""
+	}
+	var output strings.Builder
+	for _, row := range append([][]string{t.headers}, t.rows...) {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			cells[i] = cleanCell(cell)
+		}
+		output.WriteString(strings.Join(cells, "\t") + "\n")
+	}
+	return output.String()
+}
+
+// Write writes the table to the writer. If the writer is a terminal, the table is rendered for the width of the
+// terminal with the default theme, otherwise it is written as tab-separated values.
+//
+// Parameters:
+//   - `w` : Writer that receives the table
+//
+// Returns:
+//   - `error` : Returns an error when the table could not be written
+func (t *Table) Write(w io.Writer) error {
+	output := t.RenderTSV()
+	if file, ok := w.(*os.File); ok && term.IsTerminal(int(file.Fd())) {
+		width, _, err := term.GetSize(int(file.Fd()))
+		if err != nil {
+			width = defaultTerminalWidth
+		}
+		output = t.Render(width, style.DefaultTheme(), style.DetectColorProfile(w))
+	}
+	if _, err := io.WriteString(w, output); err != nil {
+		return fmt.Errorf("table could not be written! error: %w", err)
+	}
+	return nil
+}
+
+// PrintTable prints the table to the output of the console application. If the output is a terminal, the table
+// is rendered for the current width of the terminal with the theme of the console application, otherwise it is
+// printed as tab-separated values.
+//
+// Parameters:
+//   - `table` : Table that is printed
+//
+// Returns:
+//   - `error` : Returns an error when the table could not be printed
+func (ca *ConsoleApp) PrintTable(table *Table) error {
+	output := table.RenderTSV()
+	if ca.outputIsTerminal() {
+		width, _ := ca.Size()
+		output = table.Render(width, ca.theme, ca.ColorProfile())
+	}
+	if _, err := io.WriteString(ca.Output(), output); err != nil {
+		return fmt.Errorf("table could not be printed! error: %w", err)
+	}
+	return nil
+}
+
+// outputIsTerminal checks whether the output of the console application is a terminal.
+//
+// Returns:
+//   - `bool` : True if the output is a file that is a terminal
+func (ca *ConsoleApp) outputIsTerminal() bool {
+	file, ok := ca.Output().(*os.File)
+	return ok && term.IsTerminal(int(file.Fd()))
+}
+
+// columnWidths calculates the width of every column. The columns are as wide as their widest cell, if the table
+// is wider than the available width, the widest column is shrunk until the table fits.
+//
+// Parameters:
+//   - `width` : Available width in columns, the columns are not shrunk if it is not positive
+//
+// Returns:
+//   - `[]int` : Width of every column
+func (t *Table) columnWidths(width int) []int {
+	columnWidths := make([]int, len(t.headers))
+	for _, row := range append([][]string{t.headers}, t.rows...) {
+		for i, cell := range row {
+			columnWidths[i] = max(columnWidths[i], displayWidth(cleanCell(cell)))
+		}
+	}
+	if width <= 0 {
+		return columnWidths
+	}
+
+	available := width - len(columnSeparator)*(len(columnWidths)-1)
+	total := 0
+	for _, columnWidth := range columnWidths {
+		total += columnWidth
+	}
+	for total > available {
+		widest := 0
+		for i, columnWidth := range columnWidths {
+			if columnWidth > columnWidths[widest] {
+				widest = i
+			}
+		}
+		// Every column keeps at least one column, so the table might still be wider than the terminal
+		if columnWidths[widest] <= 1 {
+			break
+		}
+		columnWidths[widest] -= 1
+		total -= 1
+	}
+	return columnWidths
+}
+
+// renderRow renders a row whose cells are truncated or wrapped to the widths of their columns.
+//
+// Parameters:
+//   - `output` : Builder the row is written to
+//   - `row` : Cells of the row
+//   - `columnWidths` : Width of every column
+//   - `styleText` : Styles the text of the cells, the padding is not styled
+func (t *Table) renderRow(output *strings.Builder, row []string, columnWidths []int, styleText func(text string) string) {
+	cellLines := make([][]string, len(row))
+	height := 1
+	for i, cell := range row {
+		cell = cleanCell(cell)
+		if t.wrap {
+			cellLines[i] = wrapText(cell, columnWidths[i])
+		} else {
+			cellLines[i] = []string{truncateText(cell, columnWidths[i])}
+		}
+		height = max(height, len(cellLines[i]))
+	}
+
+	for lineIndex := 0; lineIndex < height; lineIndex++ {
+		var line strings.Builder
+		for i, lines := range cellLines {
+			if i > 0 {
+				line.WriteString(columnSeparator)
+			}
+			text := ""
+			if lineIndex < len(lines) {
+				text = lines[lineIndex]
+			}
+			padding := max(0, columnWidths[i]-displayWidth(text))
+			left := 0
+			switch t.alignments[i] {
+			case ALIGN_RIGHT:
+				left = padding
+			case ALIGN_CENTER:
+				left = padding / 2
+			}
+			line.WriteString(strings.Repeat(" ", left))
+			if text != "" {
+				line.WriteString(styleText(text))
+			}
+			line.WriteString(strings.Repeat(" ", padding-left))
+		}
+		output.WriteString(strings.TrimRight(line.String(), " ") + "\r\n")
+	}
+}
+
+// cleanCell removes escape sequences from the cell and replaces tabs and line breaks by spaces, so that the
+// width of the cell can be measured.
+//
+// Parameters:
+//   - `cell` : Content of the cell
+//
+// Returns:
+//   - `string` : Content of the cell on a single line
+func cleanCell(cell string) string {
+	return strings.Map(func(character rune) rune {
+		if character == '\t' || character == '\n' || character == '\r' {
+			return ' '
+		}
+		return character
+	}, style.Strip(cell))
+}
@@ -0,0 +1,101 @@
+//go:build unit_test
+
+package cyclecmd_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/RaphSku/cyclecmd"
+	"github.com/RaphSku/cyclecmd/style"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupTable() *cyclecmd.Table {
+	table := cyclecmd.NewTable("Name", "Count", "Description")
+	table.AddRow("apple", "3", "A red fruit")
+	table.AddRow("日本", "12", "Two wide characters")
+	table.AddRow("🚀", "100")
+	return table
+}
+
+func TestTableRendering(t *testing.T) {
+	t.Parallel()
+
+	table := setupTable()
+	err := table.SetAlignment(1, cyclecmd.ALIGN_RIGHT)
+	assert.NoError(t, err)
+
+	expOutput := "Name   Count  Description\r\n" +
+		"apple      3  A red fruit\r\n" +
+		"日本      12  Two wide characters\r\n" +
+		"🚀       100\r\n"
+	assert.Equal(t, expOutput, table.Render(0, style.PlainTheme(), style.PROFILE_ANSI16))
+
+	// The widest column is shrunk until the table fits into the width
+	expOutput = "Name   Count  Descripti…\r\n" +
+		"apple      3  A red fru…\r\n" +
+		"日本      12  Two wide …\r\n" +
+		"🚀       100\r\n"
+	assert.Equal(t, expOutput, table.Render(24, style.PlainTheme(), style.PROFILE_ANSI16))
+
+	table.SetWrap(true)
+	expOutput = "Name   Count  Descriptio\r\n" +
+		"              n\r\n" +
+		"apple      3  A red\r\n" +
+		"              fruit\r\n" +
+		"日本      12  Two wide\r\n" +
+		"              characters\r\n" +
+		"🚀       100\r\n"
+	assert.Equal(t, expOutput, table.Render(24, style.PlainTheme(), style.PROFILE_ANSI16))
+
+	header := style.New().WithBold().Render(style.PROFILE_ANSI16, "Name")
+	assert.Contains(t, table.Render(0, style.DefaultTheme(), style.PROFILE_ANSI16), header)
+
+	centered := cyclecmd.NewTable("Centered")
+	centered.SetAlignment(0, cyclecmd.ALIGN_CENTER)
+	centered.AddRow("ab")
+	assert.Equal(t, "Centered\r\n   ab\r\n", centered.Render(0, style.PlainTheme(), style.PROFILE_NO_COLOR))
+}
+
+func TestTableTSV(t *testing.T) {
+	t.Parallel()
+
+	table := setupTable()
+	table.AddRow("multi\nline", "1", "tab\tseparated")
+	expOutput := "Name\tCount\tDescription\n" +
+		"apple\t3\tA red fruit\n" +
+		"日本\t12\tTwo wide characters\n" +
+		"🚀\t100\t\n" +
+		"multi line\t1\ttab separated\n"
+	assert.Equal(t, expOutput, table.RenderTSV())
+
+	// The output is not a terminal, so the table is printed as tab-separated values
+	eventRegistry := cyclecmd.NewEventRegistry(setupDefaultEventInformation())
+	consoleApp := cyclecmd.NewConsoleApp("test", "0.1.0", "This is a test console application", eventRegistry, cyclecmd.NewEventHistory())
+	output := &bytes.Buffer{}
+	consoleApp.SetOutput(output)
+	err := consoleApp.PrintTable(table)
+	assert.NoError(t, err)
+	assert.Equal(t, expOutput, output.String())
+
+	output.Reset()
+	err = table.Write(output)
+	assert.NoError(t, err)
+	assert.Equal(t, expOutput, output.String())
+}
+
+func TestTableErrors(t *testing.T) {
+	t.Parallel()
+
+	table := cyclecmd.NewTable("A", "B")
+	err := table.AddRow("1", "2", "3")
+	if assert.Error(t, err) {
+		assert.Equal(t, fmt.Errorf("row has %v cells, but the table has only %v columns", 3, 2), err)
+	}
+	err = table.SetAlignment(2, cyclecmd.ALIGN_RIGHT)
+	if assert.Error(t, err) {
+		assert.Equal(t, fmt.Errorf("column %v does not exist, the table has %v columns", 2, 2), err)
+	}
+}
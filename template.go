@@ -0,0 +1,224 @@
+package cyclecmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// DEFAULT_BANNER_TEMPLATE is the template of the welcome banner that is printed when the event loop starts.
+const DEFAULT_BANNER_TEMPLATE string = "Welcome to {{.Name}}! Version: {{.Version}}\r\n{{.Description}}\r"
+
+// TemplateData is passed to the banner and prompt templates.
+type TemplateData struct {
+	// Name of the console application
+	Name string
+	// Version of the console application
+	Version string
+	// Description of the console application
+	Description string
+	// Mode is the current mode of the console application, see ConsoleApp.SetMode
+	Mode string
+	// LastExitStatus is the exit status of the last executed command, 0 if the command succeeded
+	LastExitStatus int
+	// Fields contains custom fields, see ConsoleApp.SetTemplateField
+	Fields map[string]any
+}
+
+// templateFuncs are available in the banner and prompt templates:
+//   - `cwd` : Returns the current working directory
+//   - `gitBranch` : Returns the git branch of the current working directory, empty if there is none
+//   - `now` : Returns the current time, e.g. {{ now.Format "15:04" }}
+var templateFuncs = template.FuncMap{
+	"cwd": func() string {
+		workingDirectory, err := os.Getwd()
+		if err != nil {
+			return ""
+		}
+		return workingDirectory
+	},
+	"gitBranch": gitBranch,
+	"now":       time.Now,
+}
+
+// gitBranch returns the checked out git branch by looking for the HEAD file of a git repository in the
+// current working directory and all of its parents.
+//
+// Returns:
+//   - `string` : Name of the branch, the short commit hash for a detached HEAD or empty if there is no repository
+func gitBranch() string {
+	directory, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	for {
+		head, err := os.ReadFile(filepath.Join(directory, ".git", "HEAD"))
+		if err == nil {
+			reference := strings.TrimSpace(string(head))
+			if branch, ok := strings.CutPrefix(reference, "ref: refs/heads/"); ok {
+				return branch
+			}
+			return reference[:min(7, len(reference))]
+		}
+		parent := filepath.Dir(directory)
+		if parent == directory {
+			return ""
+		}
+		directory = parent
+	}
+}
+
+// SetBannerTemplate replaces the welcome banner with a text/template, see TemplateData for the available data.
+//
+// Parameters:
+//   - `bannerTemplate` : Template of the banner
+//
+// Returns:
+//   - `error` : Returns an error when the template could not be parsed
+func (ca *ConsoleApp) SetBannerTemplate(bannerTemplate string) error {
+	parsedTemplate, err := template.New("banner").Funcs(templateFuncs).Parse(bannerTemplate)
+	if err != nil {
+		return fmt.Errorf("banner template could not be parsed! error: %w", err)
+	}
+	ca.bannerTemplate = parsedTemplate
+	ca.bannerDisabled = false
+	return nil
+}
+
+// DisableBanner turns off the welcome banner.
+func (ca *ConsoleApp) DisableBanner() {
+	ca.bannerDisabled = true
+}
+
+// SetPromptTemplate sets a text/template that is evaluated every time the delimiter is printed, the prompt is
+// printed right after the delimiter. See TemplateData for the available data.
+//
+// Parameters:
+//   - `promptTemplate` : Template of the prompt
+//
+// Returns:
+//   - `error` : Returns an error when the template could not be parsed
+func (ca *ConsoleApp) SetPromptTemplate(promptTemplate string) error {
+	parsedTemplate, err := template.New("prompt").Funcs(templateFuncs).Parse(promptTemplate)
+	if err != nil {
+		return fmt.Errorf("prompt template could not be parsed! error: %w", err)
+	}
+	ca.promptFunc = func() string {
+		prompt, err := ca.executeTemplate(parsedTemplate)
+		if err != nil {
+			ca.logger.Debug("Prompt template could not be executed", zap.Error(err), zap.String("func", "SetPromptTemplate"))
+			ca.runErrorHooks(err)
+		}
+		return prompt
+	}
+	return nil
+}
+
+// SetPromptFunc sets a function that is called every time the delimiter is printed, the returned prompt is
+// printed right after the delimiter.
+//
+// Parameters:
+//   - `promptFunc` : Function that returns the prompt
+func (ca *ConsoleApp) SetPromptFunc(promptFunc func() string) {
+	ca.promptFunc = promptFunc
+}
+
+// SetTemplateField sets a custom field that is available in the banner and prompt templates via .Fields.
+//
+// Parameters:
+//   - `name` : Name of the field
+//   - `value` : Value of the field
+func (ca *ConsoleApp) SetTemplateField(name string, value any) {
+	if ca.templateFields == nil {
+		ca.templateFields = make(map[string]any)
+	}
+	ca.templateFields[name] = value
+}
+
+// SetMode sets the current mode of the console application, it is available in the templates via .Mode.
+//
+// Parameters:
+//   - `mode` : Name of the mode
+func (ca *ConsoleApp) SetMode(mode string) {
+	ca.mode = mode
+}
+
+// Mode returns the current mode of the console application.
+//
+// Returns:
+//   - `string` : Name of the mode
+func (ca *ConsoleApp) Mode() string {
+	return ca.mode
+}
+
+// LastExitStatus returns the exit status of the last command that was executed by a command registry.
+//
+// Returns:
+//   - `int` : 0 if the last command succeeded, 1 otherwise
+func (ca *ConsoleApp) LastExitStatus() int {
+	return ca.lastExitStatus
+}
+
+// templateData collects the data that is passed to the templates.
+//
+// Returns:
+//   - `TemplateData` : Data for the templates
+func (ca *ConsoleApp) templateData() TemplateData {
+	return TemplateData{
+		Name:           ca.Name,
+		Version:        ca.Version,
+		Description:    ca.Description,
+		Mode:           ca.mode,
+		LastExitStatus: ca.lastExitStatus,
+		Fields:         ca.templateFields,
+	}
+}
+
+// executeTemplate executes the template with the template data.
+//
+// Parameters:
+//   - `parsedTemplate` : Template that is executed
+//
+// Returns:
+//   - `string` : Result of the template
+//   - `error` : Returns an error when the template could not be executed
+func (ca *ConsoleApp) executeTemplate(parsedTemplate *template.Template) (string, error) {
+	var result bytes.Buffer
+	if err := parsedTemplate.Execute(&result, ca.templateData()); err != nil {
+		return "", err
+	}
+	return result.String(), nil
+}
+
+// printBanner prints the welcome banner unless it is disabled.
+func (ca *ConsoleApp) printBanner() {
+	if ca.bannerDisabled {
+		return
+	}
+	bannerTemplate := ca.bannerTemplate
+	if bannerTemplate == nil {
+		bannerTemplate = template.Must(template.New("banner").Parse(DEFAULT_BANNER_TEMPLATE))
+	}
+	banner, err := ca.executeTemplate(bannerTemplate)
+	if err != nil {
+		ca.logger.Debug("Banner template could not be executed", zap.Error(err), zap.String("func", "printBanner"))
+		ca.runErrorHooks(err)
+		return
+	}
+	fmt.Fprint(ca.Output(), banner)
+}
+
+// printDelimiter prints the delimiter followed by the prompt.
+func (ca *ConsoleApp) printDelimiter() {
+	if ca.batch.active {
+		return
+	}
+	lineBreaks := ca.Delimiter[:len(ca.Delimiter)-len(strings.TrimLeft(ca.Delimiter, "\r\n"))]
+	fmt.Fprint(ca.Output(), lineBreaks+ca.prompt())
+}
@@ -0,0 +1,74 @@
+//go:build unit_test
+
+package cyclecmd_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/RaphSku/cyclecmd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBannerTemplate(t *testing.T) {
+	t.Parallel()
+
+	output := &bytes.Buffer{}
+	consoleApp := setupOutputConsoleApp(output)
+	consoleApp.SetInput(newTokenReader())
+	err := consoleApp.SetBannerTemplate("{{.Name}} {{.Version}} by {{.Fields.author}}\r\n")
+	assert.NoError(t, err)
+	consoleApp.SetTemplateField("author", "jane")
+	consoleApp.Start()
+	assert.Equal(t, "test 0.1.0 by jane\r\n", output.String())
+
+	output.Reset()
+	consoleApp.DisableBanner()
+	consoleApp.Start()
+	assert.Equal(t, "", output.String())
+
+	err = consoleApp.SetBannerTemplate("{{.Name")
+	assert.Error(t, err)
+}
+
+func TestDynamicPrompt(t *testing.T) {
+	t.Parallel()
+
+	output := &bytes.Buffer{}
+	eventRegistry := cyclecmd.NewEventRegistry(setupDefaultEventInformation())
+	consoleApp := cyclecmd.NewConsoleApp("test", "0.1.0", "This is a test console application", eventRegistry, cyclecmd.NewEventHistory())
+	consoleApp.SetInput(newTokenReader(cyclecmd.KEY_ENTER, "n", cyclecmd.KEY_ENTER))
+	consoleApp.SetOutput(output)
+	consoleApp.DisableBanner()
+
+	commandRegistry := cyclecmd.NewCommandRegistry(consoleApp)
+	err := commandRegistry.RegisterCommand(cyclecmd.Command{
+		Name: "n",
+		Run: func(args []string) (error, *cyclecmd.ControlEvent) {
+			consoleApp.SetMode("normal")
+			return fmt.Errorf("failed"), nil
+		},
+	})
+	assert.NoError(t, err)
+	lineEditor := cyclecmd.NewLineEditor(consoleApp, commandRegistry.Submit)
+	err = lineEditor.RegisterEvents(eventRegistry)
+	assert.NoError(t, err)
+	consoleApp.SetLineDelimiter("", cyclecmd.KEY_ENTER)
+	err = consoleApp.SetPromptTemplate("[{{.Mode}}:{{.LastExitStatus}}]> ")
+	assert.NoError(t, err)
+	consoleApp.SetMode("insert")
+
+	consoleApp.Start()
+	assert.Equal(t, "[insert:0]> \r\n[insert:0]> n\r\nerror: failed\r\n[normal:1]> ", output.String())
+
+	output.Reset()
+	counter := 0
+	consoleApp.SetPromptFunc(func() string {
+		counter += 1
+		return fmt.Sprintf("%d> ", counter)
+	})
+	consoleApp.SetInput(newTokenReader(cyclecmd.KEY_ENTER))
+	consoleApp.Start()
+	assert.Equal(t, "1> \r\n2> ", output.String())
+}
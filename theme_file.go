@@ -0,0 +1,157 @@
+package cyclecmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/RaphSku/cyclecmd/style"
+)
+
+// themeFile is the content of a theme file. In a YAML file:
+//
+//	name: solarized
+//	base: default
+//	styles:
+//	  prompt:
+//	    fg: "#859900"
+//	    bold: true
+//	  status_line:
+//	    fg: black
+//	    bg: bright-cyan
+type themeFile struct {
+	// Name of the theme, the name of the base theme is kept if it is empty
+	Name string `yaml:"name" json:"name" toml:"name"`
+	// Base is the name of the predefined theme whose styles are used for all styles the file does not set
+	Base string `yaml:"base" json:"base" toml:"base"`
+	// Styles maps the names of the styles of the theme to their definitions
+	Styles map[string]styleFile `yaml:"styles" json:"styles" toml:"styles"`
+}
+
+// styleFile is the definition of a style in a theme file, colours are parsed by style.ParseColor.
+type styleFile struct {
+	Foreground string `yaml:"fg" json:"fg" toml:"fg"`
+	Background string `yaml:"bg" json:"bg" toml:"bg"`
+	Bold       bool   `yaml:"bold" json:"bold" toml:"bold"`
+	Faint      bool   `yaml:"faint" json:"faint" toml:"faint"`
+	Italic     bool   `yaml:"italic" json:"italic" toml:"italic"`
+	Underline  bool   `yaml:"underline" json:"underline" toml:"underline"`
+	Reverse    bool   `yaml:"reverse" json:"reverse" toml:"reverse"`
+}
+
+// LoadTheme reads a theme from a YAML, JSON or TOML file, the format is determined by the extension. The file
+// names a predefined theme as its base, see style.ThemeByName, and overrides the styles `prompt`, `error`,
+// `help_key`, `help_description`, `status_line`, `selection`, `table_header` and `progress`.
+//
+// Parameters:
+//   - `path` : Path of the theme file
+//
+// Returns:
+//   - `style.Theme` : The theme of the file
+//   - `error` : Returns an error when the file could not be read or is invalid
+func LoadTheme(path string) (style.Theme, error) {
+	format, err := ConfigFormatOf(path)
+	if err != nil {
+		return style.Theme{}, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return style.Theme{}, fmt.Errorf("theme file could not be read! error: %w", err)
+	}
+	theme, err := ParseTheme(data, format)
+	if err != nil {
+		return style.Theme{}, fmt.Errorf("theme file %v is invalid! error: %w", path, err)
+	}
+	return theme, nil
+}
+
+// ParseTheme parses a theme in the format, see LoadTheme.
+//
+// Parameters:
+//   - `data` : Content of a theme file
+//   - `format` : Format of the content
+//
+// Returns:
+//   - `style.Theme` : The theme
+//   - `error` : Returns an error when the content is invalid
+func ParseTheme(data []byte, format ConfigFormat) (style.Theme, error) {
+	var file themeFile
+	if err := decodeConfig(data, format, &file); err != nil {
+		return style.Theme{}, err
+	}
+
+	theme := style.DefaultTheme()
+	if file.Base != "" {
+		var ok bool
+		if theme, ok = style.ThemeByName(file.Base); !ok {
+			return style.Theme{}, fmt.Errorf("base theme %v is unknown", file.Base)
+		}
+	}
+	if file.Name != "" {
+		theme.Name = file.Name
+	}
+	styles := themeStyles(&theme)
+	var errs []error
+	for _, name := range sortedKeys(file.Styles) {
+		themeStyle, ok := styles[name]
+		if !ok {
+			errs = append(errs, fmt.Errorf("style %v is unknown", name))
+			continue
+		}
+		parsedStyle, err := file.Styles[name].parse()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("style %v is invalid! error: %w", name, err))
+			continue
+		}
+		*themeStyle = parsedStyle
+	}
+	if len(errs) > 0 {
+		return style.Theme{}, errors.Join(errs...)
+	}
+	return theme, nil
+}
+
+// parse converts the definition of the style into a style.
+//
+// Returns:
+//   - `style.Style` : The style
+//   - `error` : Returns an error when a colour is invalid
+func (sf styleFile) parse() (style.Style, error) {
+	foreground, err := style.ParseColor(sf.Foreground)
+	if err != nil {
+		return style.Style{}, err
+	}
+	background, err := style.ParseColor(sf.Background)
+	if err != nil {
+		return style.Style{}, err
+	}
+	return style.Style{
+		Foreground: foreground,
+		Background: background,
+		Bold:       sf.Bold,
+		Faint:      sf.Faint,
+		Italic:     sf.Italic,
+		Underline:  sf.Underline,
+		Reverse:    sf.Reverse,
+	}, nil
+}
+
+// themeStyles maps the names of the styles in theme files to the styles of the theme.
+//
+// Parameters:
+//   - `theme` : Theme whose styles are mapped
+//
+// Returns:
+//   - `map[string]*style.Style` : Styles of the theme by their name
+func themeStyles(theme *style.Theme) map[string]*style.Style {
+	return map[string]*style.Style{
+		"prompt":           &theme.Prompt,
+		"error":            &theme.Error,
+		"help_key":         &theme.HelpKey,
+		"help_description": &theme.HelpDescription,
+		"status_line":      &theme.StatusLine,
+		"selection":        &theme.Selection,
+		"table_header":     &theme.TableHeader,
+		"progress":         &theme.Progress,
+	}
+}
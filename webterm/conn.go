@@ -0,0 +1,140 @@
+package webterm
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// controlMessage is a JSON control message the browser sends as text message.
+type controlMessage struct {
+	// Type of the control message, e.g. CONTROL_RESIZE
+	Type string `json:"type"`
+	// Cols and Rows are the size of the terminal of a resize message
+	Cols int `json:"cols"`
+	Rows int `json:"rows"`
+}
+
+// terminalConn is the WebSocket connection of a session as the console application sees it.
+type terminalConn struct {
+	conn *websocket.Conn
+	// resize is called with the size of the terminal the browser reported
+	resize func(width int, height int)
+	// errorLog logs the invalid control messages, the standard logger is used if it is nil
+	errorLog *log.Logger
+	// message is the binary message that is read, might be nil
+	message io.Reader
+	// writeMutex serializes the writes, a connection supports only one concurrent writer
+	writeMutex sync.Mutex
+}
+
+// Read reads the input of the browser, it implements io.Reader. Control messages are handled while reading.
+//
+// Parameters:
+//   - `p` : Buffer the input is read into
+//
+// Returns:
+//   - `int` : Number of bytes that were read
+//   - `error` : io.EOF once the connection was closed
+func (tc *terminalConn) Read(p []byte) (int, error) {
+	for {
+		if tc.message != nil {
+			n, err := tc.message.Read(p)
+			if err == io.EOF {
+				tc.message = nil
+				err = nil
+			}
+			if n > 0 || err != nil {
+				return n, err
+			}
+			continue
+		}
+
+		messageType, message, err := tc.conn.NextReader()
+		if err != nil {
+			if isClosedError(err) {
+				return 0, io.EOF
+			}
+			return 0, err
+		}
+		switch messageType {
+		case websocket.BinaryMessage:
+			tc.message = message
+		case websocket.TextMessage:
+			tc.handleControlMessage(message)
+		}
+	}
+}
+
+// handleControlMessage handles a control message, messages of unknown types are ignored. Invalid control messages
+// are logged and ignored, so that a faulty message does not end the session.
+//
+// Parameters:
+//   - `message` : Text message that contains the control message
+func (tc *terminalConn) handleControlMessage(message io.Reader) {
+	var control controlMessage
+	if err := json.NewDecoder(message).Decode(&control); err != nil {
+		tc.logf("webterm: control message is invalid and ignored! error: %v", err)
+		return
+	}
+	if control.Type == CONTROL_RESIZE {
+		tc.resize(control.Cols, control.Rows)
+	}
+}
+
+// logf logs the message with the error log, the standard logger is used if it is not set.
+//
+// Parameters:
+//   - `format` : Format of the message
+//   - `args` : Arguments of the format
+func (tc *terminalConn) logf(format string, args ...any) {
+	if tc.errorLog != nil {
+		tc.errorLog.Printf(format, args...)
+		return
+	}
+	log.Printf(format, args...)
+}
+
+// Write sends the output of the console application as binary message, it implements io.Writer.
+//
+// Parameters:
+//   - `p` : Output of the console application
+//
+// Returns:
+//   - `int` : Number of bytes that were sent
+//   - `error` : Returns an error when the connection failed
+func (tc *terminalConn) Write(p []byte) (int, error) {
+	tc.writeMutex.Lock()
+	defer tc.writeMutex.Unlock()
+	if err := tc.conn.WriteMessage(websocket.BinaryMessage, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// close tells the browser that the console application concluded and closes the connection.
+func (tc *terminalConn) close() {
+	tc.writeMutex.Lock()
+	defer tc.writeMutex.Unlock()
+	closeMessage := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "console application concluded")
+	tc.conn.WriteControl(websocket.CloseMessage, closeMessage, time.Now().Add(closeTimeout))
+	tc.conn.Close()
+}
+
+// isClosedError returns whether the error reports that the connection was closed.
+//
+// Parameters:
+//   - `err` : Error of a read
+//
+// Returns:
+//   - `bool` : True if the browser or the handler closed the connection
+func isClosedError(err error) bool {
+	var closeError *websocket.CloseError
+	return errors.As(err, &closeError) || errors.Is(err, net.ErrClosed)
+}
@@ -0,0 +1,197 @@
+// Package webterm bridges console applications built with cyclecmd to terminals in the browser, e.g. xterm.js. The
+// Handler upgrades requests to WebSocket connections and runs a console application per connection. Binary
+// messages of the browser are the input of the console application and its output is sent as binary messages.
+// Text messages are JSON control messages, the browser reports the size of the terminal with a resize message:
+//
+//	const socket = new WebSocket("wss://example.com/terminal")
+//	socket.binaryType = "arraybuffer"
+//	socket.onopen = () => socket.send(JSON.stringify({type: "resize", cols: terminal.cols, rows: terminal.rows}))
+//	socket.onmessage = (event) => terminal.write(new Uint8Array(event.data))
+//	terminal.onData((data) => socket.send(new TextEncoder().encode(data)))
+//	terminal.onResize(({cols, rows}) => socket.send(JSON.stringify({type: "resize", cols, rows})))
+package webterm
+
+import (
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/RaphSku/cyclecmd"
+	"github.com/RaphSku/cyclecmd/style"
+	"github.com/gorilla/websocket"
+)
+
+// CONTROL_RESIZE is the type of the control message that reports the size of the terminal, e.g.
+// {"type": "resize", "cols": 80, "rows": 24}.
+const CONTROL_RESIZE = "resize"
+
+// maxMessageSize limits the size of the messages the browser sends, a message carries a few key presses or a
+// pasted text.
+const maxMessageSize = 64 * 1024
+
+// closeTimeout is the time the close message may take to be sent once the console application concluded.
+const closeTimeout = time.Second
+
+// AppFactory creates a new console application for every connection, see cyclecmd.ConsoleApp.AttachRemoteTerminal.
+// The input, the output, the error output and the colour profile of the console application are set by the handler.
+//
+// Parameters:
+//   - `session` : Session the console application is created for
+//
+// Returns:
+//   - `*cyclecmd.ConsoleApp` : Console application of the session
+//   - `error` : Rejects the connection before it is upgraded, the request is answered with 403 Forbidden
+type AppFactory func(session Session) (*cyclecmd.ConsoleApp, error)
+
+// Session describes the connection a console application is created for.
+type Session struct {
+	// Request is the request that is upgraded, e.g. to authenticate the user by a cookie
+	Request *http.Request
+}
+
+// Handler is an http.Handler that upgrades requests to WebSocket connections and runs a console application per
+// connection. The connections are not closed by http.Server.Shutdown, since they are hijacked, see Close.
+//
+// Unlike the servers of sshserver and netserver, the handler does not track its connections with the acceptor of
+// internal/serve. The http.Server accepts the connections and owns the listeners, the handler only sees the
+// requests it upgrades, and the console application of a request is created before the upgrade, so that a
+// rejected request is answered with an HTTP status.
+type Handler struct {
+	newConsoleApp AppFactory
+	upgrader      websocket.Upgrader
+	// errorLog logs the invalid control messages of the browsers, the standard logger is used if it is nil
+	errorLog *log.Logger
+
+	// mutex guards connections and closed
+	mutex       sync.Mutex
+	connections map[*websocket.Conn]struct{}
+	closed      bool
+	// sessions waits for the console applications of the connections
+	sessions sync.WaitGroup
+}
+
+// NewHandler initialises a handler that creates the console applications with the factory. By default, only
+// requests whose Origin header matches the host are upgraded, see SetCheckOrigin.
+//
+// Parameters:
+//   - `newConsoleApp` : Creates the console application of every connection
+//
+// Returns:
+//   - `*Handler` : Returns an instance of Handler
+func NewHandler(newConsoleApp AppFactory) *Handler {
+	return &Handler{
+		newConsoleApp: newConsoleApp,
+		connections:   make(map[*websocket.Conn]struct{}),
+	}
+}
+
+// SetCheckOrigin sets the function that decides whether a request from a cross-origin page is upgraded. It has to
+// be set before the handler serves requests.
+//
+// Parameters:
+//   - `checkOrigin` : Returns true if the request may be upgraded, nil restores the same-origin check
+func (h *Handler) SetCheckOrigin(checkOrigin func(r *http.Request) bool) {
+	h.upgrader.CheckOrigin = checkOrigin
+}
+
+// SetErrorLog sets the logger for invalid control messages, which are ignored otherwise. The standard logger of
+// the log package is used by default. It has to be set before the handler serves requests.
+//
+// Parameters:
+//   - `errorLog` : Logger for invalid control messages, nil restores the standard logger
+func (h *Handler) SetErrorLog(errorLog *log.Logger) {
+	h.errorLog = errorLog
+}
+
+// ServeHTTP upgrades the request to a WebSocket connection and runs the console application of the connection
+// until it concluded or the browser closed the connection, it implements http.Handler.
+//
+// Parameters:
+//   - `w` : Writer of the response
+//   - `r` : Request that is upgraded
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.isClosed() {
+		http.Error(w, "terminal is shut down", http.StatusServiceUnavailable)
+		return
+	}
+	consoleApp, err := h.newConsoleApp(Session{Request: r})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// The upgrader answered the request already
+		return
+	}
+	if !h.track(conn) {
+		conn.Close()
+		return
+	}
+	defer h.untrack(conn)
+	conn.SetReadLimit(maxMessageSize)
+
+	terminalConn := &terminalConn{conn: conn, resize: consoleApp.Resize, errorLog: h.errorLog}
+	consoleApp.AttachRemoteTerminal(terminalConn, terminalConn)
+	consoleApp.SetColorProfile(style.PROFILE_TRUECOLOR)
+	consoleApp.Start()
+
+	terminalConn.close()
+}
+
+// Close closes all connections and waits until their console applications concluded, further requests are
+// answered with 503 Service Unavailable.
+//
+// Returns:
+//   - `error` : Always nil
+func (h *Handler) Close() error {
+	h.mutex.Lock()
+	h.closed = true
+	for conn := range h.connections {
+		conn.Close()
+	}
+	h.mutex.Unlock()
+
+	h.sessions.Wait()
+	return nil
+}
+
+// track adds the connection to the tracked connections unless the handler was closed.
+//
+// Parameters:
+//   - `conn` : Upgraded connection
+//
+// Returns:
+//   - `bool` : False if the handler was closed
+func (h *Handler) track(conn *websocket.Conn) bool {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if h.closed {
+		return false
+	}
+	h.connections[conn] = struct{}{}
+	h.sessions.Add(1)
+	return true
+}
+
+// untrack removes the connection from the tracked connections once its console application concluded.
+//
+// Parameters:
+//   - `conn` : Upgraded connection
+func (h *Handler) untrack(conn *websocket.Conn) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	delete(h.connections, conn)
+	h.sessions.Done()
+}
+
+// isClosed returns whether the handler was closed.
+//
+// Returns:
+//   - `bool` : True if the handler was closed
+func (h *Handler) isClosed() bool {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return h.closed
+}
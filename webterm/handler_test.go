@@ -0,0 +1,189 @@
+//go:build unit_test
+
+package webterm_test
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/RaphSku/cyclecmd"
+	"github.com/RaphSku/cyclecmd/webterm"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type DefaultEvent struct{}
+
+func (de *DefaultEvent) Handle(token string) (error, *cyclecmd.ControlEvent) {
+	return nil, nil
+}
+
+// newConsoleApp creates a console application that prints every submitted line together with the number of
+// submitted lines and concludes on "exit", the size of the terminal is printed on every resize.
+func newConsoleApp(session webterm.Session) (*cyclecmd.ConsoleApp, error) {
+	if session.Request.URL.Query().Get("token") != "secret" {
+		return nil, fmt.Errorf("token is invalid")
+	}
+	eventRegistry := cyclecmd.NewEventRegistry(cyclecmd.EventInformation{EventName: "Default", Event: &DefaultEvent{}})
+	consoleApp := cyclecmd.NewConsoleApp("test", "0.1.0", "This is a test console application", eventRegistry, cyclecmd.NewEventHistory())
+	consoleApp.DisableBanner()
+	lineEditor := cyclecmd.NewLineEditor(consoleApp, func(line string) (error, *cyclecmd.ControlEvent) {
+		if line == "exit" {
+			return nil, &cyclecmd.ControlEvent{Terminate: true}
+		}
+		fmt.Fprintf(consoleApp.Output(), "\r\nline: [%v] %v\r\n", line, len(consoleApp.EventHistory().SubmittedLines()))
+		return nil, nil
+	})
+	if err := lineEditor.RegisterEvents(eventRegistry); err != nil {
+		return nil, err
+	}
+	consoleApp.OnResize(func(width int, height int) {
+		fmt.Fprintf(consoleApp.Output(), "size: %dx%d\r\n", width, height)
+	})
+	return consoleApp, nil
+}
+
+func setupHandler(t *testing.T) (*webterm.Handler, string) {
+	handler := webterm.NewHandler(newConsoleApp)
+	server := httptest.NewServer(handler)
+	t.Cleanup(func() {
+		assert.NoError(t, handler.Close())
+		server.Close()
+	})
+	return handler, "ws" + strings.TrimPrefix(server.URL, "http") + "/?token=secret"
+}
+
+func dial(t *testing.T, url string) *websocket.Conn {
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// readUntil reads binary messages until their text contains the text.
+func readUntil(t *testing.T, conn *websocket.Conn, text string) {
+	var output strings.Builder
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	for !strings.Contains(output.String(), text) {
+		messageType, message, err := conn.ReadMessage()
+		require.NoError(t, err, "output %q does not contain %q", output.String(), text)
+		assert.Equal(t, websocket.BinaryMessage, messageType)
+		output.Write(message)
+	}
+}
+
+// readClose reads messages until the handler closes the connection.
+func readClose(t *testing.T, conn *websocket.Conn) error {
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			return err
+		}
+	}
+}
+
+func TestHandler(t *testing.T) {
+	t.Parallel()
+
+	_, url := setupHandler(t)
+	conn := dial(t, url)
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(`{"type": "resize", "cols": 100, "rows": 30}`)))
+	readUntil(t, conn, "size: 100x30")
+
+	// Pasted input arrives in a single message and is split into keys
+	require.NoError(t, conn.WriteMessage(websocket.BinaryMessage, []byte("hello\rwor")))
+	require.NoError(t, conn.WriteMessage(websocket.BinaryMessage, []byte("ld\r")))
+	readUntil(t, conn, "line: [hello] 1")
+	readUntil(t, conn, "line: [world] 2")
+
+	// Unknown control messages are ignored
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(`{"type": "title", "title": "x"}`)))
+	require.NoError(t, conn.WriteMessage(websocket.BinaryMessage, []byte("exit\r")))
+	assert.True(t, websocket.IsCloseError(readClose(t, conn), websocket.CloseNormalClosure))
+}
+
+func TestHandlerIsolatesSessions(t *testing.T) {
+	t.Parallel()
+
+	_, url := setupHandler(t)
+	alice, bob := dial(t, url), dial(t, url)
+	require.NoError(t, alice.WriteMessage(websocket.BinaryMessage, []byte("one\r")))
+	readUntil(t, alice, "line: [one] 1")
+	require.NoError(t, bob.WriteMessage(websocket.BinaryMessage, []byte("two\r")))
+	readUntil(t, bob, "line: [two] 1")
+}
+
+// logOutput collects the output of the error log, the handler logs while the test reads it.
+type logOutput struct {
+	mutex  sync.Mutex
+	output strings.Builder
+}
+
+func (lo *logOutput) Write(p []byte) (int, error) {
+	lo.mutex.Lock()
+	defer lo.mutex.Unlock()
+	return lo.output.Write(p)
+}
+
+func (lo *logOutput) String() string {
+	lo.mutex.Lock()
+	defer lo.mutex.Unlock()
+	return lo.output.String()
+}
+
+func TestHandlerInvalidControlMessage(t *testing.T) {
+	t.Parallel()
+
+	handler, url := setupHandler(t)
+	errorLog := &logOutput{}
+	handler.SetErrorLog(log.New(errorLog, "", 0))
+	conn := dial(t, url)
+
+	// The invalid control message is logged and the session goes on
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(`{"type": `)))
+	require.NoError(t, conn.WriteMessage(websocket.BinaryMessage, []byte("hi\r")))
+	readUntil(t, conn, "line: [hi] 1")
+	assert.Equal(t, "webterm: control message is invalid and ignored! error: unexpected EOF\n", errorLog.String())
+}
+
+func TestHandlerRejectsRequests(t *testing.T) {
+	t.Parallel()
+
+	handler, url := setupHandler(t)
+	_, response, err := websocket.DefaultDialer.Dial(strings.TrimSuffix(url, "secret")+"guess", nil)
+	assert.ErrorIs(t, err, websocket.ErrBadHandshake)
+	assert.Equal(t, http.StatusForbidden, response.StatusCode)
+
+	// Cross-origin pages are rejected unless the origin is checked by the application
+	_, response, err = websocket.DefaultDialer.Dial(url, http.Header{"Origin": {"https://example.com"}})
+	assert.ErrorIs(t, err, websocket.ErrBadHandshake)
+	assert.Equal(t, http.StatusForbidden, response.StatusCode)
+
+	conn := dial(t, url)
+	require.NoError(t, handler.Close())
+	assert.Error(t, readClose(t, conn))
+	_, response, err = websocket.DefaultDialer.Dial(url, nil)
+	assert.ErrorIs(t, err, websocket.ErrBadHandshake)
+	assert.Equal(t, http.StatusServiceUnavailable, response.StatusCode)
+}
+
+func TestHandlerCheckOrigin(t *testing.T) {
+	t.Parallel()
+
+	handler, url := setupHandler(t)
+	handler.SetCheckOrigin(func(r *http.Request) bool {
+		return r.Header.Get("Origin") == "https://example.com"
+	})
+	conn, _, err := websocket.DefaultDialer.Dial(url, http.Header{"Origin": {"https://example.com"}})
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, conn.WriteMessage(websocket.BinaryMessage, []byte("hi\r")))
+	readUntil(t, conn, "line: [hi] 1")
+}
@@ -0,0 +1,609 @@
+package cyclecmd
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrWidgetCancelled is returned by prompt widgets when the user cancelled the prompt with Escape or Ctrl-C.
+var ErrWidgetCancelled = errors.New("prompt was cancelled")
+
+// defaultWidgetPageSize is the number of options that select widgets show at once.
+const defaultWidgetPageSize = 7
+
+// widget draws a prompt widget below the current line. The widget redraws itself in place on every change and
+// is replaced by a single summary line once it is done.
+type widget struct {
+	consoleApp *ConsoleApp
+	// cursorLine is the line of the widget the cursor is placed on, counted from the first line
+	cursorLine int
+	// done is true once the widget was accepted or cancelled
+	done bool
+	// cancelled is true if the widget was cancelled
+	cancelled bool
+}
+
+// run handles events with the event registry until the widget is done.
+//
+// Parameters:
+//   - `eventRegistry` : Event registry of the widget
+//
+// Returns:
+//   - `error` : Returns ErrWidgetCancelled when the widget was cancelled or an error when the event loop concluded
+func (w *widget) run(eventRegistry *EventRegistry) error {
+	if err := w.consoleApp.RunModal(eventRegistry, func() bool { return w.done }); err != nil {
+		return err
+	}
+	if w.cancelled {
+		return ErrWidgetCancelled
+	}
+	return nil
+}
+
+// draw replaces the previously drawn lines of the widget.
+//
+// Parameters:
+//   - `lines` : Lines of the widget, already styled
+//   - `cursorLine` : Line the cursor is placed on
+//   - `cursorColumn` : Column the cursor is placed on
+func (w *widget) draw(lines []string, cursorLine int, cursorColumn int) {
+	var output strings.Builder
+	output.WriteString("\r")
+	if w.cursorLine > 0 {
+		fmt.Fprintf(&output, "\x1b[%dA", w.cursorLine)
+	}
+	output.WriteString("\x1b[J")
+	output.WriteString(strings.Join(lines, "\r\n"))
+	if up := len(lines) - 1 - cursorLine; up > 0 {
+		fmt.Fprintf(&output, "\x1b[%dA", up)
+	}
+	output.WriteString("\r")
+	if cursorColumn > 0 {
+		fmt.Fprintf(&output, "\x1b[%dC", cursorColumn)
+	}
+	w.cursorLine = cursorLine
+	fmt.Fprint(w.consoleApp.Output(), output.String())
+}
+
+// finish replaces the widget with the summary and moves the cursor to the next line.
+//
+// Parameters:
+//   - `summary` : Summary of the answer, e.g. the prompt followed by the chosen option
+func (w *widget) finish(summary string) {
+	w.draw([]string{summary}, 0, 0)
+	fmt.Fprint(w.consoleApp.Output(), "\r\n")
+	w.cursorLine = 0
+	w.done = true
+}
+
+// cancel replaces the widget with the prompt and marks it as cancelled.
+//
+// Parameters:
+//   - `prompt` : Prompt of the widget
+func (w *widget) cancel(prompt string) {
+	w.cancelled = true
+	w.finish(prompt)
+}
+
+// Select is a prompt widget that lets the user pick one of the options with the arrow keys. Typing filters
+// the options, Enter picks the highlighted option and Escape or Ctrl-C cancel the prompt.
+type Select struct {
+	consoleApp *ConsoleApp
+
+	// Prompt is printed in front of the filter
+	Prompt string
+	// Options the user can pick from
+	Options []string
+	// PageSize is the number of options that are shown at once
+	PageSize int
+}
+
+// NewSelect initialises a single-select widget.
+//
+// Parameters:
+//   - `consoleApp` : Console application the widget runs in
+//   - `prompt` : Prompt that is printed in front of the filter
+//   - `options` : Options the user can pick from
+//
+// Returns:
+//   - `*Select` : Returns an instance of Select
+func NewSelect(consoleApp *ConsoleApp, prompt string, options ...string) *Select {
+	return &Select{consoleApp: consoleApp, Prompt: prompt, Options: options, PageSize: defaultWidgetPageSize}
+}
+
+// Run shows the widget and waits until the user picked an option. It can only be called from within events.
+//
+// Returns:
+//   - `int` : Index of the picked option
+//   - `error` : Returns ErrWidgetCancelled when the user cancelled the prompt or an error when the event loop concluded
+func (s *Select) Run() (int, error) {
+	optionList := newOptionList(s.consoleApp, s.Prompt, s.Options, s.PageSize, false)
+	if err := optionList.run(); err != nil {
+		return -1, err
+	}
+	return optionList.picked[0], nil
+}
+
+// MultiSelect is a prompt widget that lets the user pick any number of the options with the arrow keys. Space
+// toggles the highlighted option, typing filters the options, Enter confirms the picked options and Escape or
+// Ctrl-C cancel the prompt.
+type MultiSelect struct {
+	consoleApp *ConsoleApp
+
+	// Prompt is printed in front of the filter
+	Prompt string
+	// Options the user can pick from
+	Options []string
+	// PageSize is the number of options that are shown at once
+	PageSize int
+}
+
+// NewMultiSelect initialises a multi-select widget.
+//
+// Parameters:
+//   - `consoleApp` : Console application the widget runs in
+//   - `prompt` : Prompt that is printed in front of the filter
+//   - `options` : Options the user can pick from
+//
+// Returns:
+//   - `*MultiSelect` : Returns an instance of MultiSelect
+func NewMultiSelect(consoleApp *ConsoleApp, prompt string, options ...string) *MultiSelect {
+	return &MultiSelect{consoleApp: consoleApp, Prompt: prompt, Options: options, PageSize: defaultWidgetPageSize}
+}
+
+// Run shows the widget and waits until the user confirmed the picked options. It can only be called from
+// within events.
+//
+// Returns:
+//   - `[]int` : Sorted indices of the picked options, might be empty
+//   - `error` : Returns ErrWidgetCancelled when the user cancelled the prompt or an error when the event loop concluded
+func (ms *MultiSelect) Run() ([]int, error) {
+	optionList := newOptionList(ms.consoleApp, ms.Prompt, ms.Options, ms.PageSize, true)
+	if err := optionList.run(); err != nil {
+		return nil, err
+	}
+	return optionList.picked, nil
+}
+
+// optionList implements the single-select and the multi-select widget.
+type optionList struct {
+	widget
+
+	prompt   string
+	options  []string
+	pageSize int
+	multiple bool
+
+	// filter is typed by the user, only options that contain the filter are shown
+	filter []rune
+	// matches are the indices of the options that contain the filter
+	matches []int
+	// highlighted is the position of the highlighted option within the matches
+	highlighted int
+	// offset is the position of the first shown option within the matches
+	offset int
+	// toggled contains the indices of the options that are toggled in the multi-select widget
+	toggled map[int]bool
+	// picked contains the sorted indices of the picked options once the widget is done
+	picked []int
+}
+
+// newOptionList initialises the option list of a select widget.
+//
+// Parameters:
+//   - `consoleApp` : Console application the widget runs in
+//   - `prompt` : Prompt that is printed in front of the filter
+//   - `options` : Options the user can pick from
+//   - `pageSize` : Number of options that are shown at once
+//   - `multiple` : True if any number of options can be picked
+//
+// Returns:
+//   - `*optionList` : Returns an instance of optionList
+func newOptionList(consoleApp *ConsoleApp, prompt string, options []string, pageSize int, multiple bool) *optionList {
+	if pageSize < 1 {
+		pageSize = defaultWidgetPageSize
+	}
+	return &optionList{
+		widget:   widget{consoleApp: consoleApp},
+		prompt:   prompt,
+		options:  options,
+		pageSize: pageSize,
+		multiple: multiple,
+		toggled:  make(map[int]bool),
+	}
+}
+
+// run shows the option list and handles events until the user picked or cancelled.
+//
+// Returns:
+//   - `error` : Returns ErrWidgetCancelled when the user cancelled the prompt or an error when the event loop concluded
+func (ol *optionList) run() error {
+	if len(ol.options) == 0 {
+		return fmt.Errorf("select widget %q has no options", ol.prompt)
+	}
+	eventRegistry := NewEventRegistry(EventInformation{
+		EventName: "WidgetFilter",
+		Event:     &funcEvent{handle: ol.extendFilter},
+	})
+	eventRegistry.registry[KEY_ARROW_UP] = EventInformation{EventName: "WidgetUp", Event: &funcEvent{handle: ol.up}}
+	eventRegistry.registry[KEY_ARROW_DOWN] = EventInformation{EventName: "WidgetDown", Event: &funcEvent{handle: ol.down}}
+	eventRegistry.registry[KEY_BACKSPACE] = EventInformation{EventName: "WidgetBackspace", Event: &funcEvent{handle: ol.shortenFilter}}
+	eventRegistry.registry[KEY_ENTER] = EventInformation{EventName: "WidgetAccept", Event: &funcEvent{handle: ol.accept}}
+	eventRegistry.registry[KEY_ESCAPE] = EventInformation{EventName: "WidgetCancel", Event: &funcEvent{handle: ol.cancelList}}
+	eventRegistry.registry[KEY_CTRL_C] = EventInformation{EventName: "WidgetCancel", Event: &funcEvent{handle: ol.cancelList}}
+	if ol.multiple {
+		eventRegistry.registry[KEY_SPACE] = EventInformation{EventName: "WidgetToggle", Event: &funcEvent{handle: ol.toggle}}
+	}
+	ol.updateMatches()
+	ol.render()
+	return ol.widget.run(eventRegistry)
+}
+
+// extendFilter appends a printable token to the filter.
+func (ol *optionList) extendFilter(token string) (error, *ControlEvent) {
+	character, ok := printableCharacter(token)
+	if !ok {
+		return nil, nil
+	}
+	ol.filter = append(ol.filter, []rune(character)...)
+	ol.updateMatches()
+	ol.render()
+	return nil, nil
+}
+
+// shortenFilter removes the last character of the filter.
+func (ol *optionList) shortenFilter(token string) (error, *ControlEvent) {
+	if len(ol.filter) > 0 {
+		ol.filter = ol.filter[:len(ol.filter)-1]
+		ol.updateMatches()
+	}
+	ol.render()
+	return nil, nil
+}
+
+// up highlights the previous option, the highlight wraps around at the top.
+func (ol *optionList) up(token string) (error, *ControlEvent) {
+	if len(ol.matches) > 0 {
+		ol.highlighted = (ol.highlighted - 1 + len(ol.matches)) % len(ol.matches)
+	}
+	ol.render()
+	return nil, nil
+}
+
+// down highlights the next option, the highlight wraps around at the bottom.
+func (ol *optionList) down(token string) (error, *ControlEvent) {
+	if len(ol.matches) > 0 {
+		ol.highlighted = (ol.highlighted + 1) % len(ol.matches)
+	}
+	ol.render()
+	return nil, nil
+}
+
+// toggle toggles the highlighted option of the multi-select widget.
+func (ol *optionList) toggle(token string) (error, *ControlEvent) {
+	if len(ol.matches) > 0 {
+		index := ol.matches[ol.highlighted]
+		ol.toggled[index] = !ol.toggled[index]
+	}
+	ol.render()
+	return nil, nil
+}
+
+// accept picks the highlighted option or, for the multi-select widget, the toggled options.
+func (ol *optionList) accept(token string) (error, *ControlEvent) {
+	if ol.multiple {
+		ol.picked = []int{}
+		for index, toggled := range ol.toggled {
+			if toggled {
+				ol.picked = append(ol.picked, index)
+			}
+		}
+		sort.Ints(ol.picked)
+	} else {
+		if len(ol.matches) == 0 {
+			return nil, nil
+		}
+		ol.picked = []int{ol.matches[ol.highlighted]}
+	}
+	pickedOptions := make([]string, 0, len(ol.picked))
+	for _, index := range ol.picked {
+		pickedOptions = append(pickedOptions, ol.options[index])
+	}
+	ol.finish(ol.prompt + " " + strings.Join(pickedOptions, ", "))
+	return nil, nil
+}
+
+// cancelList cancels the widget.
+func (ol *optionList) cancelList(token string) (error, *ControlEvent) {
+	ol.cancel(ol.prompt)
+	return nil, nil
+}
+
+// updateMatches collects the options that contain the filter, ignoring the case.
+func (ol *optionList) updateMatches() {
+	filter := strings.ToLower(string(ol.filter))
+	ol.matches = ol.matches[:0]
+	for index, option := range ol.options {
+		if strings.Contains(strings.ToLower(option), filter) {
+			ol.matches = append(ol.matches, index)
+		}
+	}
+	ol.highlighted = 0
+	ol.offset = 0
+}
+
+// render draws the prompt with the filter and the page of options that contains the highlighted option.
+func (ol *optionList) render() {
+	if ol.highlighted < ol.offset {
+		ol.offset = ol.highlighted
+	}
+	if ol.highlighted >= ol.offset+ol.pageSize {
+		ol.offset = ol.highlighted - ol.pageSize + 1
+	}
+	width, _ := ol.consoleApp.Size()
+	theme := ol.consoleApp.theme
+
+	promptLine := truncateText(ol.prompt+" "+string(ol.filter), width)
+	lines := []string{ol.consoleApp.render(theme.Prompt, promptLine)}
+	if len(ol.matches) == 0 {
+		lines = append(lines, "  no matching options")
+	}
+	for position := ol.offset; position < min(len(ol.matches), ol.offset+ol.pageSize); position++ {
+		index := ol.matches[position]
+		marker := "  "
+		if position == ol.highlighted {
+			marker = "> "
+		}
+		if ol.multiple {
+			if ol.toggled[index] {
+				marker += "[x] "
+			} else {
+				marker += "[ ] "
+			}
+		}
+		line := truncateText(marker+ol.options[index], width)
+		if position == ol.highlighted {
+			line = ol.consoleApp.render(theme.Selection, line)
+		}
+		lines = append(lines, line)
+	}
+	ol.draw(lines, 0, displayWidth(promptLine))
+}
+
+// Confirm is a prompt widget that asks a yes/no question. The user answers with y or n, Enter picks the
+// default answer and Escape or Ctrl-C cancel the prompt.
+type Confirm struct {
+	widget
+
+	// Prompt is the question
+	Prompt string
+	// Default is the answer that is picked with Enter
+	Default bool
+}
+
+// NewConfirm initialises a confirm widget.
+//
+// Parameters:
+//   - `consoleApp` : Console application the widget runs in
+//   - `prompt` : Question that is asked
+//   - `defaultAnswer` : Answer that is picked with Enter
+//
+// Returns:
+//   - `*Confirm` : Returns an instance of Confirm
+func NewConfirm(consoleApp *ConsoleApp, prompt string, defaultAnswer bool) *Confirm {
+	return &Confirm{widget: widget{consoleApp: consoleApp}, Prompt: prompt, Default: defaultAnswer}
+}
+
+// Run shows the widget and waits until the user answered. It can only be called from within events.
+//
+// Returns:
+//   - `bool` : True if the user answered yes
+//   - `error` : Returns ErrWidgetCancelled when the user cancelled the prompt or an error when the event loop concluded
+func (c *Confirm) Run() (bool, error) {
+	c.done, c.cancelled = false, false
+	answer := c.Default
+	eventRegistry := NewEventRegistry(EventInformation{
+		EventName: "WidgetAnswer",
+		Event: &funcEvent{handle: func(token string) (error, *ControlEvent) {
+			switch strings.ToLower(token) {
+			case "y":
+				answer = true
+			case "n":
+				answer = false
+			default:
+				return nil, nil
+			}
+			c.answer(answer)
+			return nil, nil
+		}},
+	})
+	eventRegistry.registry[KEY_ENTER] = EventInformation{EventName: "WidgetAccept", Event: &funcEvent{handle: func(token string) (error, *ControlEvent) {
+		c.answer(answer)
+		return nil, nil
+	}}}
+	cancel := &funcEvent{handle: func(token string) (error, *ControlEvent) {
+		c.cancel(c.Prompt)
+		return nil, nil
+	}}
+	eventRegistry.registry[KEY_ESCAPE] = EventInformation{EventName: "WidgetCancel", Event: cancel}
+	eventRegistry.registry[KEY_CTRL_C] = EventInformation{EventName: "WidgetCancel", Event: cancel}
+
+	hint := "[y/N]"
+	if c.Default {
+		hint = "[Y/n]"
+	}
+	promptLine := c.Prompt + " " + hint + " "
+	c.draw([]string{c.consoleApp.render(c.consoleApp.theme.Prompt, promptLine)}, 0, displayWidth(promptLine))
+	if err := c.run(eventRegistry); err != nil {
+		return false, err
+	}
+	return answer, nil
+}
+
+// answer replaces the widget with the answer.
+//
+// Parameters:
+//   - `answer` : Answer of the user
+func (c *Confirm) answer(answer bool) {
+	if answer {
+		c.finish(c.Prompt + " yes")
+	} else {
+		c.finish(c.Prompt + " no")
+	}
+}
+
+// Input is a prompt widget that reads a line of text. The input is validated when the user submits it with
+// Enter, the error is shown below the input until the input is valid. Escape or Ctrl-C cancel the prompt.
+// A mask hides the typed characters, see NewPassword.
+type Input struct {
+	widget
+
+	// Prompt is printed in front of the input
+	Prompt string
+	// Default is used when the user submits an empty input
+	Default string
+	// Validate checks the submitted input, might be nil
+	Validate func(input string) error
+	// Mask replaces every typed character if it is not 0
+	Mask rune
+
+	value []rune
+	err   error
+}
+
+// NewInput initialises a text input widget.
+//
+// Parameters:
+//   - `consoleApp` : Console application the widget runs in
+//   - `prompt` : Prompt that is printed in front of the input
+//
+// Returns:
+//   - `*Input` : Returns an instance of Input
+func NewInput(consoleApp *ConsoleApp, prompt string) *Input {
+	return &Input{widget: widget{consoleApp: consoleApp}, Prompt: prompt}
+}
+
+// NewPassword initialises a text input widget that masks the typed characters. The typed characters are not
+// recorded in the event history and the session recording either, they are recorded as MASKED_TOKEN.
+//
+// Parameters:
+//   - `consoleApp` : Console application the widget runs in
+//   - `prompt` : Prompt that is printed in front of the input
+//
+// Returns:
+//   - `*Input` : Returns an instance of Input
+func NewPassword(consoleApp *ConsoleApp, prompt string) *Input {
+	input := NewInput(consoleApp, prompt)
+	input.Mask = '*'
+	return input
+}
+
+// Run shows the widget and waits until the user submitted a valid input. It can only be called from within
+// events.
+//
+// Returns:
+//   - `string` : The submitted input
+//   - `error` : Returns ErrWidgetCancelled when the user cancelled the prompt or an error when the event loop concluded
+func (i *Input) Run() (string, error) {
+	i.done, i.cancelled = false, false
+	i.value, i.err = nil, nil
+	eventRegistry := NewEventRegistry(EventInformation{
+		EventName: "WidgetInsert",
+		Event:     &funcEvent{handle: i.insert},
+	})
+	eventRegistry.maskTokens = i.Mask != 0
+	eventRegistry.registry[KEY_BACKSPACE] = EventInformation{EventName: "WidgetBackspace", Event: &funcEvent{handle: i.backspace}}
+	eventRegistry.registry[KEY_ENTER] = EventInformation{EventName: "WidgetAccept", Event: &funcEvent{handle: i.submit}}
+	cancel := &funcEvent{handle: func(token string) (error, *ControlEvent) {
+		i.cancel(i.Prompt)
+		return nil, nil
+	}}
+	eventRegistry.registry[KEY_ESCAPE] = EventInformation{EventName: "WidgetCancel", Event: cancel}
+	eventRegistry.registry[KEY_CTRL_C] = EventInformation{EventName: "WidgetCancel", Event: cancel}
+
+	i.render()
+	if err := i.run(eventRegistry); err != nil {
+		return "", err
+	}
+	return i.submitted(), nil
+}
+
+// insert appends a printable token to the input.
+func (i *Input) insert(token string) (error, *ControlEvent) {
+	character, ok := printableCharacter(token)
+	if !ok {
+		return nil, nil
+	}
+	i.value = append(i.value, []rune(character)...)
+	i.render()
+	return nil, nil
+}
+
+// backspace removes the last character of the input.
+func (i *Input) backspace(token string) (error, *ControlEvent) {
+	if len(i.value) > 0 {
+		i.value = i.value[:len(i.value)-1]
+	}
+	i.render()
+	return nil, nil
+}
+
+// submit validates the input, the widget is done if the input is valid.
+func (i *Input) submit(token string) (error, *ControlEvent) {
+	if i.Validate != nil {
+		i.err = i.Validate(i.submitted())
+		if i.err != nil {
+			i.render()
+			return nil, nil
+		}
+	}
+	i.finish(i.Prompt + " " + i.displayed(i.submitted()))
+	return nil, nil
+}
+
+// submitted returns the input or the default if the input is empty.
+//
+// Returns:
+//   - `string` : The submitted input
+func (i *Input) submitted() string {
+	if len(i.value) == 0 {
+		return i.Default
+	}
+	return string(i.value)
+}
+
+// displayed masks the text if a mask is set.
+//
+// Parameters:
+//   - `text` : Text that is displayed
+//
+// Returns:
+//   - `string` : The masked text
+func (i *Input) displayed(text string) string {
+	if i.Mask == 0 {
+		return text
+	}
+	return strings.Repeat(string(i.Mask), len([]rune(text)))
+}
+
+// render draws the prompt with the input and the validation error, if any.
+func (i *Input) render() {
+	width, _ := i.consoleApp.Size()
+	theme := i.consoleApp.theme
+	prompt := i.Prompt + " "
+	if i.Default != "" && i.Mask == 0 {
+		prompt = fmt.Sprintf("%s(%s) ", i.Prompt, i.Default)
+	}
+	value := i.displayed(string(i.value))
+	// The end of the input stays visible when the input is wider than the screen
+	if overflow := displayWidth(prompt) + displayWidth(value) - width + 1; overflow > 0 {
+		value = string([]rune(value)[min(overflow, len([]rune(value))):])
+	}
+	lines := []string{i.consoleApp.render(theme.Prompt, truncateText(prompt, width)) + value}
+	if i.err != nil {
+		lines = append(lines, i.consoleApp.render(theme.Error, truncateText(fmt.Sprintf("error: %v", i.err), width)))
+	}
+	i.draw(lines, 0, min(width-1, displayWidth(prompt)+displayWidth(value)))
+}
@@ -0,0 +1,180 @@
+//go:build unit_test
+
+package cyclecmd_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/RaphSku/cyclecmd"
+	"github.com/stretchr/testify/assert"
+)
+
+// WidgetEvent runs a prompt widget and keeps its result.
+type WidgetEvent struct {
+	run    func() (any, error)
+	result any
+	err    error
+}
+
+func (we *WidgetEvent) Handle(token string) (error, *cyclecmd.ControlEvent) {
+	we.result, we.err = we.run()
+	return nil, nil
+}
+
+func runWidget(t *testing.T, run func(consoleApp *cyclecmd.ConsoleApp) (any, error), tokens ...string) (*WidgetEvent, *cyclecmd.EventHistory, *bytes.Buffer) {
+	eventRegistry := cyclecmd.NewEventRegistry(setupDefaultEventInformation())
+	eventHistory := cyclecmd.NewEventHistory()
+	consoleApp := cyclecmd.NewConsoleApp("test", "0.1.0", "This is a test console application", eventRegistry, eventHistory)
+	output := &bytes.Buffer{}
+	consoleApp.SetOutput(output)
+	consoleApp.SetInput(newTokenReader(append([]string{"w"}, tokens...)...))
+	consoleApp.DisableBanner()
+
+	widgetEvent := &WidgetEvent{run: func() (any, error) { return run(consoleApp) }}
+	err := eventRegistry.RegisterEvent("w", cyclecmd.EventInformation{EventName: "Widget", Event: widgetEvent})
+	assert.NoError(t, err)
+	consoleApp.Start()
+	return widgetEvent, eventHistory, output
+}
+
+func TestSelectWidget(t *testing.T) {
+	t.Parallel()
+
+	selectColour := func(consoleApp *cyclecmd.ConsoleApp) (any, error) {
+		return cyclecmd.NewSelect(consoleApp, "Colour?", "red", "green", "blue").Run()
+	}
+
+	widgetEvent, eventHistory, output := runWidget(t, selectColour, "\x1b[B", "\x1b[B", cyclecmd.KEY_ENTER)
+	assert.NoError(t, widgetEvent.err)
+	assert.Equal(t, 2, widgetEvent.result)
+	assert.Equal(t, 4, eventHistory.Len())
+	assert.Contains(t, output.String(), "\r\x1b[JColour? \r\n  red\r\n  green\r\n> blue\x1b[3A\r\x1b[8C")
+	assert.Contains(t, output.String(), "\r\x1b[JColour? blue\r\r\n")
+
+	widgetEvent, _, _ = runWidget(t, selectColour, "B", "\x1b[A", cyclecmd.KEY_ENTER)
+	assert.NoError(t, widgetEvent.err)
+	assert.Equal(t, 2, widgetEvent.result)
+
+	widgetEvent, _, _ = runWidget(t, selectColour, "x", cyclecmd.KEY_ENTER, cyclecmd.KEY_ESCAPE)
+	assert.ErrorIs(t, widgetEvent.err, cyclecmd.ErrWidgetCancelled)
+
+	widgetEvent, _, _ = runWidget(t, selectColour, "\x1b[B")
+	assert.ErrorContains(t, widgetEvent.err, "event loop concluded during modal event handling")
+}
+
+func TestSelectWidgetDoesNotPrintDelimiter(t *testing.T) {
+	t.Parallel()
+
+	eventRegistry := cyclecmd.NewEventRegistry(setupDefaultEventInformation())
+	consoleApp := cyclecmd.NewConsoleApp("test", "0.1.0", "This is a test console application", eventRegistry, cyclecmd.NewEventHistory())
+	output := &bytes.Buffer{}
+	consoleApp.SetOutput(output)
+	consoleApp.SetInput(newTokenReader(cyclecmd.KEY_ENTER, "\x1b[B", cyclecmd.KEY_ENTER))
+	consoleApp.DisableBanner()
+	pickEvent := &WidgetEvent{run: func() (any, error) {
+		index, err := cyclecmd.NewSelect(consoleApp, "Pick", "x", "y").Run()
+		fmt.Fprintf(consoleApp.Output(), "[picked %v %v]", index, err)
+		return index, err
+	}}
+	assert.NoError(t, eventRegistry.RegisterEvent(cyclecmd.KEY_ENTER, cyclecmd.EventInformation{EventName: "Pick", Event: pickEvent}))
+	consoleApp.SetLineDelimiter("\r\n>>> ", cyclecmd.KEY_ENTER)
+	consoleApp.Start()
+
+	// Accepting the selection does not print the delimiter, it is printed once the event that ran the widget returned
+	assert.Equal(t, 1, pickEvent.result)
+	assert.Contains(t, output.String(), "\r\x1b[JPick y\r\r\n[picked 1 <nil>]\r\n>>> ")
+}
+
+func TestMultiSelectWidget(t *testing.T) {
+	t.Parallel()
+
+	selectColours := func(consoleApp *cyclecmd.ConsoleApp) (any, error) {
+		return cyclecmd.NewMultiSelect(consoleApp, "Colours?", "red", "green", "blue").Run()
+	}
+
+	widgetEvent, _, output := runWidget(t, selectColours, cyclecmd.KEY_SPACE, "\x1b[A", cyclecmd.KEY_SPACE, cyclecmd.KEY_ENTER)
+	assert.NoError(t, widgetEvent.err)
+	assert.Equal(t, []int{0, 2}, widgetEvent.result)
+	assert.Contains(t, output.String(), "  [x] red\r\n  [ ] green\r\n> [x] blue")
+	assert.Contains(t, output.String(), "Colours? red, blue\r\r\n")
+
+	widgetEvent, _, _ = runWidget(t, selectColours, cyclecmd.KEY_ENTER)
+	assert.NoError(t, widgetEvent.err)
+	assert.Equal(t, []int{}, widgetEvent.result)
+}
+
+func TestConfirmWidget(t *testing.T) {
+	t.Parallel()
+
+	confirm := func(consoleApp *cyclecmd.ConsoleApp) (any, error) {
+		return cyclecmd.NewConfirm(consoleApp, "Continue?", true).Run()
+	}
+
+	widgetEvent, _, output := runWidget(t, confirm, "x", "N")
+	assert.NoError(t, widgetEvent.err)
+	assert.Equal(t, false, widgetEvent.result)
+	assert.Contains(t, output.String(), "Continue? [Y/n] ")
+	assert.Contains(t, output.String(), "Continue? no\r\r\n")
+
+	widgetEvent, _, _ = runWidget(t, confirm, cyclecmd.KEY_ENTER)
+	assert.NoError(t, widgetEvent.err)
+	assert.Equal(t, true, widgetEvent.result)
+
+	widgetEvent, _, _ = runWidget(t, confirm, cyclecmd.KEY_CTRL_C)
+	assert.ErrorIs(t, widgetEvent.err, cyclecmd.ErrWidgetCancelled)
+}
+
+func TestInputWidget(t *testing.T) {
+	t.Parallel()
+
+	input := func(consoleApp *cyclecmd.ConsoleApp) (any, error) {
+		inputWidget := cyclecmd.NewInput(consoleApp, "Name:")
+		inputWidget.Validate = func(input string) error {
+			if len(input) < 2 {
+				return fmt.Errorf("name is too short")
+			}
+			return nil
+		}
+		return inputWidget.Run()
+	}
+
+	widgetEvent, _, output := runWidget(t, input, "J", cyclecmd.KEY_ENTER, "o", "x", cyclecmd.KEY_BACKSPACE, cyclecmd.KEY_ENTER)
+	assert.NoError(t, widgetEvent.err)
+	assert.Equal(t, "Jo", widgetEvent.result)
+	assert.Contains(t, output.String(), "Name: J\r\nerror: name is too short\x1b[1A\r\x1b[7C")
+	assert.Contains(t, output.String(), "Name: Jo\r\r\n")
+
+	defaultInput := func(consoleApp *cyclecmd.ConsoleApp) (any, error) {
+		inputWidget := cyclecmd.NewInput(consoleApp, "Name:")
+		inputWidget.Default = "Jane"
+		return inputWidget.Run()
+	}
+	widgetEvent, _, _ = runWidget(t, defaultInput, cyclecmd.KEY_ENTER)
+	assert.NoError(t, widgetEvent.err)
+	assert.Equal(t, "Jane", widgetEvent.result)
+
+	widgetEvent, _, _ = runWidget(t, input, "J", "ö", "r", "g", cyclecmd.KEY_ENTER)
+	assert.NoError(t, widgetEvent.err)
+	assert.Equal(t, "Jörg", widgetEvent.result)
+}
+
+func TestPasswordWidget(t *testing.T) {
+	t.Parallel()
+
+	password := func(consoleApp *cyclecmd.ConsoleApp) (any, error) {
+		return cyclecmd.NewPassword(consoleApp, "Password:").Run()
+	}
+
+	widgetEvent, eventHistory, output := runWidget(t, password, "s", "3", "c", cyclecmd.KEY_ENTER)
+	assert.NoError(t, widgetEvent.err)
+	assert.Equal(t, "s3c", widgetEvent.result)
+	assert.NotContains(t, output.String(), "s3c")
+	assert.Contains(t, output.String(), "Password: ***\r\r\n")
+	for index := 1; index < eventHistory.Len(); index++ {
+		entry, err := eventHistory.RetrieveEventEntryByIndex(index)
+		assert.NoError(t, err)
+		assert.Equal(t, cyclecmd.MASKED_TOKEN, entry.Token)
+	}
+}